@@ -5,11 +5,13 @@ import (
 
 	"github.com/go-logr/logr"
 
+	"github.com/kedacore/http-add-on/interceptor/circuitbreaker"
 	"github.com/kedacore/http-add-on/pkg/queue"
+	"github.com/kedacore/http-add-on/pkg/routing"
 )
 
 // BuildAdminHandler creates the handler for the admin endpoint.
-func BuildAdminHandler(logger logr.Logger, counter queue.Counter, probeHandler http.Handler) http.Handler {
+func BuildAdminHandler(logger logr.Logger, counter queue.Counter, routingTable routing.Table, circuits *circuitbreaker.Registry, probeHandler http.Handler) http.Handler {
 	mux := http.NewServeMux()
 
 	mux.Handle("/readyz", probeHandler)
@@ -24,5 +26,17 @@ func BuildAdminHandler(logger logr.Logger, counter queue.Counter, probeHandler h
 		counter,
 	)
 
+	routing.AddDebugRoutes(
+		logger,
+		mux,
+		routingTable,
+	)
+
+	circuitbreaker.AddDebugRoute(
+		logger,
+		mux,
+		circuits,
+	)
+
 	return mux
 }
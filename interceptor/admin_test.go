@@ -6,6 +6,9 @@ import (
 	"testing"
 
 	"github.com/go-logr/logr"
+
+	"github.com/kedacore/http-add-on/interceptor/circuitbreaker"
+	routingtest "github.com/kedacore/http-add-on/pkg/routing/test"
 )
 
 func TestAdminHandler(t *testing.T) {
@@ -24,6 +27,21 @@ func TestAdminHandler(t *testing.T) {
 			wantProbeCalled: true,
 			wantStatus:      http.StatusOK,
 		},
+		"debug routes": {
+			path:            "/debug/routes",
+			wantProbeCalled: false,
+			wantStatus:      http.StatusOK,
+		},
+		"debug match": {
+			path:            "/debug/match?host=example.com&path=/",
+			wantProbeCalled: false,
+			wantStatus:      http.StatusOK,
+		},
+		"debug circuits": {
+			path:            "/debug/circuits",
+			wantProbeCalled: false,
+			wantStatus:      http.StatusOK,
+		},
 		"other": {
 			path:            "/other",
 			wantProbeCalled: false,
@@ -39,7 +57,7 @@ func TestAdminHandler(t *testing.T) {
 				w.WriteHeader(http.StatusOK)
 			})
 
-			handler := BuildAdminHandler(logr.Discard(), nil, probeHandler)
+			handler := BuildAdminHandler(logr.Discard(), nil, routingtest.NewTable(), circuitbreaker.NewRegistry(nil), probeHandler)
 
 			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
 			rec := httptest.NewRecorder()
@@ -0,0 +1,272 @@
+// Package circuitbreaker fails fast for backend Services failing too many
+// requests, following the circuit breaker of their InterceptorRoute.
+package circuitbreaker
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	httpv1beta1 "github.com/kedacore/http-add-on/operator/apis/http/v1beta1"
+)
+
+const (
+	defaultMinimumRequests  = 10
+	defaultInterval         = 10 * time.Second
+	defaultCoolDown         = 30 * time.Second
+	defaultHalfOpenRequests = 1
+
+	// idleCircuitTTL is the time after which the circuit of a backend
+	// Service without requests is forgotten, e.g. once the Service is deleted
+	// or no longer a backend of a route.
+	idleCircuitTTL = 10 * time.Minute
+	// evictionInterval is the time between two searches for idle circuits.
+	evictionInterval = time.Minute
+)
+
+// State is the state of a circuit.
+type State int
+
+const (
+	// StateClosed forwards requests, counting their failures.
+	StateClosed State = iota
+	// StateOpen rejects requests until the cool-down ends.
+	StateOpen
+	// StateHalfOpen forwards a limited number of probe requests.
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "Closed"
+	case StateOpen:
+		return "Open"
+	case StateHalfOpen:
+		return "HalfOpen"
+	default:
+		return "Unknown"
+	}
+}
+
+// Outcome is the result of a forwarded request.
+type Outcome int
+
+const (
+	// Success is a request the backend answered without a 5xx.
+	Success Outcome = iota
+	// Failure is a request failing with a connection error or a 5xx.
+	Failure
+	// Ignored is a request not reaching the backend, e.g. because the
+	// client went away.
+	Ignored
+)
+
+// Registry holds the circuit of each backend Service.
+type Registry struct {
+	onStateChange func(serviceKey string, state State)
+	now           func() time.Time
+
+	mu        sync.Mutex
+	circuits  map[string]*circuit // "namespace/service" -> circuit
+	lastEvict time.Time
+}
+
+// circuit is the state of a backend Service, guarded by Registry.mu.
+type circuit struct {
+	state State
+	since time.Time
+	// generation changes with the state, so that requests admitted in a
+	// previous state do not count.
+	generation uint64
+
+	// Closed.
+	windowStart         time.Time
+	requests            int
+	failures            int
+	consecutiveFailures int
+
+	// Half-open.
+	probes         int
+	probeSuccesses int
+
+	// lastAllow is the time of the last request to the backend Service.
+	lastAllow time.Time
+	// coolDown is the cool-down of the last request, kept so that idle open
+	// circuits are only forgotten once it ended.
+	coolDown time.Duration
+}
+
+// NewRegistry returns a Registry of closed circuits. onStateChange, if set,
+// is called on each state change, with the registry locked.
+func NewRegistry(onStateChange func(serviceKey string, state State)) *Registry {
+	return &Registry{
+		onStateChange: onStateChange,
+		now:           time.Now,
+		circuits:      make(map[string]*circuit),
+	}
+}
+
+// Allow reports whether a request to the backend Service serviceKey may be
+// forwarded under spec. If so, done must be called with the outcome of the
+// request. Otherwise retryAfter is the remaining cool-down, 0 if unknown.
+func (reg *Registry) Allow(serviceKey string, spec *httpv1beta1.CircuitBreakerSpec) (done func(Outcome), retryAfter time.Duration, ok bool) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	now := reg.now()
+	reg.evictIdle(now)
+	c, found := reg.circuits[serviceKey]
+	if !found {
+		c = &circuit{}
+		reg.circuits[serviceKey] = c
+	}
+	c.lastAllow = now
+	c.coolDown = durationOr(spec.CoolDown.Duration, defaultCoolDown)
+	if c.state == StateOpen {
+		if elapsed := now.Sub(c.since); elapsed < c.coolDown {
+			return nil, c.coolDown - elapsed, false
+		}
+		reg.setState(serviceKey, c, StateHalfOpen, now)
+	}
+	if c.state == StateHalfOpen {
+		if c.probes >= halfOpenRequests(spec) {
+			return nil, 0, false
+		}
+		c.probes++
+	}
+
+	generation := c.generation
+	return func(o Outcome) { reg.record(serviceKey, c, generation, spec, o) }, 0, true
+}
+
+func (reg *Registry) record(serviceKey string, c *circuit, generation uint64, spec *httpv1beta1.CircuitBreakerSpec, o Outcome) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	if c.generation != generation {
+		return
+	}
+
+	now := reg.now()
+	switch c.state {
+	case StateClosed:
+		if now.Sub(c.windowStart) >= durationOr(spec.Interval.Duration, defaultInterval) {
+			c.windowStart = now
+			c.requests, c.failures = 0, 0
+		}
+		if o == Ignored {
+			return
+		}
+		c.requests++
+		if o == Failure {
+			c.failures++
+			c.consecutiveFailures++
+		} else {
+			c.consecutiveFailures = 0
+		}
+		if shouldTrip(c, spec) {
+			reg.setState(serviceKey, c, StateOpen, now)
+		}
+
+	case StateHalfOpen:
+		switch o {
+		case Ignored:
+			c.probes--
+		case Failure:
+			reg.setState(serviceKey, c, StateOpen, now)
+		case Success:
+			c.probeSuccesses++
+			if c.probeSuccesses >= halfOpenRequests(spec) {
+				reg.setState(serviceKey, c, StateClosed, now)
+			}
+		}
+
+	case StateOpen:
+		// Not reachable: requests are only admitted while closed or half-open,
+		// and opening changes the generation.
+	}
+}
+
+func (reg *Registry) setState(serviceKey string, c *circuit, state State, now time.Time) {
+	*c = circuit{
+		state:       state,
+		since:       now,
+		generation:  c.generation + 1,
+		windowStart: now,
+		lastAllow:   c.lastAllow,
+		coolDown:    c.coolDown,
+	}
+	if reg.onStateChange != nil {
+		reg.onStateChange(serviceKey, state)
+	}
+}
+
+// evictIdle forgets the circuits without requests for idleCircuitTTL, at
+// most once per evictionInterval. Open circuits are kept until their
+// cool-down ended, and reported closed when forgotten.
+func (reg *Registry) evictIdle(now time.Time) {
+	if now.Sub(reg.lastEvict) < evictionInterval {
+		return
+	}
+	reg.lastEvict = now
+
+	for key, c := range reg.circuits {
+		if now.Sub(c.lastAllow) < idleCircuitTTL || c.state == StateOpen && now.Sub(c.since) < c.coolDown {
+			continue
+		}
+		delete(reg.circuits, key)
+		if c.state != StateClosed && reg.onStateChange != nil {
+			reg.onStateChange(key, StateClosed)
+		}
+	}
+}
+
+// Status is the state of the circuit of a backend Service.
+type Status struct {
+	Service string    `json:"service"`
+	State   string    `json:"state"`
+	Since   time.Time `json:"since,omitzero"`
+}
+
+// Statuses returns the state of every circuit, sorted by Service.
+func (reg *Registry) Statuses() []Status {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	reg.evictIdle(reg.now())
+	statuses := make([]Status, 0, len(reg.circuits))
+	for key, c := range reg.circuits {
+		statuses = append(statuses, Status{Service: key, State: c.state.String(), Since: c.since})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Service < statuses[j].Service })
+	return statuses
+}
+
+func shouldTrip(c *circuit, spec *httpv1beta1.CircuitBreakerSpec) bool {
+	if spec.ConsecutiveFailures != nil && c.consecutiveFailures >= int(*spec.ConsecutiveFailures) {
+		return true
+	}
+	if spec.FailurePercentage == nil {
+		return false
+	}
+	minimumRequests := int(spec.MinimumRequests)
+	if minimumRequests <= 0 {
+		minimumRequests = defaultMinimumRequests
+	}
+	return c.requests >= minimumRequests && c.failures*100 >= int(*spec.FailurePercentage)*c.requests
+}
+
+func halfOpenRequests(spec *httpv1beta1.CircuitBreakerSpec) int {
+	if spec.HalfOpenRequests <= 0 {
+		return defaultHalfOpenRequests
+	}
+	return int(spec.HalfOpenRequests)
+}
+
+func durationOr(d, def time.Duration) time.Duration {
+	if d <= 0 {
+		return def
+	}
+	return d
+}
@@ -0,0 +1,196 @@
+package circuitbreaker
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	httpv1beta1 "github.com/kedacore/http-add-on/operator/apis/http/v1beta1"
+)
+
+const testKey = "ns/svc"
+
+func newTestRegistry() (*Registry, *time.Time, *[]State) {
+	now := time.Unix(0, 0)
+	var states []State
+	reg := NewRegistry(func(_ string, s State) { states = append(states, s) })
+	reg.now = func() time.Time { return now }
+	return reg, &now, &states
+}
+
+// send forwards a request with the given outcome, reporting whether it was
+// allowed.
+func send(reg *Registry, spec *httpv1beta1.CircuitBreakerSpec, o Outcome) bool {
+	done, _, ok := reg.Allow(testKey, spec)
+	if ok {
+		done(o)
+	}
+	return ok
+}
+
+func TestConsecutiveFailures(t *testing.T) {
+	reg, now, states := newTestRegistry()
+	spec := &httpv1beta1.CircuitBreakerSpec{
+		ConsecutiveFailures: ptr.To[int32](3),
+		CoolDown:            metav1.Duration{Duration: 10 * time.Second},
+	}
+
+	for _, o := range []Outcome{Failure, Failure, Success, Failure, Failure, Ignored} {
+		send(reg, spec, o)
+	}
+	if len(*states) != 0 {
+		t.Fatalf("states = %v, want the circuit to stay closed", *states)
+	}
+
+	send(reg, spec, Failure)
+	done, retryAfter, ok := reg.Allow(testKey, spec)
+	if ok || done != nil {
+		t.Fatal("Allow() = true, want an open circuit")
+	}
+	if retryAfter != 10*time.Second {
+		t.Errorf("retryAfter = %s, want 10s", retryAfter)
+	}
+
+	*now = now.Add(4 * time.Second)
+	if _, retryAfter, _ := reg.Allow(testKey, spec); retryAfter != 6*time.Second {
+		t.Errorf("retryAfter = %s, want 6s", retryAfter)
+	}
+
+	// After the cool-down, a single probe is forwarded.
+	*now = now.Add(6 * time.Second)
+	probe, _, ok := reg.Allow(testKey, spec)
+	if !ok {
+		t.Fatal("Allow() = false, want a half-open probe")
+	}
+	if _, _, ok := reg.Allow(testKey, spec); ok {
+		t.Error("Allow() = true, want a single probe")
+	}
+	probe(Success)
+	if !send(reg, spec, Success) {
+		t.Error("Allow() = false, want a closed circuit after a successful probe")
+	}
+
+	if want := []State{StateOpen, StateHalfOpen, StateClosed}; !slices.Equal(*states, want) {
+		t.Errorf("states = %v, want %v", *states, want)
+	}
+}
+
+func TestFailurePercentage(t *testing.T) {
+	reg, now, states := newTestRegistry()
+	spec := &httpv1beta1.CircuitBreakerSpec{
+		FailurePercentage: ptr.To[int32](50),
+		MinimumRequests:   4,
+		Interval:          metav1.Duration{Duration: 10 * time.Second},
+	}
+
+	// Below the minimum number of requests.
+	send(reg, spec, Failure)
+	send(reg, spec, Failure)
+	send(reg, spec, Success)
+
+	// The window ends, starting over.
+	*now = now.Add(10 * time.Second)
+	send(reg, spec, Success)
+	send(reg, spec, Success)
+	send(reg, spec, Failure)
+	if len(*states) != 0 {
+		t.Fatalf("states = %v, want the circuit to stay closed", *states)
+	}
+	send(reg, spec, Failure)
+	if want := []State{StateOpen}; !slices.Equal(*states, want) {
+		t.Errorf("states = %v, want %v", *states, want)
+	}
+}
+
+func TestHalfOpen(t *testing.T) {
+	spec := &httpv1beta1.CircuitBreakerSpec{
+		ConsecutiveFailures: ptr.To[int32](1),
+		CoolDown:            metav1.Duration{Duration: time.Second},
+		HalfOpenRequests:    2,
+	}
+
+	tests := map[string]struct {
+		outcomes  []Outcome
+		wantState State
+	}{
+		"probes succeed": {outcomes: []Outcome{Success, Success}, wantState: StateClosed},
+		"probe fails":    {outcomes: []Outcome{Success, Failure}, wantState: StateOpen},
+		"probe ignored":  {outcomes: []Outcome{Success, Ignored}, wantState: StateHalfOpen},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			reg, now, states := newTestRegistry()
+			send(reg, spec, Failure)
+			*now = now.Add(time.Second)
+
+			var probes []func(Outcome)
+			for range tt.outcomes {
+				done, _, ok := reg.Allow(testKey, spec)
+				if !ok {
+					t.Fatal("Allow() = false, want a probe")
+				}
+				probes = append(probes, done)
+			}
+			for i, o := range tt.outcomes {
+				probes[i](o)
+			}
+			if got := (*states)[len(*states)-1]; got != tt.wantState {
+				t.Errorf("state = %s, want %s", got, tt.wantState)
+			}
+		})
+	}
+}
+
+func TestStaleOutcomesIgnored(t *testing.T) {
+	reg, _, states := newTestRegistry()
+	spec := &httpv1beta1.CircuitBreakerSpec{ConsecutiveFailures: ptr.To[int32](1)}
+
+	slow, _, _ := reg.Allow(testKey, spec)
+	send(reg, spec, Failure)
+	// The slow request started while closed must not count once open.
+	slow(Success)
+
+	if want := []State{StateOpen}; !slices.Equal(*states, want) {
+		t.Errorf("states = %v, want %v", *states, want)
+	}
+	if statuses := reg.Statuses(); len(statuses) != 1 || statuses[0].State != "Open" {
+		t.Errorf("Statuses() = %+v, want one open circuit", statuses)
+	}
+}
+
+func TestIdleCircuitsEvicted(t *testing.T) {
+	reg, now, states := newTestRegistry()
+	closed := &httpv1beta1.CircuitBreakerSpec{ConsecutiveFailures: ptr.To[int32](3)}
+	open := &httpv1beta1.CircuitBreakerSpec{
+		ConsecutiveFailures: ptr.To[int32](1),
+		CoolDown:            metav1.Duration{Duration: time.Hour},
+	}
+
+	if done, _, ok := reg.Allow("ns/closed", closed); ok {
+		done(Failure)
+	}
+	send(reg, open, Failure)
+	services := func() []string {
+		var services []string
+		for _, s := range reg.Statuses() {
+			services = append(services, s.Service)
+		}
+		return services
+	}
+
+	*now = now.Add(idleCircuitTTL)
+	if got, want := services(), []string{testKey}; !slices.Equal(got, want) {
+		t.Errorf("services = %v, want %v", got, want)
+	}
+
+	*now = now.Add(time.Hour)
+	if got := services(); len(got) != 0 {
+		t.Errorf("services = %v, want none once the cool-down ended", got)
+	}
+	if want := []State{StateOpen, StateClosed}; !slices.Equal(*states, want) {
+		t.Errorf("states = %v, want %v", *states, want)
+	}
+}
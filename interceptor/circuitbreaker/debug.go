@@ -0,0 +1,26 @@
+package circuitbreaker
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-logr/logr"
+)
+
+const debugCircuitsPath = "/debug/circuits"
+
+// AddDebugRoute adds an endpoint listing the state of the circuit of each
+// backend Service to mux.
+func AddDebugRoute(lggr logr.Logger, mux *http.ServeMux, reg *Registry) {
+	lggr = lggr.WithName("circuitbreaker.AddDebugRoute")
+	lggr.Info("adding circuit breaker debug route", "path", debugCircuitsPath)
+
+	mux.HandleFunc(debugCircuitsPath, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(reg.Statuses()); err != nil {
+			lggr.Error(err, "encoding circuit states")
+		}
+	})
+}
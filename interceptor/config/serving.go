@@ -69,6 +69,42 @@ type Serving struct {
 	// ClusterIP, bypassing kube-proxy and other Service-layer features
 	// (NetworkPolicy, session affinity, topology-aware routing). Single-stack only.
 	DirectPodRouting bool `env:"KEDA_HTTP_DIRECT_POD_ROUTING" envDefault:"false"`
+	// FirstComeHostOwnership makes the namespace of the oldest route using a
+	// host not claimed by a HostClaim the owner of that host. Routes in other
+	// namespaces are not routed for owned hosts.
+	FirstComeHostOwnership bool `env:"KEDA_HTTP_FIRST_COME_HOST_OWNERSHIP" envDefault:"false"`
+	// GatewayAPIParentRef enables routing of Gateway API HTTPRoutes that
+	// reference this parent, as "[kind/]namespace/name" where kind is Service
+	// (the default, for the interceptor proxy Service) or Gateway. Leave this
+	// empty to ignore HTTPRoutes.
+	GatewayAPIParentRef string `env:"KEDA_HTTP_GATEWAY_API_PARENT_REF" envDefault:""`
+	// IngressClassName enables routing of the Ingresses of this IngressClass.
+	// Leave this empty to ignore Ingresses.
+	IngressClassName string `env:"KEDA_HTTP_INGRESS_CLASS_NAME" envDefault:""`
+	// StandaloneDir runs the interceptor without Kubernetes, loading routes,
+	// Services, EndpointSlices and ConfigMaps from the YAML files of this
+	// directory and reloading them on changes. Requests are routed directly to
+	// the addresses of the EndpointSlices.
+	StandaloneDir string `env:"KEDA_HTTP_STANDALONE_DIR" envDefault:""`
+	// Zone of the interceptor pod. Direct pod routing prefers ready pods in
+	// this zone, or hinted for it, and falls back to other zones when it has
+	// none. When empty, the zone is read from the topology.kubernetes.io/zone
+	// label of the node named by NodeName.
+	Zone string `env:"KEDA_HTTP_ZONE" envDefault:""`
+	// NodeName is the node the interceptor pod runs on.
+	NodeName string `env:"KEDA_HTTP_NODE_NAME" envDefault:""`
+	// OutlierConsecutiveFailures ejects a pod from direct pod routing after
+	// this many connection errors or 5xx responses in a row. 0, the default,
+	// disables outlier detection.
+	OutlierConsecutiveFailures int `env:"KEDA_HTTP_OUTLIER_CONSECUTIVE_FAILURES" envDefault:"0"`
+	// OutlierBaseEjectionTime is how long a pod is ejected. Each ejection
+	// without a successful request in between ejects the pod for one more
+	// OutlierBaseEjectionTime, up to OutlierMaxEjectionTime.
+	OutlierBaseEjectionTime time.Duration `env:"KEDA_HTTP_OUTLIER_BASE_EJECTION_TIME" envDefault:"30s"`
+	OutlierMaxEjectionTime  time.Duration `env:"KEDA_HTTP_OUTLIER_MAX_EJECTION_TIME" envDefault:"5m"`
+	// OutlierMaxEjectionPercent caps the percentage of the pods of a service
+	// ejected at the same time.
+	OutlierMaxEjectionPercent int `env:"KEDA_HTTP_OUTLIER_MAX_EJECTION_PERCENT" envDefault:"50"`
 }
 
 // MustParseServing parses standard configs and returns the
@@ -6,6 +6,8 @@ import (
 	"net"
 	"net/http"
 	"net/http/httputil"
+	"net/url"
+	"strings"
 	"time"
 
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
@@ -18,6 +20,7 @@ import (
 	"github.com/kedacore/http-add-on/interceptor/config"
 	httpv1beta1 "github.com/kedacore/http-add-on/operator/apis/http/v1beta1"
 	kedahttp "github.com/kedacore/http-add-on/pkg/http"
+	"github.com/kedacore/http-add-on/pkg/routing"
 	"github.com/kedacore/http-add-on/pkg/util"
 )
 
@@ -78,6 +81,11 @@ func (uh *Upstream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	attempt := util.UpstreamAttemptFromContext(ctx)
+	if attempt != nil {
+		attempt.Host = url.Host
+	}
+
 	// Select transport with per-route or global response header timeout.
 	ir := util.InterceptorRouteFromContext(ctx)
 	responseHeaderTimeout := uh.responseHeaderTimeout
@@ -114,6 +122,9 @@ func (uh *Upstream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			pr.SetURL(url)
 			// Preserve original Host header (SetURL rewrites it by default).
 			pr.Out.Host = pr.In.Host
+			if ir != nil && ir.Spec.Rewrite != nil {
+				rewriteRequest(pr, ir.Spec.Rewrite, ir.Spec.Rules)
+			}
 
 			// Preserve and extend X-Forwarded-... headers from upstream proxies
 			pr.Out.Header["X-Forwarded-For"] = pr.In.Header["X-Forwarded-For"]
@@ -124,16 +135,22 @@ func (uh *Upstream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			if proto := pr.In.Header.Get("X-Forwarded-Proto"); proto != "" {
 				pr.Out.Header.Set("X-Forwarded-Proto", proto)
 			}
+			if ir != nil {
+				modifyHeaders(pr.Out.Header, ir.Spec.RequestHeaders)
+			}
 		},
 		BufferPool: bufferPool,
 		Transport:  rt,
 		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			if attempt != nil {
+				attempt.Err = err
+			}
 			code := http.StatusBadGateway
 			var netErr net.Error
 			if errors.As(err, &netErr) && netErr.Timeout() {
 				// Respond with 504 Gateway Timeout on timeouts to differentiate from general server errors
 				code = http.StatusGatewayTimeout
-			} else if errors.Is(err, context.DeadlineExceeded) {
+			} else if errors.Is(err, context.DeadlineExceeded) || errors.Is(context.Cause(r.Context()), context.DeadlineExceeded) {
 				code = http.StatusGatewayTimeout
 			}
 			sh := NewStatic(code, err)
@@ -141,29 +158,54 @@ func (uh *Upstream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		},
 	}
 
+	if ir != nil && ir.Spec.ResponseHeaders != nil {
+		proxy.ModifyResponse = func(resp *http.Response) error {
+			modifyHeaders(resp.Header, ir.Spec.ResponseHeaders)
+			return nil
+		}
+	}
+
 	proxy.ServeHTTP(w, r)
 }
 
+// modifyHeaders applies m to h. A nil m leaves h unchanged.
+func modifyHeaders(h http.Header, m *httpv1beta1.HeaderModifier) {
+	if m == nil {
+		return
+	}
+	for name, value := range m.Set {
+		h.Set(name, value)
+	}
+	for name, value := range m.Add {
+		h.Add(name, value)
+	}
+	for _, name := range m.Remove {
+		h.Del(name)
+	}
+}
+
 func (uh *Upstream) requiresHTTP2(ctx context.Context, ir *httpv1beta1.InterceptorRoute) bool {
 	if ir == nil {
 		return false
 	}
 
+	target := util.TargetRefFromContext(ctx)
+
 	var svc corev1.Service
 	err := uh.reader.Get(ctx, types.NamespacedName{
 		Namespace: ir.Namespace,
-		Name:      ir.Spec.Target.Service,
+		Name:      target.Service,
 	}, &svc)
 	if err != nil {
 		util.LoggerFromContext(ctx).Error(err, "failed to look up Service for appProtocol, using default transport",
-			"service", ir.Spec.Target.Service,
+			"service", target.Service,
 			"namespace", ir.Namespace,
 		)
 		return false
 	}
 
 	for _, port := range svc.Spec.Ports {
-		if !matchesTargetPort(ir.Spec.Target, port) {
+		if !matchesTargetPort(*target, port) {
 			continue
 		}
 		return port.AppProtocol != nil && *port.AppProtocol == appProtocolH2C
@@ -177,3 +219,77 @@ func matchesTargetPort(target httpv1beta1.TargetRef, port corev1.ServicePort) bo
 	}
 	return port.Name == target.PortName
 }
+
+// rewriteRequest applies the route's URL rewrite to the outgoing request.
+func rewriteRequest(pr *httputil.ProxyRequest, rewrite *httpv1beta1.URLRewrite, rules []httpv1beta1.RoutingRule) {
+	if rewrite.Hostname != nil {
+		pr.Out.Host = *rewrite.Hostname
+	}
+
+	switch {
+	case rewrite.ReplaceFullPath != nil:
+		pr.Out.URL.Path = *rewrite.ReplaceFullPath
+		pr.Out.URL.RawPath = ""
+	case rewrite.ReplacePrefixMatch != nil:
+		prefix, ok := routing.MatchedPathPrefix(pr.In, rules)
+		if !ok {
+			return
+		}
+		// The escaped path is rewritten too, so that escaped characters
+		// like "%2F" reach the backend as sent.
+		escapedPrefix, ok := escapedPathPrefix(pr.In.URL.EscapedPath(), prefix)
+		if !ok {
+			// The prefix ends within an escaped segment, e.g. "a%2Fb".
+			return
+		}
+		replacement := *rewrite.ReplacePrefixMatch
+		pr.Out.URL.Path = replacePathPrefix(pr.In.URL.Path, prefix, replacement)
+		pr.Out.URL.RawPath = replacePathPrefix(pr.In.URL.EscapedPath(), escapedPrefix, (&url.URL{Path: replacement}).EscapedPath())
+	}
+}
+
+// escapedPathPrefix returns the leading segments of escapedPath, without
+// leading slashes, that unescape to prefix. It reports false if prefix does
+// not end on a segment boundary of escapedPath.
+func escapedPathPrefix(escapedPath, prefix string) (string, bool) {
+	trimmed := strings.TrimLeft(escapedPath, "/")
+	if prefix == "" {
+		return "", true
+	}
+
+	end := 0
+	for {
+		next := strings.IndexByte(trimmed[end:], '/')
+		if next < 0 {
+			end = len(trimmed)
+		} else {
+			end += next
+		}
+		segments, err := url.PathUnescape(trimmed[:end])
+		if err != nil || len(segments) > len(prefix) {
+			return "", false
+		}
+		if segments == prefix {
+			return trimmed[:end], true
+		}
+		if end == len(trimmed) {
+			return "", false
+		}
+		end++
+	}
+}
+
+// replacePathPrefix replaces prefix, given without leading and trailing
+// slashes, of reqPath with replacement on a path segment boundary.
+func replacePathPrefix(reqPath, prefix, replacement string) string {
+	rest := strings.TrimLeft(reqPath, "/")[len(prefix):]
+	if prefix == "" && rest != "" {
+		rest = "/" + rest
+	}
+
+	path := strings.TrimSuffix(replacement, "/") + rest
+	if path == "" {
+		return "/"
+	}
+	return path
+}
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"slices"
 	"strings"
 	"sync/atomic"
 	"testing"
@@ -22,6 +23,7 @@ import (
 	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
@@ -476,6 +478,184 @@ func TestUpstream_RouteSpecResponseHeaderOverride(t *testing.T) {
 	close(originWaitCh)
 }
 
+func TestUpstream_Rewrite(t *testing.T) {
+	rules := []httpv1beta1.RoutingRule{{
+		Paths: []httpv1beta1.PathMatch{{Value: "/api"}, {Value: "/api/orders"}},
+	}}
+
+	tests := map[string]struct {
+		path     string
+		rewrite  httpv1beta1.URLRewrite
+		wantPath string
+		wantHost string
+	}{
+		"replace longest matched prefix": {
+			path:     "/api/orders/1",
+			rewrite:  httpv1beta1.URLRewrite{ReplacePrefixMatch: ptr.To("/")},
+			wantPath: "/1",
+			wantHost: "example.com",
+		},
+		"replace prefix with path": {
+			path:     "/api/users",
+			rewrite:  httpv1beta1.URLRewrite{ReplacePrefixMatch: ptr.To("/v2/")},
+			wantPath: "/v2/users",
+			wantHost: "example.com",
+		},
+		"replace prefix keeps encoded slash": {
+			path:     "/api/a%2Fb",
+			rewrite:  httpv1beta1.URLRewrite{ReplacePrefixMatch: ptr.To("/v2")},
+			wantPath: "/v2/a%2Fb",
+			wantHost: "example.com",
+		},
+		"prefix ending within encoded segment not replaced": {
+			path:     "/api/orders%2F1",
+			rewrite:  httpv1beta1.URLRewrite{ReplacePrefixMatch: ptr.To("/")},
+			wantPath: "/api/orders%2F1",
+			wantHost: "example.com",
+		},
+		"replace full path": {
+			path:     "/api/orders/1",
+			rewrite:  httpv1beta1.URLRewrite{ReplaceFullPath: ptr.To("/index.html")},
+			wantPath: "/index.html",
+			wantHost: "example.com",
+		},
+		"set hostname": {
+			path:     "/api/orders/1",
+			rewrite:  httpv1beta1.URLRewrite{Hostname: ptr.To("orders.internal")},
+			wantPath: "/api/orders/1",
+			wantHost: "orders.internal",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var gotPath, gotHost, gotForwardedHost string
+			backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath = r.URL.EscapedPath()
+				gotHost = r.Host
+				gotForwardedHost = r.Header.Get("X-Forwarded-Host")
+			}))
+			defer backend.Close()
+
+			backendURL, err := url.Parse(backend.URL)
+			if err != nil {
+				t.Fatalf("failed to parse backend URL: %v", err)
+			}
+
+			ir := &httpv1beta1.InterceptorRoute{
+				Spec: httpv1beta1.InterceptorRouteSpec{
+					Rules:   rules,
+					Rewrite: &tt.rewrite,
+				},
+			}
+
+			req := httptest.NewRequest("GET", tt.path, nil)
+			req.Host = "example.com"
+			req = util.RequestWithUpstreamURL(req, backendURL)
+			req = req.WithContext(util.ContextWithInterceptorRoute(req.Context(), ir))
+
+			upstream := NewUpstream(http.DefaultTransport.(*http.Transport), newFakeClient(), config.Tracing{}, 500*time.Millisecond)
+			upstream.ServeHTTP(httptest.NewRecorder(), req)
+
+			if gotPath != tt.wantPath {
+				t.Errorf("path: got %q, want %q", gotPath, tt.wantPath)
+			}
+			if gotHost != tt.wantHost {
+				t.Errorf("host: got %q, want %q", gotHost, tt.wantHost)
+			}
+			if gotForwardedHost != "example.com" {
+				t.Errorf("X-Forwarded-Host: got %q, want %q", gotForwardedHost, "example.com")
+			}
+		})
+	}
+}
+
+func TestUpstream_HeaderModifiers(t *testing.T) {
+	var gotHeaders http.Header
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeaders = r.Header.Clone()
+		w.Header().Set("X-Debug", "internal")
+		w.Header().Set("Server", "backend")
+	}))
+	defer backend.Close()
+
+	backendURL, err := url.Parse(backend.URL)
+	if err != nil {
+		t.Fatalf("failed to parse backend URL: %v", err)
+	}
+
+	ir := &httpv1beta1.InterceptorRoute{
+		Spec: httpv1beta1.InterceptorRouteSpec{
+			RequestHeaders: &httpv1beta1.HeaderModifier{
+				Set:    map[string]string{"X-Request-Id": "generated"},
+				Add:    map[string]string{"X-Tenant": "b"},
+				Remove: []string{"X-Internal"},
+			},
+			ResponseHeaders: &httpv1beta1.HeaderModifier{
+				Set:    map[string]string{"Server": "keda"},
+				Add:    map[string]string{"Strict-Transport-Security": "max-age=31536000"},
+				Remove: []string{"X-Debug"},
+			},
+		},
+	}
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("X-Request-Id", "client")
+	req.Header.Set("X-Tenant", "a")
+	req.Header.Set("X-Internal", "secret")
+	req = util.RequestWithUpstreamURL(req, backendURL)
+	req = req.WithContext(util.ContextWithInterceptorRoute(req.Context(), ir))
+
+	rec := httptest.NewRecorder()
+	upstream := NewUpstream(http.DefaultTransport.(*http.Transport), newFakeClient(), config.Tracing{}, 500*time.Millisecond)
+	upstream.ServeHTTP(rec, req)
+
+	if got, want := gotHeaders.Values("X-Request-Id"), []string{"generated"}; !slices.Equal(got, want) {
+		t.Errorf("request X-Request-Id: got %q, want %q", got, want)
+	}
+	if got, want := gotHeaders.Values("X-Tenant"), []string{"a", "b"}; !slices.Equal(got, want) {
+		t.Errorf("request X-Tenant: got %q, want %q", got, want)
+	}
+	if got := gotHeaders.Get("X-Internal"); got != "" {
+		t.Errorf("request X-Internal: got %q, want it removed", got)
+	}
+
+	res := rec.Result()
+	if got, want := res.Header.Get("Server"), "keda"; got != want {
+		t.Errorf("response Server: got %q, want %q", got, want)
+	}
+	if got, want := res.Header.Get("Strict-Transport-Security"), "max-age=31536000"; got != want {
+		t.Errorf("response Strict-Transport-Security: got %q, want %q", got, want)
+	}
+	if got := res.Header.Get("X-Debug"); got != "" {
+		t.Errorf("response X-Debug: got %q, want it removed", got)
+	}
+}
+
+func TestReplacePathPrefix(t *testing.T) {
+	tests := []struct {
+		path        string
+		prefix      string
+		replacement string
+		want        string
+	}{
+		{path: "/foo/bar", prefix: "foo", replacement: "/xyz", want: "/xyz/bar"},
+		{path: "/foo/bar", prefix: "foo", replacement: "/xyz/", want: "/xyz/bar"},
+		{path: "/foo", prefix: "foo", replacement: "/xyz", want: "/xyz"},
+		{path: "/foo", prefix: "foo", replacement: "/", want: "/"},
+		{path: "/foo/bar", prefix: "foo", replacement: "/", want: "/bar"},
+		{path: "/foo/", prefix: "foo", replacement: "/", want: "/"},
+		{path: "/foo/bar", prefix: "", replacement: "/v1", want: "/v1/foo/bar"},
+		{path: "/", prefix: "", replacement: "/v1", want: "/v1"},
+	}
+
+	for _, tt := range tests {
+		if got := replacePathPrefix(tt.path, tt.prefix, tt.replacement); got != tt.want {
+			t.Errorf("replacePathPrefix(%q, %q, %q) = %q, want %q", tt.path, tt.prefix, tt.replacement, got, tt.want)
+		}
+	}
+}
+
 // TestFullDuplexBodyPanic verifies that the upstream handler does not trigger
 // a "invalid concurrent Body.Read call" panic (golang/go#68560) when the
 // reverse proxy's RoundTrip fails without consuming the request body.
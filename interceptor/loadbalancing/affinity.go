@@ -0,0 +1,105 @@
+package loadbalancing
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+
+	httpv1beta1 "github.com/kedacore/http-add-on/operator/apis/http/v1beta1"
+	"github.com/kedacore/http-add-on/pkg/k8s"
+)
+
+// DefaultAffinityCookieName is the session affinity cookie of routes not
+// naming one.
+const DefaultAffinityCookieName = "keda-http-affinity"
+
+// Affinity pins the requests of a client to a pod with a cookie. The cookie
+// holds a hash of the pod's "ip:port" so that pod addresses are not exposed.
+type Affinity struct {
+	spec *httpv1beta1.SessionAffinitySpec
+	// value is the cookie value of the request, "" if it has none.
+	value string
+}
+
+// NewAffinity returns the session affinity of r for spec, or nil if spec is
+// nil.
+func NewAffinity(r *http.Request, spec *httpv1beta1.SessionAffinitySpec) *Affinity {
+	if spec == nil {
+		return nil
+	}
+
+	a := &Affinity{spec: spec}
+	if c, err := r.Cookie(a.cookieName()); err == nil {
+		a.value = c.Value
+	}
+	return a
+}
+
+// Picker returns a HostPicker selecting the pod named by the request's
+// cookie, if it is still among the hosts, and otherwise the host selected by
+// pick, or a random one if pick is nil. ready are the ready pods of every
+// zone: while the pod of the cookie is among them, the picker rejects hosts
+// without it, so that zone preference does not move the client to another pod.
+func (a *Affinity) Picker(pick k8s.HostPicker, ready []string) k8s.HostPicker {
+	pinned := false
+	if a.value != "" {
+		for _, host := range ready {
+			if affinityValue(host) == a.value {
+				pinned = true
+				break
+			}
+		}
+	}
+
+	return func(hosts []string) string {
+		if a.value != "" {
+			for _, host := range hosts {
+				if affinityValue(host) == a.value {
+					return host
+				}
+			}
+		}
+		if pinned {
+			// The pod of the cookie is ready in another zone.
+			return ""
+		}
+		if pick != nil {
+			return pick(hosts)
+		}
+		return hosts[rand.IntN(len(hosts))] //nolint:gosec // G404: math/rand is sufficient for load-balancing endpoint selection
+	}
+}
+
+// SetCookie sets the cookie naming host on w, unless the request's cookie
+// already names it.
+func (a *Affinity) SetCookie(w http.ResponseWriter, r *http.Request, host string) {
+	value := affinityValue(host)
+	if value == a.value {
+		return
+	}
+
+	c := &http.Cookie{
+		Name:     a.cookieName(),
+		Value:    value,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	}
+	if a.spec.MaxAge != nil {
+		c.MaxAge = int(a.spec.MaxAge.Seconds())
+	}
+	http.SetCookie(w, c)
+}
+
+func (a *Affinity) cookieName() string {
+	if a.spec.CookieName != "" {
+		return a.spec.CookieName
+	}
+	return DefaultAffinityCookieName
+}
+
+// affinityValue returns the cookie value naming host.
+func affinityValue(host string) string {
+	return strconv.FormatUint(mix(fnv1a(fnvOffset64, host)), 36)
+}
@@ -0,0 +1,79 @@
+package loadbalancing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	httpv1beta1 "github.com/kedacore/http-add-on/operator/apis/http/v1beta1"
+)
+
+func TestAffinity(t *testing.T) {
+	spec := &httpv1beta1.SessionAffinitySpec{MaxAge: &metav1.Duration{Duration: time.Hour}}
+	first := func(hosts []string) string { return hosts[0] }
+
+	// Without a cookie the pick is delegated and a cookie is set.
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	a := NewAffinity(req, spec)
+	host := a.Picker(first, testHosts)(testHosts)
+	if host != testHosts[0] {
+		t.Fatalf("pick without cookie = %s, want %s", host, testHosts[0])
+	}
+	rec := httptest.NewRecorder()
+	a.SetCookie(rec, req, testHosts[1])
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != DefaultAffinityCookieName || cookies[0].MaxAge != 3600 || !cookies[0].HttpOnly {
+		t.Fatalf("cookies = %+v, want one %s cookie", cookies, DefaultAffinityCookieName)
+	}
+
+	// With the cookie, the named pod is picked while it is ready.
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(cookies[0])
+	a = NewAffinity(req, spec)
+	if got := a.Picker(first, testHosts)(testHosts); got != testHosts[1] {
+		t.Errorf("pick with cookie = %s, want %s", got, testHosts[1])
+	}
+	rec = httptest.NewRecorder()
+	a.SetCookie(rec, req, testHosts[1])
+	if got := rec.Header().Get("Set-Cookie"); got != "" {
+		t.Errorf("Set-Cookie = %q, want none for an unchanged pod", got)
+	}
+
+	// Once the pod is gone, the pick falls back to the policy.
+	remaining := []string{testHosts[0], testHosts[2]}
+	if got := a.Picker(first, remaining)(remaining); got != testHosts[0] {
+		t.Errorf("pick with stale cookie = %s, want %s", got, testHosts[0])
+	}
+}
+
+func TestAffinityAcrossZones(t *testing.T) {
+	spec := &httpv1beta1.SessionAffinitySpec{}
+	first := func(hosts []string) string { return hosts[0] }
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: DefaultAffinityCookieName, Value: affinityValue(testHosts[2])})
+	a := NewAffinity(req, spec)
+
+	// The pod of the cookie is ready in another zone: the local zone is
+	// rejected so that the pod is picked among all of them.
+	pick := a.Picker(first, testHosts)
+	if got := pick(testHosts[:2]); got != "" {
+		t.Errorf("pick in the local zone = %s, want none", got)
+	}
+	if got := pick(testHosts); got != testHosts[2] {
+		t.Errorf("pick in all zones = %s, want %s", got, testHosts[2])
+	}
+
+	// Once it is not ready anymore, the local zone is picked from.
+	if got := a.Picker(first, testHosts[:2])(testHosts[:2]); got != testHosts[0] {
+		t.Errorf("pick with stale cookie = %s, want %s", got, testHosts[0])
+	}
+}
+
+func TestAffinityDisabled(t *testing.T) {
+	if a := NewAffinity(httptest.NewRequest(http.MethodGet, "/", nil), nil); a != nil {
+		t.Errorf("NewAffinity(nil) = %+v, want nil", a)
+	}
+}
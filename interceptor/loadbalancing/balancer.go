@@ -0,0 +1,205 @@
+// Package loadbalancing selects the ready pod of requests routed directly to
+// pods, following the load-balancing policy of their InterceptorRoute.
+package loadbalancing
+
+import (
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	httpv1beta1 "github.com/kedacore/http-add-on/operator/apis/http/v1beta1"
+	"github.com/kedacore/http-add-on/pkg/k8s"
+)
+
+// Balancer implements the load-balancing policies. It tracks the requests in
+// flight to each pod and the round-robin position of each service port.
+type Balancer struct {
+	mu       sync.Mutex
+	inflight map[string]int // "ip:port" -> requests in flight
+
+	// "namespace/service/portName" -> *atomic.Uint64
+	next sync.Map
+}
+
+// New returns a Balancer without requests in flight.
+func New() *Balancer {
+	return &Balancer{inflight: make(map[string]int)}
+}
+
+// Picker returns the HostPicker implementing spec for r. key identifies the
+// service port for round-robin. Returns nil, picking a random host, for the
+// Random policy and for consistent hashing of requests without a hash key.
+func (b *Balancer) Picker(r *http.Request, spec *httpv1beta1.LoadBalancingSpec, key string) k8s.HostPicker {
+	if spec == nil {
+		return nil
+	}
+
+	switch spec.Policy {
+	case httpv1beta1.LoadBalancingRoundRobin:
+		return b.roundRobin(key)
+	case httpv1beta1.LoadBalancingLeastRequest:
+		return b.leastRequest
+	case httpv1beta1.LoadBalancingPowerOfTwoChoices:
+		return b.powerOfTwoChoices
+	case httpv1beta1.LoadBalancingConsistentHash:
+		hashKey := requestHashKey(r, spec.HashKey)
+		if hashKey == "" {
+			return nil
+		}
+		return func(hosts []string) string { return rendezvous(hashKey, hosts) }
+	default:
+		return nil
+	}
+}
+
+// Excluding returns a HostPicker selecting with pick, or randomly if pick is
+// nil, among the hosts not in exclude. It rejects hosts that are all
+// excluded.
+func Excluding(pick k8s.HostPicker, exclude []string) k8s.HostPicker {
+	return func(hosts []string) string {
+		remaining := make([]string, 0, len(hosts))
+		for _, host := range hosts {
+			if !slices.Contains(exclude, host) {
+				remaining = append(remaining, host)
+			}
+		}
+		switch {
+		case len(remaining) == 0:
+			return ""
+		case pick != nil:
+			return pick(remaining)
+		default:
+			return remaining[rand.IntN(len(remaining))] //nolint:gosec // G404: math/rand is sufficient for load-balancing endpoint selection
+		}
+	}
+}
+
+// Track counts a request in flight to host until the returned function is
+// called.
+func (b *Balancer) Track(host string) (done func()) {
+	b.mu.Lock()
+	b.inflight[host]++
+	b.mu.Unlock()
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.inflight[host]--
+		if b.inflight[host] <= 0 {
+			delete(b.inflight, host)
+		}
+	}
+}
+
+func (b *Balancer) roundRobin(key string) k8s.HostPicker {
+	v, _ := b.next.LoadOrStore(key, new(atomic.Uint64))
+	counter := v.(*atomic.Uint64)
+	return func(hosts []string) string {
+		return hosts[(counter.Add(1)-1)%uint64(len(hosts))]
+	}
+}
+
+// leastRequest picks the host with the fewest requests in flight. Ties are
+// broken by starting the scan at a random host.
+func (b *Balancer) leastRequest(hosts []string) string {
+	start := rand.IntN(len(hosts)) //nolint:gosec // G404: math/rand is sufficient for load-balancing endpoint selection
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	best, bestCount := "", 0
+	for i := range hosts {
+		host := hosts[(start+i)%len(hosts)]
+		if count := b.inflight[host]; best == "" || count < bestCount {
+			best, bestCount = host, count
+		}
+	}
+	return best
+}
+
+// powerOfTwoChoices picks the host with fewer requests in flight out of two
+// distinct random hosts.
+func (b *Balancer) powerOfTwoChoices(hosts []string) string {
+	if len(hosts) == 1 {
+		return hosts[0]
+	}
+	i := rand.IntN(len(hosts))     //nolint:gosec // G404: math/rand is sufficient for load-balancing endpoint selection
+	j := rand.IntN(len(hosts) - 1) //nolint:gosec // G404: math/rand is sufficient for load-balancing endpoint selection
+	if j >= i {
+		j++
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.inflight[hosts[j]] < b.inflight[hosts[i]] {
+		return hosts[j]
+	}
+	return hosts[i]
+}
+
+// requestHashKey returns the part of r selected by hk, or "" if r has none.
+func requestHashKey(r *http.Request, hk *httpv1beta1.HashKey) string {
+	switch {
+	case hk == nil:
+		return ""
+	case hk.Header != "":
+		return r.Header.Get(hk.Header)
+	case hk.Cookie != "":
+		c, err := r.Cookie(hk.Cookie)
+		if err != nil {
+			return ""
+		}
+		return c.Value
+	case hk.SourceIP:
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			return r.RemoteAddr
+		}
+		return host
+	}
+	return ""
+}
+
+// rendezvous picks the host with the highest hash of key and host, so that
+// adding or removing a host only moves the keys of that host and every
+// interceptor replica picks the same host for a key.
+func rendezvous(key string, hosts []string) string {
+	keyHash := fnv1a(fnvOffset64, key)
+
+	var best string
+	var bestScore uint64
+	for _, host := range hosts {
+		if score := mix(fnv1a(keyHash, host)); best == "" || score > bestScore {
+			best, bestScore = host, score
+		}
+	}
+	return best
+}
+
+const (
+	fnvOffset64 = 14695981039346656037
+	fnvPrime64  = 1099511628211
+)
+
+// fnv1a continues the 64-bit FNV-1a hash h with s.
+func fnv1a(h uint64, s string) uint64 {
+	for i := 0; i < len(s); i++ {
+		h ^= uint64(s[i])
+		h *= fnvPrime64
+	}
+	return h
+}
+
+// mix is the splitmix64 finalizer, spreading hashes sharing a key prefix.
+func mix(h uint64) uint64 {
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+	return h
+}
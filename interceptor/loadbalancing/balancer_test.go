@@ -0,0 +1,154 @@
+package loadbalancing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	httpv1beta1 "github.com/kedacore/http-add-on/operator/apis/http/v1beta1"
+)
+
+var testHosts = []string{"10.0.0.1:8080", "10.0.0.2:8080", "10.0.0.3:8080"}
+
+func TestPickerNil(t *testing.T) {
+	b := New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	tests := map[string]*httpv1beta1.LoadBalancingSpec{
+		"no spec": nil,
+		"random":  {Policy: httpv1beta1.LoadBalancingRandom},
+		"consistent hash without key": {
+			Policy:  httpv1beta1.LoadBalancingConsistentHash,
+			HashKey: &httpv1beta1.HashKey{Header: "X-User"},
+		},
+	}
+	for name, spec := range tests {
+		t.Run(name, func(t *testing.T) {
+			if pick := b.Picker(req, spec, "ns/svc/"); pick != nil {
+				t.Error("Picker() != nil, want nil for a random pick")
+			}
+		})
+	}
+}
+
+func TestRoundRobin(t *testing.T) {
+	b := New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	spec := &httpv1beta1.LoadBalancingSpec{Policy: httpv1beta1.LoadBalancingRoundRobin}
+
+	for i := range 2 * len(testHosts) {
+		if got, want := b.Picker(req, spec, "ns/svc/")(testHosts), testHosts[i%len(testHosts)]; got != want {
+			t.Fatalf("pick %d = %s, want %s", i, got, want)
+		}
+	}
+
+	// Each service port has its own position.
+	if got := b.Picker(req, spec, "ns/other/")(testHosts); got != testHosts[0] {
+		t.Errorf("first pick of another service = %s, want %s", got, testHosts[0])
+	}
+}
+
+func TestLeastRequest(t *testing.T) {
+	b := New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	spec := &httpv1beta1.LoadBalancingSpec{Policy: httpv1beta1.LoadBalancingLeastRequest}
+
+	done0 := b.Track(testHosts[0])
+	b.Track(testHosts[2])
+	b.Track(testHosts[2])
+
+	for range 10 {
+		if got := b.Picker(req, spec, "")(testHosts); got != testHosts[1] {
+			t.Fatalf("pick = %s, want idle %s", got, testHosts[1])
+		}
+	}
+
+	b.Track(testHosts[1])
+	b.Track(testHosts[1])
+	done0()
+	if got := b.Picker(req, spec, "")(testHosts); got != testHosts[0] {
+		t.Errorf("pick after release = %s, want %s", got, testHosts[0])
+	}
+}
+
+func TestPowerOfTwoChoices(t *testing.T) {
+	b := New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	spec := &httpv1beta1.LoadBalancingSpec{Policy: httpv1beta1.LoadBalancingPowerOfTwoChoices}
+
+	// The busiest host is never the less loaded of two distinct hosts.
+	b.Track(testHosts[0])
+	b.Track(testHosts[0])
+	b.Track(testHosts[1])
+	for range 100 {
+		if got := b.Picker(req, spec, "")(testHosts); got == testHosts[0] {
+			t.Fatalf("picked the busiest host %s", got)
+		}
+	}
+
+	if got := b.Picker(req, spec, "")(testHosts[:1]); got != testHosts[0] {
+		t.Errorf("pick of a single host = %s, want %s", got, testHosts[0])
+	}
+}
+
+func TestConsistentHash(t *testing.T) {
+	b := New()
+	spec := &httpv1beta1.LoadBalancingSpec{
+		Policy:  httpv1beta1.LoadBalancingConsistentHash,
+		HashKey: &httpv1beta1.HashKey{Header: "X-User"},
+	}
+	pick := func(user string, hosts []string) string {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-User", user)
+		return b.Picker(req, spec, "")(hosts)
+	}
+
+	users := []string{"alice", "bob", "carol", "dave", "erin", "frank", "grace", "heidi"}
+	picks := make(map[string]string)
+	used := make(map[string]bool)
+	for _, u := range users {
+		picks[u] = pick(u, testHosts)
+		used[picks[u]] = true
+		if again := pick(u, testHosts); again != picks[u] {
+			t.Errorf("user %s picked %s then %s", u, picks[u], again)
+		}
+	}
+	if len(used) < 2 {
+		t.Errorf("all users hashed to %v", used)
+	}
+
+	// Removing a host only moves the users of that host.
+	removed := testHosts[2]
+	for _, u := range users {
+		got := pick(u, testHosts[:2])
+		if picks[u] != removed && got != picks[u] {
+			t.Errorf("user %s moved from %s to %s after removing %s", u, picks[u], got, removed)
+		}
+	}
+}
+
+func TestRequestHashKey(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "192.0.2.1:51234"
+	req.Header.Set("X-User", "alice")
+	req.AddCookie(&http.Cookie{Name: "session", Value: "abc"})
+
+	tests := map[string]struct {
+		hashKey *httpv1beta1.HashKey
+		want    string
+	}{
+		"header":         {hashKey: &httpv1beta1.HashKey{Header: "X-User"}, want: "alice"},
+		"missing header": {hashKey: &httpv1beta1.HashKey{Header: "X-Missing"}, want: ""},
+		"cookie":         {hashKey: &httpv1beta1.HashKey{Cookie: "session"}, want: "abc"},
+		"missing cookie": {hashKey: &httpv1beta1.HashKey{Cookie: "missing"}, want: ""},
+		"source ip":      {hashKey: &httpv1beta1.HashKey{SourceIP: true}, want: "192.0.2.1"},
+		"nil":            {hashKey: nil, want: ""},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := requestHashKey(req, tt.hashKey); got != tt.want {
+				t.Errorf("requestHashKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
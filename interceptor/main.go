@@ -8,6 +8,7 @@ import (
 	"net/http"
 	_ "net/http/pprof" //nolint:gosec // G108: pprof intentionally exposed, gated by --profiling-addr
 	"os"
+	"strings"
 	"sync/atomic"
 	"time"
 
@@ -15,15 +16,21 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"golang.org/x/sync/errgroup"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/rest"
 	toolscache "k8s.io/client-go/tools/cache"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
+	"github.com/kedacore/http-add-on/interceptor/circuitbreaker"
 	"github.com/kedacore/http-add-on/interceptor/config"
 	"github.com/kedacore/http-add-on/interceptor/handler"
 	"github.com/kedacore/http-add-on/interceptor/metrics"
+	"github.com/kedacore/http-add-on/interceptor/standalone"
 	"github.com/kedacore/http-add-on/interceptor/tracing"
 	"github.com/kedacore/http-add-on/operator/apis/http/v1alpha1"
 	"github.com/kedacore/http-add-on/operator/apis/http/v1beta1"
@@ -41,9 +48,13 @@ var setupLog = ctrl.Log.WithName("setup")
 
 // +kubebuilder:rbac:groups=http.keda.sh,resources=httpscaledobjects,verbs=get;list;watch
 // +kubebuilder:rbac:groups=http.keda.sh,resources=interceptorroutes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=http.keda.sh,resources=hostclaims,verbs=get;list;watch
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch
 // +kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get
 
 func main() {
 	if err := run(); err != nil {
@@ -55,6 +66,11 @@ func main() {
 func run() error {
 	timeoutCfg := config.MustParseTimeouts(setupLog)
 	servingCfg := config.MustParseServing()
+	if servingCfg.StandaloneDir != "" {
+		// Service DNS names don't resolve without a cluster, so requests are
+		// sent to the addresses of the loaded EndpointSlices.
+		servingCfg.DirectPodRouting = true
+	}
 	metricsCfg := observability.MustParseMetricsConfig()
 	tracingCfg := observability.MustParseTracingConfig()
 
@@ -88,57 +104,41 @@ func run() error {
 		return fmt.Errorf("creating metric instruments: %w", err)
 	}
 
-	cfg := ctrl.GetConfigOrDie()
 	signalCtx := ctrl.SetupSignalHandler()
 
-	cacheOpts := cache.Options{
-		DefaultTransform: cache.TransformStripManagedFields(),
-		Scheme:           kedacache.NewScheme(),
-		SyncPeriod:       &servingCfg.CacheSyncPeriod,
-		// Scope the ConfigMap informer to only cache labeled ConfigMaps,
-		// avoiding cluster-wide caching of all ConfigMaps on first Get.
-		ByObject: map[client.Object]cache.ByObject{
-			&corev1.ConfigMap{}: {
-				Label: k8s.ResponseBodyLabels.AsSelector(),
-			},
-		},
+	queues := queue.NewMemory()
+	tableOpts := routing.TableOptions{
+		FirstComeHostOwnership: servingCfg.FirstComeHostOwnership,
+		IngressClassName:       servingCfg.IngressClassName,
 	}
-	if servingCfg.WatchNamespace != "" {
-		cacheOpts.DefaultNamespaces = map[string]cache.Config{
-			servingCfg.WatchNamespace: {},
+	if servingCfg.GatewayAPIParentRef != "" {
+		parent, err := routing.ParseHTTPRouteParent(servingCfg.GatewayAPIParentRef)
+		if err != nil {
+			return fmt.Errorf("parsing Gateway API parent: %w", err)
 		}
+		tableOpts.HTTPRouteParent = &parent
 	}
 
-	ctrlCache, err := cache.New(cfg, cacheOpts)
-	if err != nil {
-		return fmt.Errorf("creating cache: %w", err)
+	var src *routeSource
+	if servingCfg.StandaloneDir != "" {
+		src, err = newStandaloneSource(servingCfg, queues, tableOpts)
+	} else {
+		src, err = newClusterSource(signalCtx, servingCfg, queues, tableOpts)
 	}
-
-	readyCache, err := k8s.NewReadyEndpointsCacheWithInformer(signalCtx, ctrl.Log, ctrlCache)
 	if err != nil {
-		return fmt.Errorf("creating endpoints cache: %w", err)
-	}
-
-	queues := queue.NewMemory()
-	routingTable := routing.NewTable(ctrlCache, queues)
-
-	// Setup informers to signal routing table on IR and HTTPSO changes
-	routeSources := []client.Object{&v1beta1.InterceptorRoute{}, &v1alpha1.HTTPScaledObject{}}
-	for _, obj := range routeSources {
-		informer, err := ctrlCache.GetInformer(signalCtx, obj)
-		if err != nil {
-			return fmt.Errorf("getting informer for %T: %w", obj, err)
-		}
-
-		_, err = informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
-			AddFunc:    func(_ any) { routingTable.Signal() },
-			UpdateFunc: func(_, _ any) { routingTable.Signal() },
-			DeleteFunc: func(_ any) { routingTable.Signal() },
-		})
-		if err != nil {
-			return fmt.Errorf("adding event handlers: %w", err)
-		}
+		return err
 	}
+	readyCache, routingTable := src.readyCache, src.routingTable
+	readyCache.SetOutlierDetection(k8s.OutlierDetection{
+		ConsecutiveFailures: servingCfg.OutlierConsecutiveFailures,
+		BaseEjectionTime:    servingCfg.OutlierBaseEjectionTime,
+		MaxEjectionTime:     servingCfg.OutlierMaxEjectionTime,
+		MaxEjectionPercent:  servingCfg.OutlierMaxEjectionPercent,
+		OnEject: func(serviceKey, _ string) {
+			namespace, service, _ := strings.Cut(serviceKey, "/")
+			instruments.RecordEjection(namespace, service)
+		},
+	})
 
 	if tracingCfg.Enabled {
 		shutdown, err := tracing.SetupOTelSDK(signalCtx, tracingCfg)
@@ -152,6 +152,11 @@ func run() error {
 		}()
 	}
 
+	circuits := circuitbreaker.NewRegistry(func(serviceKey string, state circuitbreaker.State) {
+		namespace, service, _ := strings.Cut(serviceKey, "/")
+		instruments.RecordCircuitState(namespace, service, int64(state))
+	})
+
 	var draining atomic.Bool
 	probeHandler := handler.NewProbe(&draining, routingTable)
 
@@ -174,17 +179,12 @@ func run() error {
 
 	// --- Infrastructure goroutines (use infraCtx) ---
 
-	infraEg.Go(func() error {
-		setupLog.Info("starting the controller-runtime cache")
-		return ctrlCache.Start(infraCtx)
-	})
-
-	// Wait for cache to sync before starting components that depend on it
-	if !ctrlCache.WaitForCacheSync(infraCtx) {
-		return fmt.Errorf("cache failed to sync")
+	// Wait for the source to sync before starting components that depend on it
+	if err := src.start(infraCtx, infraEg); err != nil {
+		return err
 	}
 
-	// Start the update loop that refreshes the routing table on InterceptorRoute & HTTPSO changes
+	// Start the update loop that refreshes the routing table on InterceptorRoute, HTTPSO & HostClaim changes
 	infraEg.Go(func() error {
 		setupLog.Info("starting the routing table")
 		if err := routingTable.Start(infraCtx); !util.IsIgnoredErr(err) {
@@ -202,7 +202,7 @@ func run() error {
 	// that serves the queue size API
 	infraEg.Go(func() error {
 		setupLog.Info("starting the admin server", "port", servingCfg.AdminPort)
-		if err := runAdminServer(infraCtx, ctrl.Log, servingCfg.AdminPort, queues, probeHandler); !util.IsIgnoredErr(err) {
+		if err := runAdminServer(infraCtx, ctrl.Log, servingCfg.AdminPort, queues, routingTable, circuits, probeHandler); !util.IsIgnoredErr(err) {
 			return fmt.Errorf("admin server: %w", err)
 		}
 		return nil
@@ -253,7 +253,7 @@ func run() error {
 			}
 
 			setupLog.Info("starting the proxy server with TLS enabled", "port", servingCfg.TLSPort)
-			if err := runProxyServer(proxyCtx, ctrl.Log, queues, readyCache, routingTable, ctrlCache, timeoutCfg, servingCfg, servingCfg.TLSPort, tlsCfg, tracingCfg, instruments, &draining); !util.IsIgnoredErr(err) {
+			if err := runProxyServer(proxyCtx, ctrl.Log, queues, readyCache, routingTable, src.reader, timeoutCfg, servingCfg, servingCfg.TLSPort, tlsCfg, tracingCfg, instruments, circuits, &draining); !util.IsIgnoredErr(err) {
 				return fmt.Errorf("tls proxy server: %w", err)
 			}
 			return nil
@@ -262,7 +262,7 @@ func run() error {
 
 	proxyEg.Go(func() error {
 		setupLog.Info("starting the proxy server", "port", servingCfg.ProxyPort)
-		if err := runProxyServer(proxyCtx, ctrl.Log, queues, readyCache, routingTable, ctrlCache, timeoutCfg, servingCfg, servingCfg.ProxyPort, nil, tracingCfg, instruments, &draining); !util.IsIgnoredErr(err) {
+		if err := runProxyServer(proxyCtx, ctrl.Log, queues, readyCache, routingTable, src.reader, timeoutCfg, servingCfg, servingCfg.ProxyPort, nil, tracingCfg, instruments, circuits, &draining); !util.IsIgnoredErr(err) {
 			return fmt.Errorf("proxy server: %w", err)
 		}
 		return nil
@@ -312,16 +312,170 @@ func run() error {
 	return nil
 }
 
+// routeSource provides the routes, Services, EndpointSlices and ConfigMaps
+// the proxy serves.
+type routeSource struct {
+	reader       client.Reader
+	readyCache   *k8s.ReadyEndpointsCache
+	routingTable routing.Table
+	// start runs the source in eg and returns once its objects are synced.
+	start func(ctx context.Context, eg *errgroup.Group) error
+}
+
+// watchRouteSources sets up informers to signal routingTable on route source
+// and HostClaim changes. HostClaims are not watched if their CRD is not
+// installed, until the interceptor restarts.
+func watchRouteSources(ctx context.Context, informers cache.Informers, routingTable routing.Table, tableOpts routing.TableOptions) error {
+	routeSources := []client.Object{&v1beta1.InterceptorRoute{}, &v1alpha1.HTTPScaledObject{}, &v1beta1.HostClaim{}}
+	if tableOpts.HTTPRouteParent != nil {
+		// Only watched when enabled, the Gateway API CRDs may not be installed.
+		routeSources = append(routeSources, &gatewayv1.HTTPRoute{})
+	}
+	if tableOpts.IngressClassName != "" {
+		routeSources = append(routeSources, &networkingv1.Ingress{})
+	}
+	for _, obj := range routeSources {
+		informer, err := informers.GetInformer(ctx, obj)
+		if _, hostClaim := obj.(*v1beta1.HostClaim); hostClaim && meta.IsNoMatchError(err) {
+			setupLog.Info("HostClaim CRD not installed, hosts are not claimed")
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("getting informer for %T: %w", obj, err)
+		}
+
+		_, err = informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+			AddFunc:    func(_ any) { routingTable.Signal() },
+			UpdateFunc: func(_, _ any) { routingTable.Signal() },
+			DeleteFunc: func(_ any) { routingTable.Signal() },
+		})
+		if err != nil {
+			return fmt.Errorf("adding event handlers: %w", err)
+		}
+	}
+	return nil
+}
+
+// newClusterSource returns a source backed by a controller-runtime cache of
+// the Kubernetes cluster.
+func newClusterSource(ctx context.Context, servingCfg config.Serving, queues queue.Counter, tableOpts routing.TableOptions) (*routeSource, error) {
+	cfg := ctrl.GetConfigOrDie()
+	cacheOpts := cache.Options{
+		DefaultTransform: cache.TransformStripManagedFields(),
+		Scheme:           kedacache.NewScheme(),
+		SyncPeriod:       &servingCfg.CacheSyncPeriod,
+		// Scope the ConfigMap informer to only cache labeled ConfigMaps,
+		// avoiding cluster-wide caching of all ConfigMaps on first Get.
+		ByObject: map[client.Object]cache.ByObject{
+			&corev1.ConfigMap{}: {
+				Label: k8s.ResponseBodyLabels.AsSelector(),
+			},
+		},
+	}
+	if servingCfg.WatchNamespace != "" {
+		cacheOpts.DefaultNamespaces = map[string]cache.Config{
+			servingCfg.WatchNamespace: {},
+		}
+	}
+
+	ctrlCache, err := cache.New(cfg, cacheOpts)
+	if err != nil {
+		return nil, fmt.Errorf("creating cache: %w", err)
+	}
+
+	readyCache, err := k8s.NewReadyEndpointsCacheWithInformer(ctx, ctrl.Log, ctrlCache)
+	if err != nil {
+		return nil, fmt.Errorf("creating endpoints cache: %w", err)
+	}
+	readyCache.SetLocalZone(localZone(ctx, cfg, servingCfg))
+
+	routingTable := routing.NewTable(ctrlCache, queues, tableOpts)
+
+	if err := watchRouteSources(ctx, ctrlCache, routingTable, tableOpts); err != nil {
+		return nil, err
+	}
+
+	return &routeSource{
+		reader:       ctrlCache,
+		readyCache:   readyCache,
+		routingTable: routingTable,
+		start: func(ctx context.Context, eg *errgroup.Group) error {
+			eg.Go(func() error {
+				setupLog.Info("starting the controller-runtime cache")
+				return ctrlCache.Start(ctx)
+			})
+			if !ctrlCache.WaitForCacheSync(ctx) {
+				return fmt.Errorf("cache failed to sync")
+			}
+			return nil
+		},
+	}, nil
+}
+
+// newStandaloneSource returns a source loading the files of
+// servingCfg.StandaloneDir.
+func newStandaloneSource(servingCfg config.Serving, queues queue.Counter, tableOpts routing.TableOptions) (*routeSource, error) {
+	readyCache := k8s.NewReadyEndpointsCache(ctrl.Log)
+	readyCache.SetLocalZone(servingCfg.Zone)
+	source, err := standalone.NewSource(ctrl.Log, servingCfg.StandaloneDir, readyCache)
+	if err != nil {
+		return nil, fmt.Errorf("creating standalone source: %w", err)
+	}
+	routingTable := routing.NewTable(source, queues, tableOpts)
+
+	return &routeSource{
+		reader:       source,
+		readyCache:   readyCache,
+		routingTable: routingTable,
+		start: func(ctx context.Context, eg *errgroup.Group) error {
+			eg.Go(func() error {
+				setupLog.Info("watching the standalone directory", "dir", servingCfg.StandaloneDir)
+				if err := source.Start(ctx, routingTable.Signal); !util.IsIgnoredErr(err) {
+					return fmt.Errorf("standalone source: %w", err)
+				}
+				return nil
+			})
+			return nil
+		},
+	}, nil
+}
+
+// localZone returns the zone of the interceptor pod, from servingCfg.Zone or
+// the zone label of its node. Returns "" if the zone is unknown, disabling
+// same-zone preference.
+func localZone(ctx context.Context, cfg *rest.Config, servingCfg config.Serving) string {
+	if servingCfg.Zone != "" || servingCfg.NodeName == "" {
+		return servingCfg.Zone
+	}
+
+	cl, err := client.New(cfg, client.Options{Scheme: kedacache.NewScheme()})
+	if err != nil {
+		setupLog.Error(err, "creating client to look up the interceptor's zone")
+		return ""
+	}
+	var node corev1.Node
+	if err := cl.Get(ctx, client.ObjectKey{Name: servingCfg.NodeName}, &node); err != nil {
+		setupLog.Error(err, "looking up the interceptor's zone, not preferring same-zone endpoints", "node", servingCfg.NodeName)
+		return ""
+	}
+
+	zone := node.Labels[corev1.LabelTopologyZone]
+	setupLog.Info("preferring same-zone endpoints", "zone", zone)
+	return zone
+}
+
 func runAdminServer(
 	ctx context.Context,
 	lggr logr.Logger,
 	port int,
 	q queue.Counter,
+	routingTable routing.Table,
+	circuits *circuitbreaker.Registry,
 	probeHandler *handler.Probe,
 ) error {
 	lggr = lggr.WithName("runAdminServer")
 
-	adminHandler := BuildAdminHandler(lggr, q, probeHandler)
+	adminHandler := BuildAdminHandler(lggr, q, routingTable, circuits, probeHandler)
 
 	addr := fmt.Sprintf("0.0.0.0:%d", port)
 	lggr.Info("admin server starting", "address", addr)
@@ -359,6 +513,7 @@ func runProxyServer(
 	tlsCfg *tls.Config,
 	tracingConfig config.Tracing,
 	instruments *metrics.Instruments,
+	circuits *circuitbreaker.Registry,
 	draining *atomic.Bool,
 ) error {
 	// Build handler chain using the shared builder
@@ -373,6 +528,7 @@ func runProxyServer(
 		TLSConfig:    tlsCfg,
 		Tracing:      tracingConfig,
 		Instruments:  instruments,
+		Circuits:     circuits,
 	})
 
 	addr := fmt.Sprintf("0.0.0.0:%d", port)
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+
+	"github.com/kedacore/http-add-on/operator/apis/http/v1alpha1"
+	"github.com/kedacore/http-add-on/operator/apis/http/v1beta1"
+	kedacache "github.com/kedacore/http-add-on/pkg/cache"
+	"github.com/kedacore/http-add-on/pkg/queue"
+	"github.com/kedacore/http-add-on/pkg/routing"
+)
+
+func TestWatchRouteSources(t *testing.T) {
+	tests := map[string]struct {
+		kinds   []string
+		wantErr bool
+	}{
+		"all CRDs installed": {
+			kinds: []string{"InterceptorRoute", "HostClaim"},
+		},
+		"without HostClaim CRD": {
+			kinds: []string{"InterceptorRoute"},
+		},
+		"without InterceptorRoute CRD": {
+			kinds:   []string{"HostClaim"},
+			wantErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Only the kinds of installed CRDs are known to the REST mapper.
+			mapper := meta.NewDefaultRESTMapper(nil)
+			mapper.Add(v1alpha1.SchemeGroupVersion.WithKind("HTTPScaledObject"), meta.RESTScopeNamespace)
+			for _, kind := range tt.kinds {
+				mapper.Add(v1beta1.SchemeGroupVersion.WithKind(kind), meta.RESTScopeNamespace)
+			}
+			ctrlCache, err := cache.New(&rest.Config{Host: "http://127.0.0.1:1"}, cache.Options{
+				Scheme: kedacache.NewScheme(),
+				Mapper: mapper,
+			})
+			if err != nil {
+				t.Fatalf("creating cache: %v", err)
+			}
+
+			routingTable := routing.NewTable(ctrlCache, queue.NewMemory(), routing.TableOptions{})
+			err = watchRouteSources(t.Context(), ctrlCache, routingTable, routing.TableOptions{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("watchRouteSources() error = %v, want error %t", err, tt.wantErr)
+			}
+		})
+	}
+}
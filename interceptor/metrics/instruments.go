@@ -18,11 +18,15 @@ const (
 	MetricRequestConcurrency = "interceptor.request.concurrency"
 	MetricRequestCount       = "interceptor.request.count"
 	MetricRequestDuration    = "interceptor.request.duration"
+	MetricEndpointEjections  = "interceptor.endpoint.ejections"
+	MetricCircuitState       = "interceptor.circuit.state"
 
 	AttrCode           = "code"
 	AttrMethod         = "method"
 	AttrRouteName      = "route_name"
 	AttrRouteNamespace = "route_namespace"
+	AttrService        = "service"
+	AttrNamespace      = "namespace"
 
 	// MethodOther is the normalized value for non-standard HTTP methods,
 	// following the OTel semantic convention prefix for synthetic values.
@@ -48,6 +52,8 @@ type Instruments struct {
 	pendingRequests api.Int64UpDownCounter
 	requestCounter  api.Int64Counter
 	requestDuration api.Float64Histogram
+	ejections       api.Int64Counter
+	circuitState    api.Int64Gauge
 }
 
 // NewNoopInstruments returns Instruments backed by a no-op provider, for use in tests.
@@ -92,10 +98,28 @@ func NewInstruments(provider *sdkmetric.MeterProvider) (*Instruments, error) {
 		return nil, fmt.Errorf("creating pending requests counter: %w", err)
 	}
 
+	ejections, err := meter.Int64Counter(
+		MetricEndpointEjections,
+		api.WithDescription("Endpoints ejected from load balancing by outlier detection"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating endpoint ejections counter: %w", err)
+	}
+
+	circuitState, err := meter.Int64Gauge(
+		MetricCircuitState,
+		api.WithDescription("State of the circuit breaker of a backend service: 0 closed, 1 open, 2 half-open"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating circuit state gauge: %w", err)
+	}
+
 	return &Instruments{
 		requestCounter:  requestCounter,
 		requestDuration: requestDuration,
 		pendingRequests: pendingRequests,
+		ejections:       ejections,
+		circuitState:    circuitState,
 	}, nil
 }
 
@@ -126,3 +150,21 @@ func (i *Instruments) RecordPendingRequest(routeName, routeNamespace string, del
 	))
 	i.pendingRequests.Add(context.Background(), delta, attrs)
 }
+
+// RecordEjection counts an endpoint of a service ejected by outlier detection.
+func (i *Instruments) RecordEjection(namespace, service string) {
+	attrs := api.WithAttributeSet(attribute.NewSet(
+		attribute.String(AttrNamespace, namespace),
+		attribute.String(AttrService, service),
+	))
+	i.ejections.Add(context.Background(), 1, attrs)
+}
+
+// RecordCircuitState records the state of the circuit breaker of a service.
+func (i *Instruments) RecordCircuitState(namespace, service string, state int64) {
+	attrs := api.WithAttributeSet(attribute.NewSet(
+		attribute.String(AttrNamespace, namespace),
+		attribute.String(AttrService, service),
+	))
+	i.circuitState.Record(context.Background(), state, attrs)
+}
@@ -125,3 +125,34 @@ func TestPrometheus_ConcurrencyMetrics(t *testing.T) {
 		t.Fatalf("unexpected metrics output:\n%v", err)
 	}
 }
+
+func TestPrometheus_EjectionMetrics(t *testing.T) {
+	registry, instruments := testRegistry(t)
+
+	instruments.RecordEjection("my-ns", "my-svc")
+	instruments.RecordEjection("my-ns", "my-svc")
+
+	expected := `
+		# HELP interceptor_endpoint_ejections_total Endpoints ejected from load balancing by outlier detection
+		# TYPE interceptor_endpoint_ejections_total counter
+		interceptor_endpoint_ejections_total{namespace="my-ns",service="my-svc"} 2
+	`
+	if err := testutil.CollectAndCompare(registry, strings.NewReader(expected), "interceptor_endpoint_ejections_total"); err != nil {
+		t.Fatalf("unexpected metrics output:\n%v", err)
+	}
+}
+
+func TestPrometheus_CircuitStateMetrics(t *testing.T) {
+	registry, instruments := testRegistry(t)
+
+	instruments.RecordCircuitState("my-ns", "my-svc", 1)
+
+	expected := `
+		# HELP interceptor_circuit_state State of the circuit breaker of a backend service: 0 closed, 1 open, 2 half-open
+		# TYPE interceptor_circuit_state gauge
+		interceptor_circuit_state{namespace="my-ns",service="my-svc"} 1
+	`
+	if err := testutil.CollectAndCompare(registry, strings.NewReader(expected), "interceptor_circuit_state"); err != nil {
+		t.Fatalf("unexpected metrics output:\n%v", err)
+	}
+}
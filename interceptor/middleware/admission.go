@@ -0,0 +1,327 @@
+package middleware
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kedacore/http-add-on/interceptor/handler"
+	httpv1beta1 "github.com/kedacore/http-add-on/operator/apis/http/v1beta1"
+	"github.com/kedacore/http-add-on/pkg/k8s"
+	"github.com/kedacore/http-add-on/pkg/util"
+)
+
+const (
+	defaultAdmissionRejectStatusCode = http.StatusServiceUnavailable
+	defaultAdmissionRetryAfter       = time.Second
+)
+
+// Admission bounds the pending requests of routes with an admission spec,
+// rejecting requests beyond maxPendingRequests. A request is pending while it
+// waits for ready endpoints or for a concurrency slot. It sits before the
+// EndpointResolver so that cold starts cannot pile up an unbounded number of
+// waiting requests; a ConcurrencyLimit after the EndpointResolver hands out
+// the concurrency slots.
+type Admission struct {
+	next       http.Handler
+	readyCache *k8s.ReadyEndpointsCache
+
+	mu       sync.Mutex
+	limiters map[string]*routeLimiter
+}
+
+// NewAdmission returns a middleware enforcing the admission spec of the
+// route of each request.
+func NewAdmission(next http.Handler, readyCache *k8s.ReadyEndpointsCache) *Admission {
+	return &Admission{
+		next:       next,
+		readyCache: readyCache,
+		limiters:   make(map[string]*routeLimiter),
+	}
+}
+
+var _ http.Handler = (*Admission)(nil)
+
+func (a *Admission) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	ir := util.InterceptorRouteFromContext(ctx)
+
+	spec := ir.Spec.Admission
+	if spec == nil {
+		a.next.ServeHTTP(w, r)
+		return
+	}
+
+	key := k8s.ResourceKey(ir.Namespace, ir.Name)
+	lim := a.acquireLimiter(key)
+	defer a.releaseLimiter(key, lim)
+
+	serviceKey := ir.Namespace + "/" + util.TargetRefFromContext(ctx).Service
+	t := lim.admit(spec, a.readyCache.HasReadyEndpoints(serviceKey), requestPriority(r, spec.PriorityHeader))
+	if t == nil {
+		rejectRequest(w, r, spec)
+		return
+	}
+	defer t.done()
+
+	a.next.ServeHTTP(w, r.WithContext(context.WithValue(ctx, admissionTicketKey, t)))
+}
+
+// acquireLimiter returns the limiter of the route key, creating it if needed.
+func (a *Admission) acquireLimiter(key string) *routeLimiter {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	lim, ok := a.limiters[key]
+	if !ok {
+		lim = &routeLimiter{}
+		a.limiters[key] = lim
+	}
+	lim.refs++
+	return lim
+}
+
+// releaseLimiter drops the limiter of the route key once no request holds it,
+// so that deleted routes do not leak limiters.
+func (a *Admission) releaseLimiter(key string, lim *routeLimiter) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	lim.refs--
+	if lim.refs == 0 {
+		delete(a.limiters, key)
+	}
+}
+
+// ConcurrencyLimit waits for a concurrency slot of the request's route before
+// forwarding it, serving pending requests by priority and then in arrival
+// order. It sits after the EndpointResolver so that requests waiting for a
+// cold start do not hold a slot. Requests not admitted by an Admission
+// middleware are forwarded immediately.
+type ConcurrencyLimit struct {
+	next http.Handler
+}
+
+// NewConcurrencyLimit returns a middleware enforcing the
+// maxConcurrentRequests of the route of each request.
+func NewConcurrencyLimit(next http.Handler) *ConcurrencyLimit {
+	return &ConcurrencyLimit{next: next}
+}
+
+var _ http.Handler = (*ConcurrencyLimit)(nil)
+
+func (cl *ConcurrencyLimit) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	t, _ := r.Context().Value(admissionTicketKey).(*admissionTicket)
+	if t != nil {
+		if err := t.wait(r.Context()); err != nil {
+			handler.
+				NewStatic(http.StatusGatewayTimeout, fmt.Errorf("waiting for a concurrency slot: %w", err)).
+				ServeHTTP(w, r)
+			return
+		}
+	}
+
+	cl.next.ServeHTTP(w, r)
+}
+
+// routeLimiter tracks the pending and concurrent requests of a route.
+type routeLimiter struct {
+	// refs is the number of requests holding the limiter, guarded by
+	// Admission.mu.
+	refs int
+
+	mu            sync.Mutex
+	maxConcurrent int // negative for unlimited
+	pending       int
+	active        int
+	waiters       waiterQueue
+	seq           uint64
+}
+
+// admissionTicket is the admission state of a single request.
+type admissionTicket struct {
+	lim      *routeLimiter
+	priority int
+
+	// Guarded by lim.mu.
+	pending bool
+	active  bool
+}
+
+// admit admits a request, reserving a concurrency slot right away if the
+// backend is ready and a slot is free. Returns nil if the request would
+// exceed maxPendingRequests.
+func (l *routeLimiter) admit(spec *httpv1beta1.AdmissionSpec, ready bool, priority int) *admissionTicket {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.maxConcurrent = -1
+	if spec.MaxConcurrentRequests != nil {
+		l.maxConcurrent = int(*spec.MaxConcurrentRequests)
+	}
+
+	t := &admissionTicket{lim: l, priority: priority}
+	if ready && l.waiters.Len() == 0 && l.hasFreeSlot() {
+		l.active++
+		t.active = true
+		return t
+	}
+
+	if spec.MaxPendingRequests != nil && l.pending >= int(*spec.MaxPendingRequests) {
+		return nil
+	}
+	l.pending++
+	t.pending = true
+	return t
+}
+
+func (l *routeLimiter) hasFreeSlot() bool {
+	return l.maxConcurrent < 0 || l.active < l.maxConcurrent
+}
+
+// grant hands free slots to the waiters by priority. Must be called with
+// l.mu held.
+func (l *routeLimiter) grant() {
+	for l.waiters.Len() > 0 && l.hasFreeSlot() {
+		w := heap.Pop(&l.waiters).(*waiter)
+		w.ticket.activate()
+		close(w.ready)
+	}
+}
+
+// wait blocks until t holds a concurrency slot or ctx is done.
+func (t *admissionTicket) wait(ctx context.Context) error {
+	l := t.lim
+	l.mu.Lock()
+	if t.active {
+		l.mu.Unlock()
+		return nil
+	}
+	if l.waiters.Len() == 0 && l.hasFreeSlot() {
+		t.activate()
+		l.mu.Unlock()
+		return nil
+	}
+
+	w := &waiter{ticket: t, seq: l.seq, ready: make(chan struct{})}
+	l.seq++
+	heap.Push(&l.waiters, w)
+	l.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if t.active {
+			// Granted concurrently with the cancellation, done releases the slot.
+			return ctx.Err()
+		}
+		heap.Remove(&l.waiters, w.index)
+		return ctx.Err()
+	}
+}
+
+// activate moves t from pending to holding a slot. Must be called with
+// t.lim.mu held.
+func (t *admissionTicket) activate() {
+	if t.pending {
+		t.lim.pending--
+		t.pending = false
+	}
+	t.lim.active++
+	t.active = true
+}
+
+// done releases the pending place or concurrency slot of t.
+func (t *admissionTicket) done() {
+	l := t.lim
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if t.pending {
+		l.pending--
+		t.pending = false
+	}
+	if t.active {
+		l.active--
+		t.active = false
+		l.grant()
+	}
+}
+
+// requestPriority returns the integer priority in the header name of r, or
+// 0 if it is unset or invalid.
+func requestPriority(r *http.Request, name string) int {
+	if name == "" {
+		return 0
+	}
+	p, err := strconv.Atoi(r.Header.Get(name))
+	if err != nil {
+		return 0
+	}
+	return p
+}
+
+// rejectRequest rejects a request exceeding the pending limit of spec.
+func rejectRequest(w http.ResponseWriter, r *http.Request, spec *httpv1beta1.AdmissionSpec) {
+	code := int(spec.RejectStatusCode)
+	if code == 0 {
+		code = defaultAdmissionRejectStatusCode
+	}
+	retryAfter := spec.RetryAfter.Duration
+	if retryAfter <= 0 {
+		retryAfter = defaultAdmissionRetryAfter
+	}
+
+	util.LoggerFromContext(r.Context()).V(1).Info("request rejected, too many pending requests", "statusCode", code)
+
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	http.Error(w, http.StatusText(code), code)
+}
+
+// waiter is a request waiting for a concurrency slot.
+type waiter struct {
+	ticket *admissionTicket
+	seq    uint64
+	ready  chan struct{}
+	index  int
+}
+
+// waiterQueue is a heap of waiters ordered by priority, then arrival.
+type waiterQueue []*waiter
+
+func (q waiterQueue) Len() int { return len(q) }
+
+func (q waiterQueue) Less(i, j int) bool {
+	if q[i].ticket.priority != q[j].ticket.priority {
+		return q[i].ticket.priority > q[j].ticket.priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q waiterQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *waiterQueue) Push(x any) {
+	w := x.(*waiter)
+	w.index = len(*q)
+	*q = append(*q, w)
+}
+
+func (q *waiterQueue) Pop() any {
+	old := *q
+	w := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	return w
+}
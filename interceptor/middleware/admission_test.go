@@ -0,0 +1,230 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	httpv1beta1 "github.com/kedacore/http-add-on/operator/apis/http/v1beta1"
+	"github.com/kedacore/http-add-on/pkg/k8s"
+)
+
+func admissionIR(spec *httpv1beta1.AdmissionSpec) *httpv1beta1.InterceptorRoute {
+	return &httpv1beta1.InterceptorRoute{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "route"},
+		Spec: httpv1beta1.InterceptorRouteSpec{
+			Target:    httpv1beta1.TargetRef{Service: testService},
+			Admission: spec,
+		},
+	}
+}
+
+// blockingHandler records the order of the requests it serves, identified by
+// their X-Id header, and blocks each until release is closed.
+type blockingHandler struct {
+	started chan string
+	release chan struct{}
+}
+
+func newBlockingHandler() *blockingHandler {
+	return &blockingHandler{started: make(chan string, 10), release: make(chan struct{})}
+}
+
+func (b *blockingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.started <- r.Header.Get("X-Id")
+	<-b.release
+	w.WriteHeader(http.StatusOK)
+}
+
+func (b *blockingHandler) waitStarted(t *testing.T) string {
+	t.Helper()
+	select {
+	case id := <-b.started:
+		return id
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a request to be forwarded")
+		return ""
+	}
+}
+
+func serveAsync(h http.Handler, req *http.Request) <-chan *httptest.ResponseRecorder {
+	done := make(chan *httptest.ResponseRecorder, 1)
+	go func() {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		done <- rec
+	}()
+	return done
+}
+
+func TestAdmission_Disabled(t *testing.T) {
+	cache := k8s.NewReadyEndpointsCache(logr.Discard())
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+	mw := NewAdmission(NewConcurrencyLimit(next), cache)
+
+	rec := httptest.NewRecorder()
+	mw.ServeHTTP(rec, newPlaceholderRequestWithPath(t, admissionIR(nil), "/"))
+
+	if got, want := rec.Code, http.StatusOK; got != want {
+		t.Fatalf("status code = %d, want %d", got, want)
+	}
+}
+
+func TestAdmission_RejectsBeyondMaxPending(t *testing.T) {
+	tests := map[string]struct {
+		spec           httpv1beta1.AdmissionSpec
+		wantCode       int
+		wantRetryAfter string
+	}{
+		"defaults": {
+			spec:           httpv1beta1.AdmissionSpec{MaxPendingRequests: ptr.To[int32](1)},
+			wantCode:       http.StatusServiceUnavailable,
+			wantRetryAfter: "1",
+		},
+		"configured": {
+			spec: httpv1beta1.AdmissionSpec{
+				MaxPendingRequests: ptr.To[int32](1),
+				RejectStatusCode:   http.StatusTooManyRequests,
+				RetryAfter:         metav1.Duration{Duration: 2500 * time.Millisecond},
+			},
+			wantCode:       http.StatusTooManyRequests,
+			wantRetryAfter: "3",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// No ready endpoints: requests stay pending while next, standing
+			// in for the EndpointResolver, waits for the cold start.
+			cache := k8s.NewReadyEndpointsCache(logr.Discard())
+			next := newBlockingHandler()
+			mw := NewAdmission(next, cache)
+			ir := admissionIR(&tt.spec)
+
+			first := serveAsync(mw, newPlaceholderRequestWithPath(t, ir, "/"))
+			next.waitStarted(t)
+
+			rec := httptest.NewRecorder()
+			mw.ServeHTTP(rec, newPlaceholderRequestWithPath(t, ir, "/"))
+			if got := rec.Code; got != tt.wantCode {
+				t.Errorf("status code = %d, want %d", got, tt.wantCode)
+			}
+			if got := rec.Header().Get("Retry-After"); got != tt.wantRetryAfter {
+				t.Errorf("Retry-After = %q, want %q", got, tt.wantRetryAfter)
+			}
+
+			close(next.release)
+			if got := (<-first).Code; got != http.StatusOK {
+				t.Errorf("first request status code = %d, want %d", got, http.StatusOK)
+			}
+		})
+	}
+}
+
+func TestAdmission_MaxPendingZeroForwardsReadyBackend(t *testing.T) {
+	cache := k8s.NewReadyEndpointsCache(logr.Discard())
+	addReadyEndpoint(cache)
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+	mw := NewAdmission(NewConcurrencyLimit(next), cache)
+
+	rec := httptest.NewRecorder()
+	mw.ServeHTTP(rec, newPlaceholderRequestWithPath(t, admissionIR(&httpv1beta1.AdmissionSpec{MaxPendingRequests: ptr.To[int32](0)}), "/"))
+
+	if got, want := rec.Code, http.StatusOK; got != want {
+		t.Fatalf("status code = %d, want %d", got, want)
+	}
+}
+
+func TestAdmission_ConcurrencyPriority(t *testing.T) {
+	cache := k8s.NewReadyEndpointsCache(logr.Discard())
+	addReadyEndpoint(cache)
+	next := newBlockingHandler()
+	mw := NewAdmission(NewConcurrencyLimit(next), cache)
+	ir := admissionIR(&httpv1beta1.AdmissionSpec{
+		MaxConcurrentRequests: ptr.To[int32](1),
+		PriorityHeader:        "X-Priority",
+	})
+
+	newRequest := func(id, priority string) *http.Request {
+		req := newPlaceholderRequestWithPath(t, ir, "/")
+		req.Header.Set("X-Id", id)
+		if priority != "" {
+			req.Header.Set("X-Priority", priority)
+		}
+		return req
+	}
+
+	var done []<-chan *httptest.ResponseRecorder
+	done = append(done, serveAsync(mw, newRequest("first", "")))
+	if got := next.waitStarted(t); got != "first" {
+		t.Fatalf("forwarded %q, want first", got)
+	}
+
+	// Queue in order low, default, high; they must be forwarded by priority.
+	for _, req := range []*http.Request{newRequest("low", "-1"), newRequest("default", "invalid"), newRequest("high", "10")} {
+		done = append(done, serveAsync(mw, req))
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	select {
+	case id := <-next.started:
+		t.Fatalf("forwarded %q beyond maxConcurrentRequests", id)
+	default:
+	}
+
+	var order []string
+	go func() {
+		for range 4 {
+			next.release <- struct{}{}
+		}
+	}()
+	for range 3 {
+		order = append(order, next.waitStarted(t))
+	}
+	if want := []string{"high", "default", "low"}; !slices.Equal(order, want) {
+		t.Errorf("forwarding order = %v, want %v", order, want)
+	}
+
+	for _, d := range done {
+		if got := (<-d).Code; got != http.StatusOK {
+			t.Errorf("status code = %d, want %d", got, http.StatusOK)
+		}
+	}
+}
+
+func TestAdmission_WaitCanceled(t *testing.T) {
+	cache := k8s.NewReadyEndpointsCache(logr.Discard())
+	addReadyEndpoint(cache)
+	next := newBlockingHandler()
+	mw := NewAdmission(NewConcurrencyLimit(next), cache)
+	ir := admissionIR(&httpv1beta1.AdmissionSpec{MaxConcurrentRequests: ptr.To[int32](1)})
+
+	first := serveAsync(mw, newPlaceholderRequestWithPath(t, ir, "/"))
+	next.waitStarted(t)
+
+	req := newPlaceholderRequestWithPath(t, ir, "/")
+	ctx, cancel := context.WithTimeout(req.Context(), 50*time.Millisecond)
+	defer cancel()
+	rec := httptest.NewRecorder()
+	mw.ServeHTTP(rec, req.WithContext(ctx))
+	if got, want := rec.Code, http.StatusGatewayTimeout; got != want {
+		t.Errorf("status code = %d, want %d", got, want)
+	}
+
+	close(next.release)
+	<-first
+
+	// The canceled waiter must not hold a slot.
+	rec = httptest.NewRecorder()
+	mw.ServeHTTP(rec, newPlaceholderRequestWithPath(t, ir, "/"))
+	if got, want := rec.Code, http.StatusOK; got != want {
+		t.Errorf("status code after cancellation = %d, want %d", got, want)
+	}
+}
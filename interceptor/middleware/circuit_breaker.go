@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kedacore/http-add-on/interceptor/circuitbreaker"
+	"github.com/kedacore/http-add-on/interceptor/handler"
+	httpv1beta1 "github.com/kedacore/http-add-on/operator/apis/http/v1beta1"
+	"github.com/kedacore/http-add-on/pkg/k8s"
+	"github.com/kedacore/http-add-on/pkg/util"
+)
+
+var errCircuitOpen = errors.New("circuit breaker open, backend is failing")
+
+// CircuitBreaker fails fast for routes with a circuit breaker whose backend
+// Service fails too many requests, serving the route's cold-start
+// placeholder or fallback instead. It records the outcome of each forwarded
+// request. It sits after the Retry middleware so that each attempt counts,
+// and before the EndpointResolver so that requests fail fast without waiting
+// for ready endpoints.
+type CircuitBreaker struct {
+	next       http.Handler
+	upstream   http.Handler
+	readyCache *k8s.ReadyEndpointsCache
+	circuits   *circuitbreaker.Registry
+	reader     client.Reader
+	templates  placeholderTemplates
+}
+
+// NewCircuitBreaker returns a middleware enforcing the circuit breaker of
+// the route of each request. upstream is the forwarding handler used to
+// send requests to the fallbacks while a circuit is open, the ready cache
+// tells which chained fallbacks are ready, and the reader is used to resolve
+// placeholder and fallback bodies stored in ConfigMaps.
+func NewCircuitBreaker(next, upstream http.Handler, readyCache *k8s.ReadyEndpointsCache, circuits *circuitbreaker.Registry, reader client.Reader) *CircuitBreaker {
+	return &CircuitBreaker{
+		next:       next,
+		upstream:   upstream,
+		readyCache: readyCache,
+		circuits:   circuits,
+		reader:     reader,
+	}
+}
+
+var _ http.Handler = (*CircuitBreaker)(nil)
+
+func (cb *CircuitBreaker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	ir := util.InterceptorRouteFromContext(ctx)
+
+	spec := ir.Spec.CircuitBreaker
+	if spec == nil {
+		cb.next.ServeHTTP(w, r)
+		return
+	}
+
+	serviceKey := ir.Namespace + "/" + util.TargetRefFromContext(ctx).Service
+	done, retryAfter, ok := cb.circuits.Allow(serviceKey, spec)
+	if !ok {
+		util.LoggerFromContext(ctx).V(1).Info("circuit open, failing fast", "service", serviceKey)
+		cb.serveOpen(w, r, ir, retryAfter)
+		return
+	}
+
+	attempt := util.UpstreamAttemptFromContext(ctx)
+	if attempt == nil {
+		attempt = &util.UpstreamAttempt{}
+		r = r.WithContext(util.ContextWithUpstreamAttempt(ctx, attempt))
+	}
+	rw := newInstrumentedResponseWriter(w)
+	defer func() {
+		done(requestOutcome(r.Context(), attempt, rw.statusCode))
+	}()
+
+	cb.next.ServeHTTP(rw, r)
+}
+
+// serveOpen answers a request to a backend whose circuit is open.
+func (cb *CircuitBreaker) serveOpen(w http.ResponseWriter, r *http.Request, ir *httpv1beta1.InterceptorRoute, retryAfter time.Duration) {
+	if ph := placeholderOf(ir); ph != nil {
+		servePlaceholder(w, r, cb.reader, &cb.templates, ir, ph, 0, retryAfter)
+		return
+	}
+	if cs := ir.Spec.ColdStart; cs != nil {
+		if len(cs.Fallbacks) > 0 {
+			serveFallbacks(w, r, cb.upstream, cb.readyCache, cb.reader, ir, cs.Fallbacks)
+			return
+		}
+		if cs.Fallback != nil && cs.Fallback.Service != nil && util.FallbackURLFromContext(r.Context()) != nil {
+			cb.upstream.ServeHTTP(w, withFallbackUpstream(r, util.FallbackURLFromContext(r.Context())))
+			return
+		}
+	}
+
+	if retryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	}
+	handler.NewStatic(http.StatusServiceUnavailable, errCircuitOpen).ServeHTTP(w, r)
+}
+
+// requestOutcome classifies a request for the circuit breaker.
+func requestOutcome(ctx context.Context, attempt *util.UpstreamAttempt, statusCode int) circuitbreaker.Outcome {
+	switch {
+	case attempt.Host == "", errors.Is(context.Cause(ctx), context.Canceled):
+		// Not forwarded, or the client went away.
+		return circuitbreaker.Ignored
+	case attempt.Err != nil, statusCode >= http.StatusInternalServerError:
+		return circuitbreaker.Failure
+	default:
+		return circuitbreaker.Success
+	}
+}
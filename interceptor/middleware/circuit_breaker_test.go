@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	discov1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/kedacore/http-add-on/interceptor/circuitbreaker"
+	httpv1beta1 "github.com/kedacore/http-add-on/operator/apis/http/v1beta1"
+	"github.com/kedacore/http-add-on/pkg/k8s"
+	"github.com/kedacore/http-add-on/pkg/util"
+)
+
+func circuitBreakerIR(coldStart *httpv1beta1.ColdStartSpec) *httpv1beta1.InterceptorRoute {
+	ir := defaultIR()
+	ir.Spec.CircuitBreaker = &httpv1beta1.CircuitBreakerSpec{
+		ConsecutiveFailures: ptr.To[int32](2),
+		CoolDown:            metav1.Duration{Duration: 1500 * time.Millisecond},
+	}
+	ir.Spec.ColdStart = coldStart
+	return ir
+}
+
+func TestCircuitBreaker_OpenCircuit(t *testing.T) {
+	body := "warming up"
+	tests := map[string]struct {
+		coldStart      *httpv1beta1.ColdStartSpec
+		wantCode       int
+		wantBody       string
+		wantRetryAfter string
+		wantFallback   bool
+	}{
+		"rejected": {
+			wantCode:       http.StatusServiceUnavailable,
+			wantRetryAfter: "2",
+		},
+		"placeholder": {
+			coldStart: &httpv1beta1.ColdStartSpec{Placeholder: &httpv1beta1.ColdStartPlaceholder{
+				Response: &httpv1beta1.StaticResponse{StatusCode: http.StatusServiceUnavailable, Body: &body},
+			}},
+			wantCode: http.StatusServiceUnavailable,
+			wantBody: body,
+		},
+		"fallback": {
+			coldStart: &httpv1beta1.ColdStartSpec{Fallback: &httpv1beta1.ColdStartFallback{
+				Service: &httpv1beta1.ServiceRef{Name: "fallback"},
+			}},
+			wantCode:     http.StatusOK,
+			wantFallback: true,
+		},
+		"chained fallback": {
+			coldStart: &httpv1beta1.ColdStartSpec{Fallbacks: []httpv1beta1.ColdStartFallbackTarget{
+				{Service: &httpv1beta1.ServiceRef{Name: "fallback"}},
+			}},
+			wantCode:     http.StatusOK,
+			wantFallback: true,
+		},
+		"chained static response": {
+			coldStart: &httpv1beta1.ColdStartSpec{Fallbacks: []httpv1beta1.ColdStartFallbackTarget{
+				{Response: &httpv1beta1.StaticResponse{Body: &body}},
+			}},
+			wantCode: http.StatusServiceUnavailable,
+			wantBody: body,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			upstream := &fakeUpstream{respond: failFirst(100, http.StatusBadGateway)}
+			var fallbackHost string
+			fallback := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fallbackHost = util.UpstreamURLFromContext(r.Context()).Host
+				w.WriteHeader(http.StatusOK)
+			})
+			cache := k8s.NewReadyEndpointsCache(logr.Discard())
+			cache.Update(testNamespace+"/fallback", []*discov1.EndpointSlice{
+				{AddressType: discov1.AddressTypeIPv4, Endpoints: []discov1.Endpoint{{Addresses: []string{"10.0.0.1"}}}},
+			})
+			mw := NewCircuitBreaker(upstream, fallback, cache, circuitbreaker.NewRegistry(nil), nil)
+			ir := circuitBreakerIR(tt.coldStart)
+			newReq := func() *http.Request {
+				req := newRequest(t, ir)
+				fallbackURL := &url.URL{Scheme: "http", Host: "fallback"}
+				ctx := util.ContextWithFallbackURL(req.Context(), fallbackURL)
+				ctx = util.ContextWithFallbackURLs(ctx, []*url.URL{fallbackURL})
+				return req.WithContext(ctx)
+			}
+
+			for range 2 {
+				mw.ServeHTTP(httptest.NewRecorder(), newReq())
+			}
+			rec := httptest.NewRecorder()
+			mw.ServeHTTP(rec, newReq())
+
+			if got := len(upstream.hosts); got != 2 {
+				t.Errorf("forwarded %d requests, want 2 before the circuit opens", got)
+			}
+			if got := rec.Code; got != tt.wantCode {
+				t.Errorf("status code = %d, want %d", got, tt.wantCode)
+			}
+			if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tt.wantBody)
+			}
+			if got := rec.Header().Get("Retry-After"); got != tt.wantRetryAfter {
+				t.Errorf("Retry-After = %q, want %q", got, tt.wantRetryAfter)
+			}
+			if got := fallbackHost == "fallback"; got != tt.wantFallback {
+				t.Errorf("sent to fallback = %t, want %t", got, tt.wantFallback)
+			}
+		})
+	}
+}
+
+func TestCircuitBreaker_IgnoresRequestsNotForwarded(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		// Stands in for a readiness timeout of the EndpointResolver.
+		w.WriteHeader(http.StatusGatewayTimeout)
+	})
+	mw := NewCircuitBreaker(next, nil, k8s.NewReadyEndpointsCache(logr.Discard()), circuitbreaker.NewRegistry(nil), nil)
+	ir := circuitBreakerIR(nil)
+
+	for range 5 {
+		rec := httptest.NewRecorder()
+		mw.ServeHTTP(rec, newRequest(t, ir))
+		if got, want := rec.Code, http.StatusGatewayTimeout; got != want {
+			t.Fatalf("status code = %d, want %d: the circuit must stay closed", got, want)
+		}
+	}
+}
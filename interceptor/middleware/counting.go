@@ -34,6 +34,11 @@ func (cm *Counting) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ir := util.InterceptorRouteFromContext(ctx)
 
 	key := k8s.ResourceKey(ir.Namespace, ir.Name)
+	if len(ir.Spec.Backends) > 0 {
+		// Attribute weighted traffic to the selected backend so that each
+		// backend scales on its own share.
+		key = k8s.BackendResourceKey(ir.Namespace, ir.Name, util.TargetRefFromContext(ctx).Service)
+	}
 
 	if err := cm.queueCounter.Increase(key, 1); err != nil {
 		util.LoggerFromContext(ctx).Error(err, "error incrementing queue counter", "key", key)
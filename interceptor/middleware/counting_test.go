@@ -10,6 +10,7 @@ import (
 
 	"github.com/kedacore/http-add-on/interceptor/metrics"
 	httpv1beta1 "github.com/kedacore/http-add-on/operator/apis/http/v1beta1"
+	"github.com/kedacore/http-add-on/pkg/k8s"
 	"github.com/kedacore/http-add-on/pkg/queue"
 	"github.com/kedacore/http-add-on/pkg/util"
 )
@@ -55,6 +56,48 @@ func TestCounting_ConcurrencyTracking(t *testing.T) {
 	}
 }
 
+func TestCounting_WeightedBackendKey(t *testing.T) {
+	ir := &httpv1beta1.InterceptorRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-route",
+			Namespace: "test-ns",
+		},
+		Spec: httpv1beta1.InterceptorRouteSpec{
+			Backends: []httpv1beta1.WeightedBackend{
+				{TargetRef: httpv1beta1.TargetRef{Service: "stable", Port: 8080}},
+				{TargetRef: httpv1beta1.TargetRef{Service: "canary", Port: 8080}},
+			},
+		},
+	}
+	counter := queue.NewFakeCounterBuffered()
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	mw := NewCounting(next, counter, metrics.NewNoopInstruments())
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	ctx := util.ContextWithLogger(req.Context(), logr.Discard())
+	ctx = util.ContextWithInterceptorRoute(ctx, ir)
+	ctx = util.ContextWithTargetRef(ctx, &ir.Spec.Backends[1].TargetRef)
+	req = req.WithContext(ctx)
+
+	mw.ServeHTTP(httptest.NewRecorder(), req)
+
+	counts, err := counter.Current()
+	if err != nil {
+		t.Fatalf("counter.Current() error: %v", err)
+	}
+	key := k8s.BackendResourceKey("test-ns", "test-route", "canary")
+	if _, ok := counts[key]; !ok {
+		t.Fatalf("expected counter entry for %q, got %v", key, counts)
+	}
+	if got, want := len(counts), 1; got != want {
+		t.Fatalf("expected %d counter entry, got %d", want, got)
+	}
+}
+
 func currentConcurrency(t *testing.T, counter *queue.FakeCounter) int {
 	t.Helper()
 
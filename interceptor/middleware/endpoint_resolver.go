@@ -2,39 +2,62 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
 	"time"
 
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
 	"github.com/kedacore/http-add-on/interceptor/handler"
+	"github.com/kedacore/http-add-on/interceptor/loadbalancing"
+	httpv1beta1 "github.com/kedacore/http-add-on/operator/apis/http/v1beta1"
 	kedahttp "github.com/kedacore/http-add-on/pkg/http"
 	"github.com/kedacore/http-add-on/pkg/k8s"
 	"github.com/kedacore/http-add-on/pkg/util"
 )
 
-const defaultFallbackReadinessTimeout = 30 * time.Second
+const (
+	defaultFallbackReadinessTimeout = 30 * time.Second
+	// defaultChainedFallbackReadinessTimeout is the wait for a chained
+	// fallback Service without a readiness timeout.
+	defaultChainedFallbackReadinessTimeout = 5 * time.Second
+)
+
+var errFallbacksNotReady = errors.New("backend and its fallbacks not ready")
 
 type EndpointResolverConfig struct {
 	ReadinessTimeout      time.Duration
 	EnableColdStartHeader bool
 	DirectPodRouting      bool // route every request to a pod IP instead of the ClusterIP service
+	// Reader resolves the bodies of static fallback responses stored in
+	// ConfigMaps.
+	Reader client.Reader
 }
 
 type EndpointResolver struct {
 	next       http.Handler
 	readyCache *k8s.ReadyEndpointsCache
+	balancer   *loadbalancing.Balancer
 	cfg        EndpointResolverConfig
 }
 
 // NewEndpointResolver returns a middleware that resolves a ready backend
 // endpoint for each request. It waits for at least one endpoint to become
 // ready (handling cold starts) and optionally falls back to an alternate
-// upstream when the backend does not become ready in time.
+// upstream, or a chain of them, when the backend does not become ready in
+// time. With direct pod
+// routing, the pod is selected by the route's session affinity cookie and
+// load-balancing policy, and its failures are reported to the cache's outlier
+// detection.
 func NewEndpointResolver(next http.Handler, readyCache *k8s.ReadyEndpointsCache, cfg EndpointResolverConfig) *EndpointResolver {
 	return &EndpointResolver{
 		next:       next,
 		readyCache: readyCache,
+		balancer:   loadbalancing.New(),
 		cfg:        cfg,
 	}
 }
@@ -51,7 +74,8 @@ func (er *EndpointResolver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		readinessTimeout = ir.Spec.Timeouts.Readiness.Duration
 	}
 
-	hasFallback := ir.Spec.ColdStart != nil && ir.Spec.ColdStart.Fallback != nil && ir.Spec.ColdStart.Fallback.Service != nil
+	cs := ir.Spec.ColdStart
+	hasFallback := cs != nil && (cs.Fallback != nil && cs.Fallback.Service != nil || len(cs.Fallbacks) > 0)
 	// Bound the readiness wait or otherwise there is no time for the fallback
 	if hasFallback && readinessTimeout == 0 {
 		readinessTimeout = defaultFallbackReadinessTimeout
@@ -64,8 +88,26 @@ func (er *EndpointResolver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		defer cancel()
 	}
 
-	serviceKey := ir.Namespace + "/" + ir.Spec.Target.Service
-	isColdStart, podHost, err := er.readyCache.WaitForReady(waitCtx, serviceKey, util.UpstreamPortNameFromContext(ctx))
+	serviceKey := ir.Namespace + "/" + util.TargetRefFromContext(ctx).Service
+	portName := util.UpstreamPortNameFromContext(ctx)
+	var pick k8s.HostPicker
+	var affinity *loadbalancing.Affinity
+	if er.cfg.DirectPodRouting {
+		pick = er.balancer.Picker(r, ir.Spec.LoadBalancing, serviceKey+"/"+portName)
+		// Retries go to pods not tried yet.
+		tried, _ := ctx.Value(retriedHostsKey).([]string)
+		if affinity = loadbalancing.NewAffinity(r, ir.Spec.SessionAffinity); affinity != nil {
+			ready := er.readyCache.ReadyHosts(serviceKey, portName)
+			if len(tried) > 0 {
+				ready = slices.DeleteFunc(slices.Clone(ready), func(host string) bool { return slices.Contains(tried, host) })
+			}
+			pick = affinity.Picker(pick, ready)
+		}
+		if len(tried) > 0 {
+			pick = loadbalancing.Excluding(pick, tried)
+		}
+	}
+	isColdStart, podHost, err := er.readyCache.WaitForReadyWithPicker(waitCtx, serviceKey, portName, pick)
 	if err != nil {
 		// No fallback, return an error
 		if !hasFallback {
@@ -88,15 +130,13 @@ func (er *EndpointResolver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		// Fall back to alternate upstream.
-		fallbackURL := util.FallbackURLFromContext(ctx)
-		ctx = util.ContextWithUpstreamURL(ctx, fallbackURL)
-		// Swapping to the fallback URL: refresh the SNI so TLS doesn't present
-		// the primary service's hostname (HTTP ignores ServerName).
-		if fallbackURL.Scheme == "https" {
-			ctx = util.ContextWithUpstreamServerName(ctx, fallbackURL.Hostname())
+		if len(cs.Fallbacks) > 0 {
+			serveFallbacks(w, r, er.next, er.readyCache, er.cfg.Reader, ir, cs.Fallbacks)
+			return
 		}
-		r = r.WithContext(ctx)
+
+		// Fall back to alternate upstream.
+		r = withFallbackUpstream(r, util.FallbackURLFromContext(ctx))
 	} else {
 		if er.cfg.EnableColdStartHeader {
 			w.Header().Set(kedahttp.HeaderColdStart, strconv.FormatBool(isColdStart))
@@ -110,9 +150,91 @@ func (er *EndpointResolver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 				podURL.Host = podHost
 				ctx = util.ContextWithUpstreamURL(ctx, &podURL)
 				r = r.WithContext(ctx)
+
+				done := er.balancer.Track(podHost)
+				defer done()
+
+				// Hedging learns the pod of each of its requests.
+				if picked, _ := ctx.Value(podPickedKey).(func(host string)); picked != nil {
+					picked(podHost)
+				}
+
+				if affinity != nil {
+					affinity.SetCookie(w, r, podHost)
+				}
+
+				// Report connection errors and 5xx responses of the pod for
+				// outlier detection, unless the client went away.
+				rw := newInstrumentedResponseWriter(w)
+				w = rw
+				defer func() {
+					if r.Context().Err() == nil {
+						er.readyCache.ReportResult(serviceKey, podHost, rw.statusCode < http.StatusInternalServerError)
+					}
+				}()
 			}
 		}
 	}
 
 	er.next.ServeHTTP(w, r)
 }
+
+// serveFallbacks forwards r with next to the first of the chained cold-start
+// fallbacks of ir with ready endpoints within its readiness timeout, or
+// serves the static response ending the chain. The reader resolves response
+// bodies stored in ConfigMaps.
+func serveFallbacks(w http.ResponseWriter, r *http.Request, next http.Handler, readyCache *k8s.ReadyEndpointsCache, reader client.Reader, ir *httpv1beta1.InterceptorRoute, fallbacks []httpv1beta1.ColdStartFallbackTarget) {
+	ctx := r.Context()
+	logger := util.LoggerFromContext(ctx)
+	fallbackURLs := util.FallbackURLsFromContext(ctx)
+
+	for i, fb := range fallbacks {
+		if fb.Response != nil {
+			serveStaticResponse(w, r, reader, ir, fb.Response, http.StatusServiceUnavailable)
+			return
+		}
+		if fb.Service == nil || i >= len(fallbackURLs) || fallbackURLs[i] == nil {
+			continue
+		}
+
+		timeout := defaultChainedFallbackReadinessTimeout
+		if fb.ReadinessTimeout != nil {
+			timeout = fb.ReadinessTimeout.Duration
+		}
+		if timeout > 0 {
+			waitCtx, cancel := context.WithTimeout(ctx, timeout)
+			_, _, err := readyCache.WaitForReady(waitCtx, ir.Namespace+"/"+fb.Service.Name, fb.Service.PortName)
+			cancel()
+			if err != nil {
+				if ctx.Err() != nil {
+					handler.
+						NewStatic(http.StatusGatewayTimeout, fmt.Errorf("fallback %s not ready and no time remaining: %w", fb.Service.Name, err)).
+						ServeHTTP(w, r)
+					return
+				}
+				logger.V(1).Info("fallback not ready, trying the next one", "service", fb.Service.Name)
+				continue
+			}
+		}
+
+		next.ServeHTTP(w, withFallbackUpstream(r, fallbackURLs[i]))
+		return
+	}
+
+	handler.
+		NewStatic(http.StatusGatewayTimeout, errFallbacksNotReady).
+		ServeHTTP(w, r)
+}
+
+// withFallbackUpstream returns r forwarded to the cold-start fallback at
+// fallbackURL.
+func withFallbackUpstream(r *http.Request, fallbackURL *url.URL) *http.Request {
+	ctx := r.Context()
+	ctx = util.ContextWithUpstreamURL(ctx, fallbackURL)
+	// Swapping to the fallback URL: refresh the SNI so TLS doesn't present
+	// the primary service's hostname (HTTP ignores ServerName).
+	if fallbackURL.Scheme == "https" {
+		ctx = util.ContextWithUpstreamServerName(ctx, fallbackURL.Hostname())
+	}
+	return r.WithContext(ctx)
+}
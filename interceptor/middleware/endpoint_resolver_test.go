@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"slices"
 	"testing"
 	"testing/synctest"
 	"time"
@@ -12,7 +13,9 @@ import (
 	"github.com/go-logr/logr"
 	discov1 "k8s.io/api/discovery/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 
+	"github.com/kedacore/http-add-on/interceptor/loadbalancing"
 	httpv1beta1 "github.com/kedacore/http-add-on/operator/apis/http/v1beta1"
 	kedahttp "github.com/kedacore/http-add-on/pkg/http"
 	"github.com/kedacore/http-add-on/pkg/k8s"
@@ -133,6 +136,118 @@ func TestEndpointResolver_Fallback(t *testing.T) {
 	}
 }
 
+func TestEndpointResolver_ChainedFallbacks(t *testing.T) {
+	body := "down for maintenance"
+	regional := httpv1beta1.ColdStartFallbackTarget{
+		Service:          &httpv1beta1.ServiceRef{Name: "regional"},
+		ReadinessTimeout: &metav1.Duration{Duration: 5 * time.Second},
+	}
+	maintenance := httpv1beta1.ColdStartFallbackTarget{
+		Response: &httpv1beta1.StaticResponse{Body: &body},
+	}
+
+	tests := map[string]struct {
+		fallbacks     []httpv1beta1.ColdStartFallbackTarget
+		regionalReady time.Duration // 0: never
+		wantCode      int
+		wantUpstream  string
+		wantBody      string
+		wantElapsed   time.Duration
+	}{
+		"regional replica ready": {
+			fallbacks:     []httpv1beta1.ColdStartFallbackTarget{regional, maintenance},
+			regionalReady: time.Second,
+			wantCode:      http.StatusOK,
+			wantUpstream:  "regional",
+			wantElapsed:   6 * time.Second,
+		},
+		"regional replica not ready": {
+			fallbacks:   []httpv1beta1.ColdStartFallbackTarget{regional, maintenance},
+			wantCode:    http.StatusServiceUnavailable,
+			wantBody:    body,
+			wantElapsed: 10 * time.Second,
+		},
+		"fallback without readiness timeout": {
+			fallbacks:   []httpv1beta1.ColdStartFallbackTarget{{Service: &httpv1beta1.ServiceRef{Name: "regional"}}, maintenance},
+			wantCode:    http.StatusServiceUnavailable,
+			wantBody:    body,
+			wantElapsed: 5*time.Second + defaultChainedFallbackReadinessTimeout,
+		},
+		"fallback without readiness timeout ready": {
+			fallbacks:     []httpv1beta1.ColdStartFallbackTarget{{Service: &httpv1beta1.ServiceRef{Name: "regional"}}, maintenance},
+			regionalReady: time.Second,
+			wantCode:      http.StatusOK,
+			wantUpstream:  "regional",
+			wantElapsed:   6 * time.Second,
+		},
+		"fallback with zero readiness timeout": {
+			fallbacks: []httpv1beta1.ColdStartFallbackTarget{
+				{Service: &httpv1beta1.ServiceRef{Name: "regional"}, ReadinessTimeout: &metav1.Duration{}},
+				maintenance,
+			},
+			wantCode:     http.StatusOK,
+			wantUpstream: "regional",
+			wantElapsed:  5 * time.Second,
+		},
+		"no static response": {
+			fallbacks:   []httpv1beta1.ColdStartFallbackTarget{regional},
+			wantCode:    http.StatusGatewayTimeout,
+			wantElapsed: 10 * time.Second,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			synctest.Test(t, func(t *testing.T) {
+				cache := k8s.NewReadyEndpointsCache(logr.Discard())
+				if tt.regionalReady > 0 {
+					time.AfterFunc(5*time.Second+tt.regionalReady, func() {
+						cache.Update(testNamespace+"/regional", []*discov1.EndpointSlice{
+							{AddressType: discov1.AddressTypeIPv4, Endpoints: []discov1.Endpoint{{Addresses: []string{"10.0.0.1"}}}},
+						})
+					})
+				}
+
+				var gotUpstream string
+				next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					gotUpstream = util.UpstreamURLFromContext(r.Context()).Host
+					w.WriteHeader(http.StatusOK)
+				})
+
+				ir := defaultIR()
+				ir.Spec.ColdStart = &httpv1beta1.ColdStartSpec{Fallbacks: tt.fallbacks}
+				mw := NewEndpointResolver(next, cache, EndpointResolverConfig{ReadinessTimeout: 5 * time.Second})
+
+				req := newRequest(t, ir)
+				fallbackURLs := make([]*url.URL, len(tt.fallbacks))
+				for i, fb := range tt.fallbacks {
+					if fb.Service != nil {
+						fallbackURLs[i] = &url.URL{Host: fb.Service.Name}
+					}
+				}
+				req = req.WithContext(util.ContextWithFallbackURLs(req.Context(), fallbackURLs))
+
+				start := time.Now()
+				rec := httptest.NewRecorder()
+				mw.ServeHTTP(rec, req)
+
+				if got := rec.Code; got != tt.wantCode {
+					t.Fatalf("status code = %d, want %d", got, tt.wantCode)
+				}
+				if gotUpstream != tt.wantUpstream {
+					t.Errorf("upstream = %q, want %q", gotUpstream, tt.wantUpstream)
+				}
+				if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+					t.Errorf("body = %q, want %q", rec.Body.String(), tt.wantBody)
+				}
+				if got := time.Since(start); got != tt.wantElapsed {
+					t.Errorf("served after %s, want %s", got, tt.wantElapsed)
+				}
+			})
+		})
+	}
+}
+
 // TestEndpointResolver_DirectPodRouting_DoesNotOverwriteFallback verifies that
 // when the backend never becomes ready and we fall back to the alternate upstream,
 // the direct-to-pod rewrite does not overwrite the fallback URL even when
@@ -1014,3 +1129,199 @@ func TestEndpointResolver_DirectPodRouting_UsedOnWarmPath(t *testing.T) {
 		t.Fatalf("upstream host = %q, want pod IP (should be rewritten on warm path too)", capturedUpstream.Host)
 	}
 }
+
+func TestEndpointResolver_DirectPodRouting_LoadBalancing(t *testing.T) {
+	port := int32(8080)
+	cache := k8s.NewReadyEndpointsCache(logr.Discard())
+	cache.Update(testNamespace+"/"+testService, []*discov1.EndpointSlice{{
+		AddressType: discov1.AddressTypeIPv4,
+		Ports:       []discov1.EndpointPort{{Port: &port}},
+		Endpoints: []discov1.Endpoint{
+			{Addresses: []string{"10.0.0.2"}},
+			{Addresses: []string{"10.0.0.1"}},
+		},
+	}})
+
+	var hosts []string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hosts = append(hosts, util.UpstreamURLFromContext(r.Context()).Host)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	mw := NewEndpointResolver(next, cache, EndpointResolverConfig{DirectPodRouting: true})
+
+	ir := defaultIR()
+	ir.Spec.LoadBalancing = &httpv1beta1.LoadBalancingSpec{Policy: httpv1beta1.LoadBalancingRoundRobin}
+	for range 3 {
+		mw.ServeHTTP(httptest.NewRecorder(), newRequest(t, ir))
+	}
+
+	if want := []string{"10.0.0.1:8080", "10.0.0.2:8080", "10.0.0.1:8080"}; !slices.Equal(hosts, want) {
+		t.Errorf("upstream hosts = %v, want %v", hosts, want)
+	}
+}
+
+func TestEndpointResolver_DirectPodRouting_SessionAffinity(t *testing.T) {
+	port := int32(8080)
+	cache := k8s.NewReadyEndpointsCache(logr.Discard())
+	setPods := func(ips ...string) {
+		eps := make([]discov1.Endpoint, 0, len(ips))
+		for _, ip := range ips {
+			eps = append(eps, discov1.Endpoint{Addresses: []string{ip}})
+		}
+		cache.Update(testNamespace+"/"+testService, []*discov1.EndpointSlice{{
+			AddressType: discov1.AddressTypeIPv4,
+			Ports:       []discov1.EndpointPort{{Port: &port}},
+			Endpoints:   eps,
+		}})
+	}
+	setPods("10.0.0.1", "10.0.0.2", "10.0.0.3")
+
+	var host string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host = util.UpstreamURLFromContext(r.Context()).Host
+		w.WriteHeader(http.StatusOK)
+	})
+	mw := NewEndpointResolver(next, cache, EndpointResolverConfig{DirectPodRouting: true})
+
+	ir := defaultIR()
+	ir.Spec.LoadBalancing = &httpv1beta1.LoadBalancingSpec{Policy: httpv1beta1.LoadBalancingRoundRobin}
+	ir.Spec.SessionAffinity = &httpv1beta1.SessionAffinitySpec{CookieName: "sticky"}
+
+	rec := httptest.NewRecorder()
+	mw.ServeHTTP(rec, newRequest(t, ir))
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != "sticky" {
+		t.Fatalf("cookies = %+v, want one sticky cookie", cookies)
+	}
+	pinned := host
+
+	// Round-robin would move on, the cookie keeps the pod.
+	for range 3 {
+		req := newRequest(t, ir)
+		req.AddCookie(cookies[0])
+		rec = httptest.NewRecorder()
+		mw.ServeHTTP(rec, req)
+		if host != pinned {
+			t.Fatalf("upstream host = %s, want pinned %s", host, pinned)
+		}
+		if got := rec.Header().Get("Set-Cookie"); got != "" {
+			t.Fatalf("Set-Cookie = %q, want none", got)
+		}
+	}
+
+	// The pinned pod disappears: the request moves and gets a new cookie.
+	var others []string
+	for _, ip := range []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"} {
+		if ip+":8080" != pinned {
+			others = append(others, ip)
+		}
+	}
+	setPods(others...)
+	req := newRequest(t, ir)
+	req.AddCookie(cookies[0])
+	rec = httptest.NewRecorder()
+	mw.ServeHTTP(rec, req)
+	if host == pinned {
+		t.Fatalf("upstream host = %s, want another pod", host)
+	}
+	if got := rec.Result().Cookies(); len(got) != 1 || got[0].Value == cookies[0].Value {
+		t.Errorf("cookies = %+v, want a new sticky cookie", got)
+	}
+}
+
+func TestEndpointResolver_DirectPodRouting_SessionAffinityAcrossZones(t *testing.T) {
+	port := int32(8080)
+	cache := k8s.NewReadyEndpointsCache(logr.Discard())
+	cache.SetLocalZone("zone-a")
+	cache.Update(testNamespace+"/"+testService, []*discov1.EndpointSlice{{
+		AddressType: discov1.AddressTypeIPv4,
+		Ports:       []discov1.EndpointPort{{Port: &port}},
+		Endpoints: []discov1.Endpoint{
+			{Addresses: []string{"10.0.0.1"}, Zone: ptr.To("zone-a")},
+			{Addresses: []string{"10.0.0.2"}, Zone: ptr.To("zone-b")},
+		},
+	}})
+
+	var host string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host = util.UpstreamURLFromContext(r.Context()).Host
+		w.WriteHeader(http.StatusOK)
+	})
+	mw := NewEndpointResolver(next, cache, EndpointResolverConfig{DirectPodRouting: true})
+
+	ir := defaultIR()
+	ir.Spec.SessionAffinity = &httpv1beta1.SessionAffinitySpec{CookieName: "sticky"}
+
+	// A cookie pinning the pod of the other zone, e.g. set by an
+	// interceptor in that zone.
+	rec := httptest.NewRecorder()
+	loadbalancing.NewAffinity(newRequest(t, ir), ir.Spec.SessionAffinity).SetCookie(rec, newRequest(t, ir), "10.0.0.2:8080")
+	cookie := rec.Result().Cookies()[0]
+
+	for range 3 {
+		req := newRequest(t, ir)
+		req.AddCookie(cookie)
+		rec = httptest.NewRecorder()
+		mw.ServeHTTP(rec, req)
+		if want := "10.0.0.2:8080"; host != want {
+			t.Fatalf("upstream host = %s, want pinned %s of the other zone", host, want)
+		}
+		if got := rec.Header().Get("Set-Cookie"); got != "" {
+			t.Fatalf("Set-Cookie = %q, want none", got)
+		}
+	}
+
+	// Without a cookie, the local zone is preferred.
+	rec = httptest.NewRecorder()
+	mw.ServeHTTP(rec, newRequest(t, ir))
+	if want := "10.0.0.1:8080"; host != want {
+		t.Errorf("upstream host = %s, want %s of the local zone", host, want)
+	}
+}
+
+func TestEndpointResolver_DirectPodRouting_EjectsFailingPod(t *testing.T) {
+	port := int32(8080)
+	cache := k8s.NewReadyEndpointsCache(logr.Discard())
+	var ejected []string
+	cache.SetOutlierDetection(k8s.OutlierDetection{
+		ConsecutiveFailures: 2,
+		BaseEjectionTime:    time.Minute,
+		MaxEjectionPercent:  50,
+		OnEject:             func(_, host string) { ejected = append(ejected, host) },
+	})
+	cache.Update(testNamespace+"/"+testService, []*discov1.EndpointSlice{{
+		AddressType: discov1.AddressTypeIPv4,
+		Ports:       []discov1.EndpointPort{{Port: &port}},
+		Endpoints: []discov1.Endpoint{
+			{Addresses: []string{"10.0.0.1"}},
+			{Addresses: []string{"10.0.0.2"}},
+		},
+	}})
+
+	var hosts []string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := util.UpstreamURLFromContext(r.Context()).Host
+		hosts = append(hosts, host)
+		if host == "10.0.0.1:8080" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	mw := NewEndpointResolver(next, cache, EndpointResolverConfig{DirectPodRouting: true})
+
+	ir := defaultIR()
+	ir.Spec.LoadBalancing = &httpv1beta1.LoadBalancingSpec{Policy: httpv1beta1.LoadBalancingRoundRobin}
+	for range 6 {
+		mw.ServeHTTP(httptest.NewRecorder(), newRequest(t, ir))
+	}
+
+	want := []string{"10.0.0.1:8080", "10.0.0.2:8080", "10.0.0.1:8080", "10.0.0.2:8080", "10.0.0.2:8080", "10.0.0.2:8080"}
+	if !slices.Equal(hosts, want) {
+		t.Errorf("upstream hosts = %v, want %v", hosts, want)
+	}
+	if want := []string{"10.0.0.1:8080"}; !slices.Equal(ejected, want) {
+		t.Errorf("ejected = %v, want %v", ejected, want)
+	}
+}
@@ -0,0 +1,385 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	httpv1beta1 "github.com/kedacore/http-add-on/operator/apis/http/v1beta1"
+	"github.com/kedacore/http-add-on/pkg/k8s"
+	"github.com/kedacore/http-add-on/pkg/util"
+)
+
+const (
+	// hedgingWindow is the number of recent response header times of a route
+	// kept for percentile delays.
+	hedgingWindow = 200
+	// minHedgingSamples is the number of response header times of a route
+	// needed before a percentile delay applies.
+	minHedgingSamples = 20
+)
+
+var errHedgeLost = fmt.Errorf("other hedged request answered first: %w", context.Canceled)
+
+// Hedging sends a second request to another ready pod for routes with hedging
+// enabled when the response headers of the first are late, and returns the
+// response whose headers arrive first, cancelling the other. It sits after
+// the Counting middleware so that both requests count as one, and before the
+// EndpointResolver so that each request selects its pod.
+type Hedging struct {
+	next             http.Handler
+	readyCache       *k8s.ReadyEndpointsCache
+	directPodRouting bool
+
+	// "namespace/name" -> *latencyWindow
+	latencies sync.Map
+}
+
+// NewHedging returns a middleware enforcing the hedging policy of the route
+// of each request. Hedging needs direct pod routing to send the requests to
+// different pods, and is disabled without it.
+func NewHedging(next http.Handler, readyCache *k8s.ReadyEndpointsCache, directPodRouting bool) *Hedging {
+	return &Hedging{
+		next:             next,
+		readyCache:       readyCache,
+		directPodRouting: directPodRouting,
+	}
+}
+
+var _ http.Handler = (*Hedging)(nil)
+
+func (hg *Hedging) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	ir := util.InterceptorRouteFromContext(ctx)
+
+	policy := ir.Spec.Hedging
+	if !hg.directPodRouting || policy == nil || !policy.Enabled || !hedgeableRequest(r) {
+		hg.next.ServeHTTP(w, r)
+		return
+	}
+
+	window := hg.latencyWindow(ir)
+	g := &hedgeGroup{
+		next:   hg.next,
+		w:      w,
+		window: window,
+		start:  time.Now(),
+		won:    make(chan struct{}),
+	}
+	tried, _ := ctx.Value(retriedHostsKey).([]string)
+	g.serve(r, tried)
+	primary := g.requests[0]
+
+	// Without a delay yet, the request is only timed.
+	var hedge <-chan time.Time
+	delay, ok := hedgingDelay(policy, window)
+	if ok {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		hedge = timer.C
+	}
+
+	select {
+	case <-g.won:
+	case <-primary.done:
+	case <-ctx.Done():
+	case <-hedge:
+		if exclude, ok := hg.hedgeTo(ctx, ir, tried, g.host(primary)); ok {
+			util.LoggerFromContext(ctx).V(1).Info("hedging request", "delay", delay, "host", exclude[len(exclude)-1])
+			g.serve(r, exclude)
+		}
+	}
+	g.wait(ctx)
+}
+
+// hedgeTo returns the pods the hedged request must avoid, and whether another
+// ready pod is left for it. primaryHost is the pod of the first request, ""
+// if it was not forwarded yet.
+func (hg *Hedging) hedgeTo(ctx context.Context, ir *httpv1beta1.InterceptorRoute, tried []string, primaryHost string) ([]string, bool) {
+	if primaryHost == "" {
+		// Still waiting for ready pods: a second request would wait too.
+		return nil, false
+	}
+	exclude := append(slices.Clip(tried), primaryHost)
+
+	serviceKey := ir.Namespace + "/" + util.TargetRefFromContext(ctx).Service
+	hosts := hg.readyCache.ReadyHosts(serviceKey, util.UpstreamPortNameFromContext(ctx))
+	other := slices.ContainsFunc(hosts, func(host string) bool { return !slices.Contains(exclude, host) })
+	return exclude, other
+}
+
+func (hg *Hedging) latencyWindow(ir *httpv1beta1.InterceptorRoute) *latencyWindow {
+	key := ir.Namespace + "/" + ir.Name
+	if lw, ok := hg.latencies.Load(key); ok {
+		return lw.(*latencyWindow)
+	}
+	lw, _ := hg.latencies.LoadOrStore(key, &latencyWindow{})
+	return lw.(*latencyWindow)
+}
+
+// hedgeableRequest reports whether r may be sent twice: GET and HEAD requests
+// without a body, that are not protocol upgrades.
+func hedgeableRequest(r *http.Request) bool {
+	return (r.Method == http.MethodGet || r.Method == http.MethodHead) &&
+		r.ContentLength == 0 &&
+		r.Header.Get("Upgrade") == ""
+}
+
+// hedgingDelay returns the wait before hedging, false if none is known yet.
+func hedgingDelay(policy *httpv1beta1.HedgingPolicy, window *latencyWindow) (time.Duration, bool) {
+	if policy.Percentile != nil {
+		if d, ok := window.percentile(int(*policy.Percentile)); ok {
+			return d, true
+		}
+	}
+	if policy.Delay != nil {
+		return policy.Delay.Duration, true
+	}
+	return 0, false
+}
+
+// hedgeGroup races the requests sent for one client request. The first to
+// write its response headers wins: its response goes to the client and the
+// others are cancelled and discarded. A failed request, with a 5xx response or
+// an upstream error, only wins if no other request may still succeed.
+type hedgeGroup struct {
+	next   http.Handler
+	w      http.ResponseWriter
+	window *latencyWindow
+	start  time.Time
+	// won is closed once a request won.
+	won chan struct{}
+
+	mu       sync.Mutex
+	requests []*hedgedRequest
+	winner   *hedgedRequest
+	// pending is the number of requests that may still win.
+	pending int
+}
+
+// hedgedRequest is one of the requests of a hedgeGroup.
+type hedgedRequest struct {
+	attempt util.UpstreamAttempt
+	cancel  context.CancelCauseFunc
+	// done is closed once the request was served.
+	done     chan struct{}
+	panicked any
+
+	// host is the pod of the request, guarded by hedgeGroup.mu.
+	host string
+}
+
+// serve starts sending r to a pod not in exclude, unless a request already
+// won.
+func (g *hedgeGroup) serve(r *http.Request, exclude []string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.winner != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancelCause(r.Context())
+	hr := &hedgedRequest{cancel: cancel, done: make(chan struct{})}
+	ctx = util.ContextWithUpstreamAttempt(ctx, &hr.attempt)
+	ctx = context.WithValue(ctx, retriedHostsKey, exclude)
+	ctx = context.WithValue(ctx, podPickedKey, func(host string) {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		hr.host = host
+	})
+	g.requests = append(g.requests, hr)
+	g.pending++
+
+	rw := &hedgeResponseWriter{
+		ResponseWriter: g.w,
+		// The winner writes the client's header once it won.
+		header:  g.w.Header().Clone(),
+		group:   g,
+		request: hr,
+	}
+	go func() {
+		defer close(hr.done)
+		defer cancel(nil)
+		// Panics are raised again by wait, on the goroutine of the handler.
+		defer func() { hr.panicked = recover() }()
+
+		g.next.ServeHTTP(rw, r.WithContext(ctx))
+		rw.finish()
+	}()
+}
+
+// host returns the pod of hr, "" if it was not forwarded yet.
+func (g *hedgeGroup) host(hr *hedgedRequest) string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return hr.host
+}
+
+// claim makes hr the winner if no request won yet, sending its header to the
+// client and cancelling the other requests. A failed request gives way instead
+// while another one may still win. It reports whether hr won.
+func (g *hedgeGroup) claim(hr *hedgedRequest, header http.Header, failed bool) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.winner != nil {
+		return g.winner == hr
+	}
+	if failed && g.pending > 1 {
+		g.pending--
+		return false
+	}
+	g.winner = hr
+	close(g.won)
+
+	h := g.w.Header()
+	clear(h)
+	maps.Copy(h, header)
+
+	g.window.add(time.Since(g.start))
+	for _, other := range g.requests {
+		if other != hr {
+			other.cancel(errHedgeLost)
+		}
+	}
+	return true
+}
+
+// wait waits for every request to be served, then records the attempt of the
+// winner in ctx for the retry middleware.
+func (g *hedgeGroup) wait(ctx context.Context) {
+	g.mu.Lock()
+	requests := g.requests
+	g.mu.Unlock()
+
+	for _, hr := range requests {
+		<-hr.done
+	}
+	for _, hr := range requests {
+		// The reverse proxy aborts requests cancelled while copying the
+		// response body, as losers may be.
+		if hr.panicked != nil && (hr == g.winner || g.winner == nil || hr.panicked != http.ErrAbortHandler) {
+			panic(hr.panicked)
+		}
+	}
+
+	if attempt := util.UpstreamAttemptFromContext(ctx); attempt != nil {
+		*attempt = g.winner.attempt
+	}
+}
+
+// hedgeResponseWriter holds back the headers of a hedged request until it
+// writes its status code, then either sends its response to the client if it
+// won, or discards it.
+// It implements Unwrap() so that we don't have to reimplement optional interfaces like Hijacker, ...
+type hedgeResponseWriter struct {
+	http.ResponseWriter
+	header  http.Header
+	group   *hedgeGroup
+	request *hedgedRequest
+
+	won, lost bool
+}
+
+var _ interface{ Unwrap() http.ResponseWriter } = (*hedgeResponseWriter)(nil)
+
+func (rw *hedgeResponseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+func (rw *hedgeResponseWriter) Header() http.Header {
+	if rw.won {
+		return rw.ResponseWriter.Header()
+	}
+	return rw.header
+}
+
+func (rw *hedgeResponseWriter) WriteHeader(statusCode int) {
+	if rw.lost {
+		return
+	}
+	if !rw.won {
+		// Either request may still win, drop informational responses.
+		if statusCode >= 100 && statusCode < 200 {
+			return
+		}
+		failed := statusCode >= 500 || rw.request.attempt.Err != nil
+		if rw.won = rw.group.claim(rw.request, rw.header, failed); !rw.won {
+			rw.lost = true
+			return
+		}
+	}
+	rw.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (rw *hedgeResponseWriter) Write(b []byte) (int, error) {
+	if !rw.won && !rw.lost {
+		rw.WriteHeader(http.StatusOK)
+	}
+	if rw.lost {
+		return len(b), nil
+	}
+	return rw.ResponseWriter.Write(b)
+}
+
+func (rw *hedgeResponseWriter) FlushError() error {
+	if !rw.won && !rw.lost {
+		rw.WriteHeader(http.StatusOK)
+	}
+	if rw.lost {
+		return nil
+	}
+	return http.NewResponseController(rw.ResponseWriter).Flush()
+}
+
+// EnableFullDuplex is a no-op: hedged requests have no body, and both would
+// otherwise enable it on the same connection.
+func (rw *hedgeResponseWriter) EnableFullDuplex() error {
+	return nil
+}
+
+// finish makes a request that wrote no response win if none did.
+func (rw *hedgeResponseWriter) finish() {
+	if !rw.won && !rw.lost {
+		rw.won = rw.group.claim(rw.request, rw.header, rw.request.attempt.Err != nil)
+		rw.lost = !rw.won
+	}
+}
+
+// latencyWindow holds the recent response header times of a route.
+type latencyWindow struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+}
+
+func (lw *latencyWindow) add(d time.Duration) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+
+	if len(lw.samples) < hedgingWindow {
+		lw.samples = append(lw.samples, d)
+		return
+	}
+	lw.samples[lw.next] = d
+	lw.next = (lw.next + 1) % hedgingWindow
+}
+
+// percentile returns the p-th percentile of the samples, false if there are
+// too few of them.
+func (lw *latencyWindow) percentile(p int) (time.Duration, bool) {
+	lw.mu.Lock()
+	if len(lw.samples) < minHedgingSamples {
+		lw.mu.Unlock()
+		return 0, false
+	}
+	sorted := slices.Clone(lw.samples)
+	lw.mu.Unlock()
+
+	slices.Sort(sorted)
+	return sorted[(len(sorted)*p+99)/100-1], true
+}
@@ -0,0 +1,306 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/go-logr/logr"
+	discov1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	httpv1beta1 "github.com/kedacore/http-add-on/operator/apis/http/v1beta1"
+	"github.com/kedacore/http-add-on/pkg/k8s"
+	"github.com/kedacore/http-add-on/pkg/util"
+)
+
+// slowFirstUpstream answers the first request after slow, or when it is
+// cancelled, and later ones right away with the pod they went to. Later
+// requests fail with a bad gateway error if failLater is set.
+type slowFirstUpstream struct {
+	slow      time.Duration
+	failLater bool
+
+	mu        sync.Mutex
+	hosts     []string
+	cancelled bool
+}
+
+func (f *slowFirstUpstream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	host := util.UpstreamURLFromContext(r.Context()).Host
+	if attempt := util.UpstreamAttemptFromContext(r.Context()); attempt != nil {
+		attempt.Host = host
+	}
+	f.mu.Lock()
+	f.hosts = append(f.hosts, host)
+	first := len(f.hosts) == 1
+	f.mu.Unlock()
+
+	if first {
+		select {
+		case <-time.After(f.slow):
+		case <-r.Context().Done():
+			f.mu.Lock()
+			f.cancelled = true
+			f.mu.Unlock()
+			return
+		}
+	} else if f.failLater {
+		if attempt := util.UpstreamAttemptFromContext(r.Context()); attempt != nil {
+			attempt.Err = errors.New("connection refused")
+		}
+		w.WriteHeader(http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("X-Host", host)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(host))
+}
+
+func addReadyEndpoints(cache *k8s.ReadyEndpointsCache, ips ...string) {
+	port := int32(8080)
+	endpoints := make([]discov1.Endpoint, 0, len(ips))
+	for _, ip := range ips {
+		endpoints = append(endpoints, discov1.Endpoint{Addresses: []string{ip}})
+	}
+	cache.Update(testNamespace+"/"+testService, []*discov1.EndpointSlice{{
+		AddressType: discov1.AddressTypeIPv4,
+		Ports:       []discov1.EndpointPort{{Port: &port}},
+		Endpoints:   endpoints,
+	}})
+}
+
+func hedgingIR(policy *httpv1beta1.HedgingPolicy) *httpv1beta1.InterceptorRoute {
+	ir := defaultIR()
+	ir.Name = "hedged"
+	ir.Spec.Hedging = policy
+	return ir
+}
+
+func TestHedging(t *testing.T) {
+	delay := &httpv1beta1.HedgingPolicy{Enabled: true, Delay: &metav1.Duration{Duration: 50 * time.Millisecond}}
+
+	tests := map[string]struct {
+		policy           *httpv1beta1.HedgingPolicy
+		directPodRouting bool
+		method           string
+		pods             []string
+		slow             time.Duration
+		wantRequests     int
+		wantCancelled    bool
+	}{
+		"slow request hedged to another pod": {
+			policy:           delay,
+			directPodRouting: true,
+			pods:             []string{"10.0.0.1", "10.0.0.2"},
+			slow:             time.Second,
+			wantRequests:     2,
+			wantCancelled:    true,
+		},
+		"fast request not hedged": {
+			policy:           delay,
+			directPodRouting: true,
+			pods:             []string{"10.0.0.1", "10.0.0.2"},
+			slow:             10 * time.Millisecond,
+			wantRequests:     1,
+		},
+		"single pod": {
+			policy:           delay,
+			directPodRouting: true,
+			pods:             []string{"10.0.0.1"},
+			slow:             time.Second,
+			wantRequests:     1,
+		},
+		"without direct pod routing": {
+			policy:       delay,
+			pods:         []string{"10.0.0.1", "10.0.0.2"},
+			slow:         time.Second,
+			wantRequests: 1,
+		},
+		"disabled": {
+			policy:           &httpv1beta1.HedgingPolicy{Delay: delay.Delay},
+			directPodRouting: true,
+			pods:             []string{"10.0.0.1", "10.0.0.2"},
+			slow:             time.Second,
+			wantRequests:     1,
+		},
+		"not a GET request": {
+			policy:           delay,
+			directPodRouting: true,
+			method:           http.MethodDelete,
+			pods:             []string{"10.0.0.1", "10.0.0.2"},
+			slow:             time.Second,
+			wantRequests:     1,
+		},
+		"percentile without response times": {
+			policy:           &httpv1beta1.HedgingPolicy{Enabled: true, Percentile: ptr.To[int32](90)},
+			directPodRouting: true,
+			pods:             []string{"10.0.0.1", "10.0.0.2"},
+			slow:             time.Second,
+			wantRequests:     1,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			synctest.Test(t, func(t *testing.T) {
+				cache := k8s.NewReadyEndpointsCache(logr.Discard())
+				addReadyEndpoints(cache, tt.pods...)
+				upstream := &slowFirstUpstream{slow: tt.slow}
+				resolver := NewEndpointResolver(upstream, cache, EndpointResolverConfig{DirectPodRouting: tt.directPodRouting})
+				mw := NewHedging(resolver, cache, tt.directPodRouting)
+
+				req := newRequest(t, hedgingIR(tt.policy))
+				if tt.method != "" {
+					req.Method = tt.method
+				}
+				attempt := &util.UpstreamAttempt{}
+				req = req.WithContext(util.ContextWithUpstreamAttempt(req.Context(), attempt))
+				rec := httptest.NewRecorder()
+				mw.ServeHTTP(rec, req)
+
+				if got, want := rec.Code, http.StatusOK; got != want {
+					t.Fatalf("status code = %d, want %d", got, want)
+				}
+				if got := len(upstream.hosts); got != tt.wantRequests {
+					t.Fatalf("requests = %d, want %d", got, tt.wantRequests)
+				}
+				if got := upstream.cancelled; got != tt.wantCancelled {
+					t.Errorf("slow request cancelled = %t, want %t", got, tt.wantCancelled)
+				}
+
+				winner := upstream.hosts[len(upstream.hosts)-1]
+				if tt.wantRequests == 2 && upstream.hosts[0] == winner {
+					t.Errorf("hedged request went to the pod of the first one, %s", winner)
+				}
+				if got := rec.Body.String(); got != winner {
+					t.Errorf("body = %q, want the response of %s", got, winner)
+				}
+				if got := rec.Header().Get("X-Host"); got != winner {
+					t.Errorf("X-Host = %q, want the headers of %s", got, winner)
+				}
+				if got := attempt.Host; tt.policy.Enabled && tt.directPodRouting && tt.method == "" && got != winner {
+					t.Errorf("recorded attempt host = %q, want %q", got, winner)
+				}
+			})
+		})
+	}
+}
+
+func TestHedging_FailedHedgeGivesWay(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		cache := k8s.NewReadyEndpointsCache(logr.Discard())
+		addReadyEndpoints(cache, "10.0.0.1", "10.0.0.2")
+		upstream := &slowFirstUpstream{slow: time.Second, failLater: true}
+		resolver := NewEndpointResolver(upstream, cache, EndpointResolverConfig{DirectPodRouting: true})
+		mw := NewHedging(resolver, cache, true)
+
+		ir := hedgingIR(&httpv1beta1.HedgingPolicy{Enabled: true, Delay: &metav1.Duration{Duration: 50 * time.Millisecond}})
+		attempt := &util.UpstreamAttempt{}
+		req := newRequest(t, ir)
+		req = req.WithContext(util.ContextWithUpstreamAttempt(req.Context(), attempt))
+		rec := httptest.NewRecorder()
+		mw.ServeHTTP(rec, req)
+
+		if got := len(upstream.hosts); got != 2 {
+			t.Fatalf("requests = %d, want 2", got)
+		}
+		primary := upstream.hosts[0]
+		if got, want := rec.Code, http.StatusOK; got != want {
+			t.Fatalf("status code = %d, want %d of the slow request", got, want)
+		}
+		if got := rec.Body.String(); got != primary {
+			t.Errorf("body = %q, want the response of %s", got, primary)
+		}
+		if upstream.cancelled {
+			t.Error("slow request cancelled by the failed hedge")
+		}
+		if attempt.Host != primary || attempt.Err != nil {
+			t.Errorf("recorded attempt = %+v, want the one of %s", attempt, primary)
+		}
+	})
+}
+
+func TestHedging_AllFailed(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		cache := k8s.NewReadyEndpointsCache(logr.Discard())
+		addReadyEndpoints(cache, "10.0.0.1", "10.0.0.2")
+		var calls int
+		var mu sync.Mutex
+		upstream := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			calls++
+			first := calls == 1
+			mu.Unlock()
+			if first {
+				time.Sleep(time.Second)
+			}
+			w.WriteHeader(http.StatusServiceUnavailable)
+		})
+		resolver := NewEndpointResolver(upstream, cache, EndpointResolverConfig{DirectPodRouting: true})
+		mw := NewHedging(resolver, cache, true)
+
+		ir := hedgingIR(&httpv1beta1.HedgingPolicy{Enabled: true, Delay: &metav1.Duration{Duration: 50 * time.Millisecond}})
+		rec := httptest.NewRecorder()
+		mw.ServeHTTP(rec, newRequest(t, ir))
+
+		if calls != 2 {
+			t.Fatalf("requests = %d, want 2", calls)
+		}
+		if got, want := rec.Code, http.StatusServiceUnavailable; got != want {
+			t.Fatalf("status code = %d, want %d of the last failed request", got, want)
+		}
+	})
+}
+
+func TestHedging_PercentileDelay(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		cache := k8s.NewReadyEndpointsCache(logr.Discard())
+		addReadyEndpoints(cache, "10.0.0.1", "10.0.0.2")
+
+		ir := hedgingIR(&httpv1beta1.HedgingPolicy{Enabled: true, Percentile: ptr.To[int32](90)})
+		mw := NewHedging(nil, cache, true)
+		serve := func(slow time.Duration) *slowFirstUpstream {
+			upstream := &slowFirstUpstream{slow: slow}
+			mw.next = NewEndpointResolver(upstream, cache, EndpointResolverConfig{DirectPodRouting: true})
+			mw.ServeHTTP(httptest.NewRecorder(), newRequest(t, ir))
+			return upstream
+		}
+
+		for range minHedgingSamples {
+			serve(100 * time.Millisecond)
+		}
+		if got := len(serve(150 * time.Millisecond).hosts); got != 2 {
+			t.Errorf("requests slower than the percentile = %d, want 2", got)
+		}
+		if got := len(serve(50 * time.Millisecond).hosts); got != 1 {
+			t.Errorf("requests faster than the percentile = %d, want 1", got)
+		}
+	})
+}
+
+func TestLatencyWindow(t *testing.T) {
+	var lw latencyWindow
+	if _, ok := lw.percentile(50); ok {
+		t.Fatal("percentile of an empty window should not be known")
+	}
+	for i := range hedgingWindow + 100 {
+		lw.add(time.Duration(i) * time.Millisecond)
+	}
+
+	tests := map[int]time.Duration{
+		1:  101 * time.Millisecond,
+		50: 199 * time.Millisecond,
+		99: 297 * time.Millisecond,
+	}
+	for p, want := range tests {
+		if got, _ := lw.percentile(p); got != want {
+			t.Errorf("percentile(%d) = %s, want %s", p, got, want)
+		}
+	}
+}
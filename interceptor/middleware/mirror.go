@@ -0,0 +1,198 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"k8s.io/utils/ptr"
+
+	"github.com/kedacore/http-add-on/pkg/k8s"
+	"github.com/kedacore/http-add-on/pkg/queue"
+	"github.com/kedacore/http-add-on/pkg/util"
+)
+
+const (
+	// defaultMirrorTimeout bounds mirrored requests when no request timeout
+	// is configured, so a slow mirror cannot pile up goroutines.
+	defaultMirrorTimeout = 30 * time.Second
+
+	// maxMirrorBodyBytes is the largest request body buffered for mirroring.
+	// Requests with larger bodies are not mirrored.
+	maxMirrorBodyBytes = 1 << 20
+
+	// maxMirrorsInFlight bounds the mirrored requests sent at the same time.
+	// Requests sampled while that many are in flight are not mirrored.
+	maxMirrorsInFlight = 256
+)
+
+// Mirror sends a copy of a share of the route's requests to the mirror
+// Service configured on the InterceptorRoute. Mirrored requests are sent in
+// the background through the upstream handler and their responses are
+// discarded. They bypass the counting middleware so they never count toward
+// the route itself. Copies sampled while too many mirrored requests are in
+// flight are dropped.
+type Mirror struct {
+	next           http.Handler
+	upstream       http.Handler
+	readyCache     *k8s.ReadyEndpointsCache
+	queueCounter   queue.Counter
+	requestTimeout time.Duration
+	// inFlight holds a token per mirrored request in flight.
+	inFlight chan struct{}
+}
+
+// NewMirror returns a middleware that mirrors requests. upstream is the
+// forwarding handler used to send the mirrored copies.
+func NewMirror(next, upstream http.Handler, readyCache *k8s.ReadyEndpointsCache, queueCounter queue.Counter, requestTimeout time.Duration) *Mirror {
+	return &Mirror{
+		next:           next,
+		upstream:       upstream,
+		readyCache:     readyCache,
+		queueCounter:   queueCounter,
+		requestTimeout: requestTimeout,
+		inFlight:       make(chan struct{}, maxMirrorsInFlight),
+	}
+}
+
+var _ http.Handler = (*Mirror)(nil)
+
+func (m *Mirror) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	ir := util.InterceptorRouteFromContext(ctx)
+	mirrorURL := util.MirrorURLFromContext(ctx)
+
+	if ir.Spec.Mirror == nil || mirrorURL == nil || !sampleMirror(ptr.Deref(ir.Spec.Mirror.Percent, 100)) {
+		m.next.ServeHTTP(w, r)
+		return
+	}
+
+	mirror := ir.Spec.Mirror
+	serviceKey := ir.Namespace + "/" + mirror.Service
+	// Without counting nothing scales the mirror up, so waiting for it is pointless.
+	if !mirror.CountRequests && !m.readyCache.HasReadyEndpoints(serviceKey) {
+		m.next.ServeHTTP(w, r)
+		return
+	}
+
+	select {
+	case m.inFlight <- struct{}{}:
+	default:
+		util.LoggerFromContext(ctx).V(1).Info("too many mirrored requests in flight, not mirroring request", "limit", cap(m.inFlight))
+		m.next.ServeHTTP(w, r)
+		return
+	}
+
+	body, ok := bufferRequestBody(r, maxMirrorBodyBytes)
+	if !ok {
+		<-m.inFlight
+		util.LoggerFromContext(ctx).V(1).Info("request body too large, not mirroring request", "limit", maxMirrorBodyBytes)
+		m.next.ServeHTTP(w, r)
+		return
+	}
+
+	timeout := m.requestTimeout
+	if ir.Spec.Timeouts.Request != nil && ir.Spec.Timeouts.Request.Duration > 0 {
+		timeout = ir.Spec.Timeouts.Request.Duration
+	}
+	if timeout <= 0 {
+		timeout = defaultMirrorTimeout
+	}
+
+	// Detach from the primary request so that finishing it does not abort the mirror.
+	mirrorCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	mirrorCtx = util.ContextWithTargetRef(mirrorCtx, &mirror.TargetRef)
+	mirrorCtx = util.ContextWithUpstreamURL(mirrorCtx, mirrorURL)
+	serverName := ""
+	if mirrorURL.Scheme == "https" {
+		serverName = mirrorURL.Hostname()
+	}
+	mirrorCtx = util.ContextWithUpstreamServerName(mirrorCtx, serverName)
+	mirrorCtx = util.ContextWithUpstreamPortName(mirrorCtx, util.MirrorPortNameFromContext(ctx))
+
+	mr := r.Clone(mirrorCtx)
+	mr.Body = http.NoBody
+	if len(body) > 0 {
+		mr.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	mr.ContentLength = int64(len(body))
+
+	go func() {
+		defer func() { <-m.inFlight }()
+		defer cancel()
+		m.sendMirror(mr, serviceKey)
+	}()
+
+	m.next.ServeHTTP(w, r)
+}
+
+func (m *Mirror) sendMirror(r *http.Request, serviceKey string) {
+	ctx := r.Context()
+	ir := util.InterceptorRouteFromContext(ctx)
+	logger := util.LoggerFromContext(ctx).WithName("MirrorMiddleware")
+
+	if ir.Spec.Mirror.CountRequests {
+		key := k8s.BackendResourceKey(ir.Namespace, ir.Name, ir.Spec.Mirror.Service)
+		if err := m.queueCounter.Increase(key, 1); err != nil {
+			logger.Error(err, "error incrementing queue counter", "key", key)
+		} else {
+			defer func() {
+				if err := m.queueCounter.Decrease(key, 1); err != nil {
+					logger.Error(err, "error decrementing queue counter", "key", key)
+				}
+			}()
+		}
+
+		if _, _, err := m.readyCache.WaitForReady(ctx, serviceKey, util.UpstreamPortNameFromContext(ctx)); err != nil {
+			logger.V(1).Info("mirror not ready, dropping mirrored request", "service", ir.Spec.Mirror.Service, "err", err.Error())
+			return
+		}
+	}
+
+	m.upstream.ServeHTTP(discardResponseWriter{header: http.Header{}}, r)
+}
+
+// sampleMirror reports whether a request is selected for mirroring given the
+// mirrored percentage.
+func sampleMirror(percent int32) bool {
+	return percent >= 100 || (percent > 0 && rand.Int32N(100) < percent) //nolint:gosec // G404: math/rand is sufficient for sampling
+}
+
+// bufferRequestBody reads the request body so that it can be sent more than
+// once and restores it on r. Returns false if the body exceeds limit bytes, in
+// which case r still reads the complete body.
+func bufferRequestBody(r *http.Request, limit int64) ([]byte, bool) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, true
+	}
+	if r.ContentLength > limit {
+		return nil, false
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, limit+1))
+	if err != nil || int64(len(body)) > limit {
+		r.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(body), r.Body), Closer: r.Body}
+		return nil, false
+	}
+
+	r.Body = readCloser{Reader: bytes.NewReader(body), Closer: r.Body}
+	return body, true
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// discardResponseWriter drops the response of a mirrored request.
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (d discardResponseWriter) Header() http.Header       { return d.header }
+func (discardResponseWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (discardResponseWriter) WriteHeader(int)             {}
+func (discardResponseWriter) EnableFullDuplex() error     { return nil }
@@ -0,0 +1,210 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	discov1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	httpv1beta1 "github.com/kedacore/http-add-on/operator/apis/http/v1beta1"
+	"github.com/kedacore/http-add-on/pkg/k8s"
+	"github.com/kedacore/http-add-on/pkg/queue"
+	"github.com/kedacore/http-add-on/pkg/util"
+)
+
+const testMirrorService = "mirror-svc"
+
+type mirroredRequest struct {
+	url    string
+	target string
+	body   string
+}
+
+func TestMirror_SendsCopy(t *testing.T) {
+	readyCache := k8s.NewReadyEndpointsCache(logr.Discard())
+	addReadyMirrorEndpoint(readyCache)
+
+	mirrored := make(chan mirroredRequest, 1)
+	upstream := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mirrored <- mirroredRequest{
+			url:    util.UpstreamURLFromContext(r.Context()).String(),
+			target: util.TargetRefFromContext(r.Context()).Service,
+			body:   string(body),
+		}
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	var primaryBody string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		primaryBody = string(body)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	ir := mirrorIR(&httpv1beta1.MirrorSpec{TargetRef: httpv1beta1.TargetRef{Service: testMirrorService, Port: 8080}})
+	mw := NewMirror(next, upstream, readyCache, queue.NewFakeCounterBuffered(), time.Second)
+
+	rec := httptest.NewRecorder()
+	mw.ServeHTTP(rec, newMirrorRequest(ir, "hello"))
+
+	if got, want := rec.Code, http.StatusOK; got != want {
+		t.Fatalf("status: got %d, want %d", got, want)
+	}
+	if got, want := primaryBody, "hello"; got != want {
+		t.Fatalf("primary body: got %q, want %q", got, want)
+	}
+
+	select {
+	case got := <-mirrored:
+		want := mirroredRequest{url: "http://mirror-svc.test-namespace:8080", target: testMirrorService, body: "hello"}
+		if got != want {
+			t.Fatalf("mirrored request: got %+v, want %+v", got, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for mirrored request")
+	}
+}
+
+func TestMirror_CountRequests(t *testing.T) {
+	readyCache := k8s.NewReadyEndpointsCache(logr.Discard())
+	addReadyMirrorEndpoint(readyCache)
+	counter := queue.NewFakeCounterBuffered()
+
+	done := make(chan struct{})
+	upstream := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		close(done)
+	})
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	ir := mirrorIR(&httpv1beta1.MirrorSpec{
+		TargetRef:     httpv1beta1.TargetRef{Service: testMirrorService, Port: 8080},
+		CountRequests: true,
+	})
+	mw := NewMirror(next, upstream, readyCache, counter, time.Second)
+	mw.ServeHTTP(httptest.NewRecorder(), newMirrorRequest(ir, ""))
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for mirrored request")
+	}
+
+	select {
+	case got := <-counter.ResizedCh:
+		if want := k8s.BackendResourceKey(testNamespace, "test-route", testMirrorService); got.Host != want {
+			t.Fatalf("counted key: got %q, want %q", got.Host, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for mirror to be counted")
+	}
+}
+
+func TestMirror_NotMirrored(t *testing.T) {
+	tests := map[string]struct {
+		mirror    *httpv1beta1.MirrorSpec
+		noURL     bool
+		notReady  bool
+		bodyBytes int
+		saturated bool
+	}{
+		"no mirror": {},
+		"zero percent": {
+			mirror: &httpv1beta1.MirrorSpec{TargetRef: httpv1beta1.TargetRef{Service: testMirrorService, Port: 8080}, Percent: ptr.To[int32](0)},
+		},
+		"mirror URL not resolved": {
+			mirror: &httpv1beta1.MirrorSpec{TargetRef: httpv1beta1.TargetRef{Service: testMirrorService, Port: 8080}},
+			noURL:  true,
+		},
+		"mirror not ready and not counted": {
+			mirror:   &httpv1beta1.MirrorSpec{TargetRef: httpv1beta1.TargetRef{Service: testMirrorService, Port: 8080}},
+			notReady: true,
+		},
+		"body too large": {
+			mirror:    &httpv1beta1.MirrorSpec{TargetRef: httpv1beta1.TargetRef{Service: testMirrorService, Port: 8080}},
+			bodyBytes: maxMirrorBodyBytes + 1,
+		},
+		"too many mirrors in flight": {
+			mirror:    &httpv1beta1.MirrorSpec{TargetRef: httpv1beta1.TargetRef{Service: testMirrorService, Port: 8080}},
+			saturated: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			readyCache := k8s.NewReadyEndpointsCache(logr.Discard())
+			if !tc.notReady {
+				addReadyMirrorEndpoint(readyCache)
+			}
+
+			mirrored := make(chan struct{}, 1)
+			upstream := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+				mirrored <- struct{}{}
+			})
+
+			body := strings.Repeat("x", tc.bodyBytes)
+			var primaryBody string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := io.ReadAll(r.Body)
+				primaryBody = string(b)
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := newMirrorRequest(mirrorIR(tc.mirror), body)
+			if tc.noURL {
+				req = req.WithContext(util.ContextWithMirrorURL(req.Context(), nil))
+			}
+
+			mw := NewMirror(next, upstream, readyCache, queue.NewFakeCounterBuffered(), time.Second)
+			if tc.saturated {
+				for range cap(mw.inFlight) {
+					mw.inFlight <- struct{}{}
+				}
+			}
+			mw.ServeHTTP(httptest.NewRecorder(), req)
+
+			if primaryBody != body {
+				t.Fatalf("primary body: got %d bytes, want %d bytes", len(primaryBody), len(body))
+			}
+			select {
+			case <-mirrored:
+				t.Fatal("expected request not to be mirrored")
+			case <-time.After(50 * time.Millisecond):
+			}
+		})
+	}
+}
+
+func mirrorIR(mirror *httpv1beta1.MirrorSpec) *httpv1beta1.InterceptorRoute {
+	return &httpv1beta1.InterceptorRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-route", Namespace: testNamespace},
+		Spec: httpv1beta1.InterceptorRouteSpec{
+			Target: httpv1beta1.TargetRef{Service: testService, Port: 8080},
+			Mirror: mirror,
+		},
+	}
+}
+
+func newMirrorRequest(ir *httpv1beta1.InterceptorRoute, body string) *http.Request {
+	req := httptest.NewRequest("POST", "/path", strings.NewReader(body))
+	ctx := util.ContextWithLogger(req.Context(), logr.Discard())
+	ctx = util.ContextWithInterceptorRoute(ctx, ir)
+	ctx = util.ContextWithMirrorURL(ctx, &url.URL{Scheme: "http", Host: "mirror-svc.test-namespace:8080"})
+	return req.WithContext(ctx)
+}
+
+func addReadyMirrorEndpoint(cache *k8s.ReadyEndpointsCache) {
+	cache.Update(testNamespace+"/"+testMirrorService, []*discov1.EndpointSlice{
+		{AddressType: discov1.AddressTypeIPv4, Endpoints: []discov1.Endpoint{{Addresses: []string{"1.2.3.5"}}}},
+	})
+}
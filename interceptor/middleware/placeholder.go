@@ -1,14 +1,49 @@
 package middleware
 
 import (
+	"encoding/json"
+	htmltemplate "html/template"
+	"io"
+	"math"
+	"mime"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+	"time"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	httpv1beta1 "github.com/kedacore/http-add-on/operator/apis/http/v1beta1"
 	"github.com/kedacore/http-add-on/pkg/k8s"
 	"github.com/kedacore/http-add-on/pkg/util"
 )
 
+const (
+	defaultPlaceholderRetryAfter = 5 * time.Second
+	// maxPlaceholderTemplates is the number of parsed templates kept per
+	// route: bodies from ConfigMaps keyed by the request path may differ
+	// between requests.
+	maxPlaceholderTemplates = 64
+)
+
+// autoRefreshPage is the HTML page of AutoRefresh placeholders without a
+// response body.
+var autoRefreshPage = htmltemplate.Must(htmltemplate.New("placeholder").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="{{.RetryAfter}}">
+<title>Starting up</title>
+</head>
+<body>
+<h1>Starting up</h1>
+<p>The service is starting ({{.Elapsed}} elapsed). This page refreshes every {{.RetryAfter}} seconds until it is ready.</p>
+</body>
+</html>
+`))
+
 // Placeholder short-circuits requests with a static response when the
 // backend has no ready endpoints and a placeholder response is configured.
 // It sits before the EndpointResolver so the caller gets an immediate
@@ -17,6 +52,8 @@ type Placeholder struct {
 	next       http.Handler
 	readyCache *k8s.ReadyEndpointsCache
 	reader     client.Reader
+	coldStarts coldStarts
+	templates  placeholderTemplates
 }
 
 // NewPlaceholder returns a middleware that serves a static placeholder
@@ -35,13 +72,287 @@ var _ http.Handler = (*Placeholder)(nil)
 func (p *Placeholder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ir := util.InterceptorRouteFromContext(r.Context())
 
-	if ir.Spec.ColdStart != nil && ir.Spec.ColdStart.Placeholder != nil && ir.Spec.ColdStart.Placeholder.Response != nil {
-		serviceKey := ir.Namespace + "/" + ir.Spec.Target.Service
+	if ph := placeholderOf(ir); ph != nil {
+		serviceKey := ir.Namespace + "/" + util.TargetRefFromContext(r.Context()).Service
+		static := ph.Mode == "" || ph.Mode == httpv1beta1.ColdStartPlaceholderModeStatic
 		if !p.readyCache.HasReadyEndpoints(serviceKey) {
-			serveStaticResponse(w, r, p.reader, ir, ir.Spec.ColdStart.Placeholder.Response, http.StatusServiceUnavailable)
+			if static {
+				serveStaticResponse(w, r, p.reader, ir, ph.Response, http.StatusServiceUnavailable)
+				return
+			}
+			elapsed, previous := p.coldStarts.begin(serviceKey)
+			servePlaceholder(w, r, p.reader, &p.templates, ir, ph, elapsed, placeholderRetryAfter(ph, elapsed, previous))
 			return
 		}
+		if !static {
+			p.coldStarts.end(serviceKey)
+		}
 	}
 
 	p.next.ServeHTTP(w, r)
 }
+
+// placeholderOf returns the cold-start placeholder of ir, nil if it has none.
+func placeholderOf(ir *httpv1beta1.InterceptorRoute) *httpv1beta1.ColdStartPlaceholder {
+	if ir.Spec.ColdStart == nil || ir.Spec.ColdStart.Placeholder == nil {
+		return nil
+	}
+	ph := ir.Spec.ColdStart.Placeholder
+	if ph.Response == nil && ph.Mode != httpv1beta1.ColdStartPlaceholderModeAutoRefresh {
+		return nil
+	}
+	return ph
+}
+
+// placeholderRetryAfter returns the wait clients are told before retrying:
+// the rest of the previous cold start of the backend if known, between one
+// second and the RetryAfter of ph.
+func placeholderRetryAfter(ph *httpv1beta1.ColdStartPlaceholder, elapsed, previous time.Duration) time.Duration {
+	maxWait := ph.RetryAfter.Duration
+	if maxWait <= 0 {
+		maxWait = defaultPlaceholderRetryAfter
+	}
+	if previous <= 0 {
+		return maxWait
+	}
+	return min(max(previous-elapsed, time.Second), maxWait)
+}
+
+// placeholderData is the data of placeholder templates.
+type placeholderData struct {
+	Name      string
+	Namespace string
+	Path      string
+	// Elapsed is the time since the cold start began, rounded to the second.
+	Elapsed time.Duration
+	// RetryAfter is the value of the Retry-After header, in seconds.
+	RetryAfter int
+}
+
+// placeholderStatus is the JSON body of HTML placeholders for clients not
+// accepting HTML.
+type placeholderStatus struct {
+	Status            int    `json:"status"`
+	Message           string `json:"message"`
+	RetryAfterSeconds int    `json:"retryAfterSeconds"`
+}
+
+// servePlaceholder serves the placeholder ph of ir for a backend whose cold
+// start began elapsed ago, telling clients to retry after retryAfter.
+// Templates are parsed once per route generation into templates.
+func servePlaceholder(w http.ResponseWriter, r *http.Request, reader client.Reader, templates *placeholderTemplates, ir *httpv1beta1.InterceptorRoute, ph *httpv1beta1.ColdStartPlaceholder, elapsed, retryAfter time.Duration) {
+	if ph.Mode == "" || ph.Mode == httpv1beta1.ColdStartPlaceholderModeStatic {
+		serveStaticResponse(w, r, reader, ir, ph.Response, http.StatusServiceUnavailable)
+		return
+	}
+
+	data := placeholderData{
+		Name:       ir.Name,
+		Namespace:  ir.Namespace,
+		Path:       r.URL.Path,
+		Elapsed:    elapsed.Round(time.Second),
+		RetryAfter: max(int(math.Ceil(retryAfter.Seconds())), 1),
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(data.RetryAfter))
+	// Rendered bodies hold request data: they must not be sniffed as another
+	// type than the one they were escaped for.
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	if ph.Mode == httpv1beta1.ColdStartPlaceholderModeTemplate {
+		// Bodies without a declared Content-Type are HTML.
+		contentType := declaredContentType(r, ph.Response)
+		html := contentType == "" || isHTML(contentType)
+		if html && !acceptsHTML(r) {
+			servePlaceholderStatus(w, r, ir, data.RetryAfter)
+			return
+		}
+		if contentType == "" {
+			w.Header().Set(headerContentType, "text/html; charset=utf-8")
+		}
+		if isJSON(contentType) {
+			data.Path = jsonStringContent(data.Path)
+		}
+		serveRenderedResponse(w, r, reader, ir, ph.Response, http.StatusServiceUnavailable, func(body, _ string) (string, error) {
+			return templates.render(ir, body, html, data)
+		})
+		return
+	}
+
+	// AutoRefresh.
+	if !acceptsHTML(r) {
+		servePlaceholderStatus(w, r, ir, data.RetryAfter)
+		return
+	}
+
+	w.Header().Set(headerContentType, "text/html; charset=utf-8")
+	w.Header().Set("Refresh", strconv.Itoa(data.RetryAfter))
+	w.Header().Set("Cache-Control", "no-store")
+	resp := ph.Response
+	if resp == nil {
+		resp = &httpv1beta1.StaticResponse{}
+	}
+	serveRenderedResponse(w, r, reader, ir, resp, http.StatusServiceUnavailable, func(body, _ string) (string, error) {
+		if body == "" {
+			var buf strings.Builder
+			err := autoRefreshPage.Execute(&buf, data)
+			return buf.String(), err
+		}
+		return templates.render(ir, body, true, data)
+	})
+}
+
+// servePlaceholderStatus serves the JSON placeholder status of ir to clients
+// not accepting HTML, telling them to retry after retryAfter seconds.
+func servePlaceholderStatus(w http.ResponseWriter, r *http.Request, ir *httpv1beta1.InterceptorRoute, retryAfter int) {
+	w.Header().Set(headerContentType, "application/json")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	if err := json.NewEncoder(w).Encode(placeholderStatus{
+		Status:            http.StatusServiceUnavailable,
+		Message:           "the backend is starting",
+		RetryAfterSeconds: retryAfter,
+	}); err != nil {
+		util.LoggerFromContext(r.Context()).Error(err, "failed to write placeholder status",
+			"interceptorRoute", k8s.NamespacedNameFromObject(ir),
+		)
+	}
+}
+
+// placeholderTemplate is a parsed text or HTML placeholder template.
+type placeholderTemplate interface {
+	Execute(w io.Writer, data any) error
+}
+
+// placeholderTemplateKey identifies a parsed placeholder template of a route.
+type placeholderTemplateKey struct {
+	body string
+	html bool
+}
+
+// placeholderTemplates caches the parsed placeholder templates of the
+// current generation of each route.
+type placeholderTemplates struct {
+	// "namespace/name" -> *routeTemplates
+	routes sync.Map
+}
+
+// routeTemplates are the parsed placeholder templates of a route generation.
+type routeTemplates struct {
+	generation int64
+
+	mu     sync.Mutex
+	parsed map[placeholderTemplateKey]placeholderTemplate
+}
+
+// render renders the placeholder template body of ir with data, escaping it
+// as HTML if html is set.
+func (pt *placeholderTemplates) render(ir *httpv1beta1.InterceptorRoute, body string, html bool, data placeholderData) (string, error) {
+	t, err := pt.get(ir, body, html)
+	if err != nil {
+		return "", err
+	}
+	var buf strings.Builder
+	err = t.Execute(&buf, data)
+	return buf.String(), err
+}
+
+// get returns the parsed template body of ir, parsing it if it is not
+// cached for the generation of ir.
+func (pt *placeholderTemplates) get(ir *httpv1beta1.InterceptorRoute, body string, html bool) (placeholderTemplate, error) {
+	routeKey := ir.Namespace + "/" + ir.Name
+	v, _ := pt.routes.Load(routeKey)
+	rt, _ := v.(*routeTemplates)
+	if rt == nil || rt.generation != ir.Generation {
+		rt = &routeTemplates{generation: ir.Generation, parsed: map[placeholderTemplateKey]placeholderTemplate{}}
+		pt.routes.Store(routeKey, rt)
+	}
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	key := placeholderTemplateKey{body: body, html: html}
+	if t, ok := rt.parsed[key]; ok {
+		return t, nil
+	}
+	t, err := parsePlaceholder(body, html)
+	if err != nil {
+		return nil, err
+	}
+	if len(rt.parsed) >= maxPlaceholderTemplates {
+		clear(rt.parsed)
+	}
+	rt.parsed[key] = t
+	return t, nil
+}
+
+// parsePlaceholder parses the placeholder template body, as an HTML template
+// if html is set.
+func parsePlaceholder(body string, html bool) (placeholderTemplate, error) {
+	if html {
+		return htmltemplate.New("placeholder").Parse(body)
+	}
+	return texttemplate.New("placeholder").Parse(body)
+}
+
+// isHTML reports whether contentType is HTML.
+func isHTML(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == "text/html"
+}
+
+// isJSON reports whether contentType is JSON.
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"))
+}
+
+// jsonStringContent returns s escaped for the inside of a JSON string.
+func jsonStringContent(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b[1 : len(b)-1])
+}
+
+// acceptsHTML reports whether the Accept header of r explicitly accepts
+// text/html.
+func acceptsHTML(r *http.Request) bool {
+	for _, accept := range r.Header.Values("Accept") {
+		for part := range strings.SplitSeq(accept, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err != nil || mediaType != "text/html" {
+				continue
+			}
+			if q, err := strconv.ParseFloat(params["q"], 64); err == nil && q == 0 {
+				continue
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// coldStarts tracks when the cold start of backends served placeholders
+// began, and how long their previous cold start took. A cold start begins
+// with the first placeholder served, and ends with the first request finding
+// the backend ready.
+type coldStarts struct {
+	// "namespace/service" -> time.Time
+	started sync.Map
+	// "namespace/service" -> time.Duration
+	durations sync.Map
+}
+
+// begin returns the time since the cold start of serviceKey began, starting
+// one if needed, and the duration of the previous one, 0 if unknown.
+func (cs *coldStarts) begin(serviceKey string) (elapsed, previous time.Duration) {
+	now := time.Now()
+	started, _ := cs.started.LoadOrStore(serviceKey, now)
+	if d, ok := cs.durations.Load(serviceKey); ok {
+		previous = d.(time.Duration)
+	}
+	return now.Sub(started.(time.Time)), previous
+}
+
+// end ends the cold start of serviceKey, if any.
+func (cs *coldStarts) end(serviceKey string) {
+	if started, ok := cs.started.LoadAndDelete(serviceKey); ok {
+		cs.durations.Store(serviceKey, time.Since(started.(time.Time)))
+	}
+}
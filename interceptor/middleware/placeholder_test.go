@@ -3,7 +3,10 @@ package middleware
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"testing/synctest"
+	"time"
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
@@ -275,6 +278,291 @@ func TestConfigMapKeyFromPath(t *testing.T) {
 	}
 }
 
+func TestPlaceholder_Modes(t *testing.T) {
+	tmpl := `{{.Namespace}}/{{.Name}} {{.Path}}: starting for {{.Elapsed}}, retry in {{.RetryAfter}}s`
+	htmlTmpl := `<p>{{.Path}}</p>`
+	jsonTmpl := `{"path":"{{.Path}}"}`
+
+	tests := map[string]struct {
+		placeholder     httpv1beta1.ColdStartPlaceholder
+		path            string
+		accept          string
+		wantCode        int
+		wantBody        string
+		wantBodyContain string
+		wantContentType string
+		wantRetryAfter  string
+		wantRefresh     string
+		wantNoSniff     bool
+	}{
+		"template": {
+			placeholder: httpv1beta1.ColdStartPlaceholder{
+				Mode:     httpv1beta1.ColdStartPlaceholderModeTemplate,
+				Response: &httpv1beta1.StaticResponse{Body: &tmpl},
+			},
+			path:            "/app",
+			accept:          "text/html",
+			wantCode:        http.StatusServiceUnavailable,
+			wantBody:        testNamespace + "/my-route /app: starting for 3s, retry in 5s",
+			wantContentType: "text/html; charset=utf-8",
+			wantRetryAfter:  "5",
+			wantNoSniff:     true,
+		},
+		"template without content type escaped": {
+			placeholder: httpv1beta1.ColdStartPlaceholder{
+				Mode:     httpv1beta1.ColdStartPlaceholderModeTemplate,
+				Response: &httpv1beta1.StaticResponse{Body: &htmlTmpl},
+			},
+			path:            "/<script>alert(1)</script>",
+			accept:          "text/html",
+			wantCode:        http.StatusServiceUnavailable,
+			wantBody:        "<p>/&lt;script&gt;alert(1)&lt;/script&gt;</p>",
+			wantContentType: "text/html; charset=utf-8",
+			wantRetryAfter:  "5",
+			wantNoSniff:     true,
+		},
+		"template API client": {
+			placeholder: httpv1beta1.ColdStartPlaceholder{
+				Mode:     httpv1beta1.ColdStartPlaceholderModeTemplate,
+				Response: &httpv1beta1.StaticResponse{Body: &htmlTmpl},
+			},
+			path:            "/<script>",
+			accept:          "application/json",
+			wantCode:        http.StatusServiceUnavailable,
+			wantBody:        `{"status":503,"message":"the backend is starting","retryAfterSeconds":5}` + "\n",
+			wantContentType: "application/json",
+			wantRetryAfter:  "5",
+			wantNoSniff:     true,
+		},
+		"HTML template escaped": {
+			placeholder: httpv1beta1.ColdStartPlaceholder{
+				Mode: httpv1beta1.ColdStartPlaceholderModeTemplate,
+				Response: &httpv1beta1.StaticResponse{
+					Body:    &htmlTmpl,
+					Headers: map[string]string{"content-type": "text/html"},
+				},
+			},
+			path:            "/<script>",
+			accept:          "text/html",
+			wantCode:        http.StatusServiceUnavailable,
+			wantBody:        "<p>/&lt;script&gt;</p>",
+			wantContentType: "text/html",
+			wantRetryAfter:  "5",
+			wantNoSniff:     true,
+		},
+		"plain text template served to API clients": {
+			placeholder: httpv1beta1.ColdStartPlaceholder{
+				Mode: httpv1beta1.ColdStartPlaceholderModeTemplate,
+				Response: &httpv1beta1.StaticResponse{
+					Body:    &htmlTmpl,
+					Headers: map[string]string{"content-type": "text/plain"},
+				},
+			},
+			path:            "/<script>",
+			wantCode:        http.StatusServiceUnavailable,
+			wantBody:        "<p>/<script></p>",
+			wantContentType: "text/plain",
+			wantRetryAfter:  "5",
+			wantNoSniff:     true,
+		},
+		"JSON template escaped": {
+			placeholder: httpv1beta1.ColdStartPlaceholder{
+				Mode: httpv1beta1.ColdStartPlaceholderModeTemplate,
+				Response: &httpv1beta1.StaticResponse{
+					Body:    &jsonTmpl,
+					Headers: map[string]string{"content-type": "application/json"},
+				},
+			},
+			path:            `/a%22,"b":"c`,
+			wantCode:        http.StatusServiceUnavailable,
+			wantBody:        `{"path":"/a\",\"b\":\"c"}`,
+			wantContentType: "application/json",
+			wantRetryAfter:  "5",
+			wantNoSniff:     true,
+		},
+		"static body not rendered": {
+			placeholder: httpv1beta1.ColdStartPlaceholder{
+				Response: &httpv1beta1.StaticResponse{Body: &tmpl},
+			},
+			path:     "/app",
+			wantCode: http.StatusServiceUnavailable,
+			wantBody: tmpl,
+		},
+		"auto-refresh HTML page": {
+			placeholder: httpv1beta1.ColdStartPlaceholder{
+				Mode:       httpv1beta1.ColdStartPlaceholderModeAutoRefresh,
+				RetryAfter: metav1.Duration{Duration: 10 * time.Second},
+			},
+			path:            "/",
+			accept:          "text/html,application/xhtml+xml;q=0.9,*/*;q=0.8",
+			wantCode:        http.StatusServiceUnavailable,
+			wantBodyContain: `<meta http-equiv="refresh" content="10">`,
+			wantContentType: "text/html; charset=utf-8",
+			wantRetryAfter:  "10",
+			wantRefresh:     "10",
+			wantNoSniff:     true,
+		},
+		"auto-refresh custom HTML page": {
+			placeholder: httpv1beta1.ColdStartPlaceholder{
+				Mode:     httpv1beta1.ColdStartPlaceholderModeAutoRefresh,
+				Response: &httpv1beta1.StaticResponse{Body: &htmlTmpl},
+			},
+			path:            "/app",
+			accept:          "text/html",
+			wantCode:        http.StatusServiceUnavailable,
+			wantBody:        "<p>/app</p>",
+			wantContentType: "text/html; charset=utf-8",
+			wantRetryAfter:  "5",
+			wantRefresh:     "5",
+			wantNoSniff:     true,
+		},
+		"auto-refresh API client": {
+			placeholder: httpv1beta1.ColdStartPlaceholder{
+				Mode:     httpv1beta1.ColdStartPlaceholderModeAutoRefresh,
+				Response: &httpv1beta1.StaticResponse{Body: &htmlTmpl},
+			},
+			path:            "/api",
+			accept:          "*/*",
+			wantCode:        http.StatusServiceUnavailable,
+			wantBody:        `{"status":503,"message":"the backend is starting","retryAfterSeconds":5}` + "\n",
+			wantContentType: "application/json",
+			wantRetryAfter:  "5",
+			wantNoSniff:     true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			synctest.Test(t, func(t *testing.T) {
+				cache := k8s.NewReadyEndpointsCache(logr.Discard())
+				ir := placeholderIR(nil)
+				ir.Name = "my-route"
+				ir.Spec.ColdStart.Placeholder = &tt.placeholder
+				mw := NewPlaceholder(http.NotFoundHandler(), cache, nil)
+
+				// The cold start begins with the first request.
+				mw.ServeHTTP(httptest.NewRecorder(), newPlaceholderRequestWithPath(t, ir, tt.path))
+				time.Sleep(3 * time.Second)
+
+				rec := httptest.NewRecorder()
+				req := newPlaceholderRequestWithPath(t, ir, tt.path)
+				if tt.accept != "" {
+					req.Header.Set("Accept", tt.accept)
+				}
+				mw.ServeHTTP(rec, req)
+
+				if got := rec.Code; got != tt.wantCode {
+					t.Fatalf("status code = %d, want %d", got, tt.wantCode)
+				}
+				if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+					t.Errorf("body = %q, want %q", rec.Body.String(), tt.wantBody)
+				}
+				if !strings.Contains(rec.Body.String(), tt.wantBodyContain) {
+					t.Errorf("body = %q, want it to contain %q", rec.Body.String(), tt.wantBodyContain)
+				}
+				if tt.wantContentType != "" && rec.Header().Get("Content-Type") != tt.wantContentType {
+					t.Errorf("Content-Type = %q, want %q", rec.Header().Get("Content-Type"), tt.wantContentType)
+				}
+				if got := rec.Header().Get("Retry-After"); got != tt.wantRetryAfter {
+					t.Errorf("Retry-After = %q, want %q", got, tt.wantRetryAfter)
+				}
+				if got := rec.Header().Get("Refresh"); got != tt.wantRefresh {
+					t.Errorf("Refresh = %q, want %q", got, tt.wantRefresh)
+				}
+				if got := rec.Header().Get("X-Content-Type-Options") == "nosniff"; got != tt.wantNoSniff {
+					t.Errorf("X-Content-Type-Options nosniff = %t, want %t", got, tt.wantNoSniff)
+				}
+			})
+		})
+	}
+}
+
+func TestPlaceholderTemplates(t *testing.T) {
+	var pt placeholderTemplates
+	ir := placeholderIR(nil)
+	ir.Generation = 1
+
+	first, err := pt.get(ir, "{{.Path}}", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, _ := pt.get(ir, "{{.Path}}", false); got != first {
+		t.Error("template parsed again for the same route generation")
+	}
+	if got, _ := pt.get(ir, "{{.Path}}", true); got == first {
+		t.Error("text template reused as an HTML template")
+	}
+
+	ir.Generation = 2
+	if got, _ := pt.get(ir, "{{.Path}}", false); got == first {
+		t.Error("template of the previous route generation reused")
+	}
+
+	if _, err := pt.get(ir, "{{.Path", false); err == nil {
+		t.Error("invalid template parsed")
+	}
+}
+
+func TestPlaceholder_RetryAfterFromPreviousColdStart(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		cache := k8s.NewReadyEndpointsCache(logr.Discard())
+		ir := placeholderIR(nil)
+		ir.Spec.ColdStart.Placeholder = &httpv1beta1.ColdStartPlaceholder{
+			Mode:       httpv1beta1.ColdStartPlaceholderModeAutoRefresh,
+			RetryAfter: metav1.Duration{Duration: 10 * time.Second},
+		}
+		mw := NewPlaceholder(http.NotFoundHandler(), cache, nil)
+		retryAfter := func() string {
+			rec := httptest.NewRecorder()
+			mw.ServeHTTP(rec, newPlaceholderRequestWithPath(t, ir, "/"))
+			return rec.Header().Get("Retry-After")
+		}
+
+		// A first cold start of 4s.
+		retryAfter()
+		time.Sleep(4 * time.Second)
+		addReadyEndpoint(cache)
+		mw.ServeHTTP(httptest.NewRecorder(), newPlaceholderRequestWithPath(t, ir, "/"))
+		cache.Update(testNamespace+"/"+testService, nil)
+
+		if got, want := retryAfter(), "4"; got != want {
+			t.Errorf("Retry-After = %q, want the previous cold start, %q", got, want)
+		}
+		time.Sleep(3 * time.Second)
+		if got, want := retryAfter(), "1"; got != want {
+			t.Errorf("Retry-After = %q, want the rest of the previous cold start, %q", got, want)
+		}
+		time.Sleep(3 * time.Second)
+		if got, want := retryAfter(), "1"; got != want {
+			t.Errorf("Retry-After = %q, want at least %q", got, want)
+		}
+	})
+}
+
+func TestAcceptsHTML(t *testing.T) {
+	tests := map[string]bool{
+		"":                              false,
+		"*/*":                           false,
+		"application/json":              false,
+		"text/html":                     true,
+		"application/json, text/html":   true,
+		"text/html;q=0":                 false,
+		"text/html;q=0.5, */*;q=0.1":    true,
+		"application/xhtml+xml, text/*": false,
+	}
+	for accept, want := range tests {
+		t.Run(accept, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if accept != "" {
+				req.Header.Set("Accept", accept)
+			}
+			if got := acceptsHTML(req); got != want {
+				t.Fatalf("acceptsHTML(%q) = %t, want %t", accept, got, want)
+			}
+		})
+	}
+}
+
 func placeholderIR(resp *httpv1beta1.StaticResponse) *httpv1beta1.InterceptorRoute {
 	return &httpv1beta1.InterceptorRoute{
 		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace},
@@ -0,0 +1,286 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"slices"
+	"time"
+
+	"k8s.io/utils/ptr"
+
+	"github.com/kedacore/http-add-on/interceptor/handler"
+	httpv1beta1 "github.com/kedacore/http-add-on/operator/apis/http/v1beta1"
+	"github.com/kedacore/http-add-on/pkg/util"
+)
+
+const (
+	defaultRetryAttempts     = 3
+	defaultRetryBaseInterval = 25 * time.Millisecond
+	defaultRetryMaxInterval  = 250 * time.Millisecond
+
+	// maxRetryBodyBytes is the largest request body buffered for retries.
+	// Requests with larger bodies are not retried.
+	maxRetryBodyBytes = 64 << 10
+)
+
+var (
+	defaultRetryStatusCodes = []int32{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+
+	errPerTryTimeout = fmt.Errorf("per-try timeout exceeded: %w", context.DeadlineExceeded)
+)
+
+// Retry retries the requests of routes with a retry policy that the backend
+// failed. The response of an attempt is held back until its status code shows
+// whether the attempt is retried, and discarded if so. Only attempts that
+// reached the upstream are retried, not readiness timeouts. It sits before
+// the EndpointResolver so that each attempt selects a pod, avoiding the pods
+// already tried.
+type Retry struct {
+	next http.Handler
+}
+
+// NewRetry returns a middleware enforcing the retry policy of the route of
+// each request.
+func NewRetry(next http.Handler) *Retry {
+	return &Retry{next: next}
+}
+
+var _ http.Handler = (*Retry)(nil)
+
+func (rt *Retry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	ir := util.InterceptorRouteFromContext(ctx)
+
+	policy := ir.Spec.Retries
+	if policy == nil || !retryableMethod(r.Method, policy.AllowNonIdempotent) {
+		rt.next.ServeHTTP(w, r)
+		return
+	}
+	attempts := int(policy.Attempts)
+	if attempts <= 0 {
+		attempts = defaultRetryAttempts
+	}
+	if attempts == 1 {
+		rt.next.ServeHTTP(w, r)
+		return
+	}
+
+	logger := util.LoggerFromContext(ctx)
+	body, ok := bufferRequestBody(r, maxRetryBodyBytes)
+	if !ok {
+		logger.V(1).Info("request body too large, not retrying request", "limit", maxRetryBodyBytes)
+		rt.next.ServeHTTP(w, r)
+		return
+	}
+
+	var tried []string
+	for n := 1; ; n++ {
+		attempt := &util.UpstreamAttempt{}
+		rw := rt.serveAttempt(w, r, body, policy, attempt, tried, n == attempts)
+		if !rw.discarded {
+			rw.finish()
+			return
+		}
+
+		logger.V(1).Info("retrying request", "attempt", n, "host", attempt.Host, "statusCode", rw.discardedCode, "err", attempt.Err)
+		tried = append(tried, attempt.Host)
+
+		timer := time.NewTimer(retryBackoff(policy.Backoff, n))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			handler.
+				NewStatic(http.StatusGatewayTimeout, fmt.Errorf("waiting to retry request: %w", ctx.Err())).
+				ServeHTTP(w, r)
+			return
+		}
+	}
+}
+
+// serveAttempt forwards attempt n of r, avoiding the tried hosts.
+func (rt *Retry) serveAttempt(
+	w http.ResponseWriter,
+	r *http.Request,
+	body []byte,
+	policy *httpv1beta1.RetryPolicy,
+	attempt *util.UpstreamAttempt,
+	tried []string,
+	last bool,
+) *retryResponseWriter {
+	ctx, cancel := context.WithCancelCause(r.Context())
+	defer cancel(nil)
+	ctx = util.ContextWithUpstreamAttempt(ctx, attempt)
+	ctx = context.WithValue(ctx, retriedHostsKey, tried)
+
+	var perTry *time.Timer
+	if policy.PerTryTimeout != nil && policy.PerTryTimeout.Duration > 0 {
+		perTry = time.AfterFunc(policy.PerTryTimeout.Duration, func() { cancel(errPerTryTimeout) })
+		defer perTry.Stop()
+	}
+
+	rw := newRetryResponseWriter(w, func(code int) bool {
+		// The per-try timeout ends with the response headers.
+		if perTry != nil {
+			perTry.Stop()
+		}
+		return !last && r.Context().Err() == nil && shouldRetry(ctx, policy, attempt, code)
+	})
+
+	ar := r.WithContext(ctx)
+	if body != nil {
+		ar.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	rt.next.ServeHTTP(rw, ar)
+	return rw
+}
+
+// retryableMethod reports whether requests with method may be retried.
+func retryableMethod(method string, allowNonIdempotent bool) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return allowNonIdempotent
+	}
+}
+
+// shouldRetry reports whether the attempt answered with code is retried.
+func shouldRetry(ctx context.Context, policy *httpv1beta1.RetryPolicy, attempt *util.UpstreamAttempt, code int) bool {
+	switch {
+	case attempt.Host == "":
+		// Not forwarded, e.g. the backend did not become ready in time.
+		return false
+	case attempt.Err == nil:
+		statusCodes := policy.StatusCodes
+		if len(statusCodes) == 0 {
+			statusCodes = defaultRetryStatusCodes
+		}
+		return slices.Contains(statusCodes, int32(code))
+	case errors.Is(context.Cause(ctx), errPerTryTimeout):
+		return true
+	}
+
+	// Request and response header timeouts are not connection failures.
+	var netErr net.Error
+	if errors.As(attempt.Err, &netErr) && netErr.Timeout() || errors.Is(attempt.Err, context.DeadlineExceeded) {
+		return false
+	}
+	return ptr.Deref(policy.OnConnectionFailure, true)
+}
+
+// retryBackoff returns the random wait before retrying after attempt n.
+func retryBackoff(b httpv1beta1.RetryBackoff, n int) time.Duration {
+	base := b.BaseInterval.Duration
+	if base <= 0 {
+		base = defaultRetryBaseInterval
+	}
+	maxInterval := b.MaxInterval.Duration
+	if maxInterval <= 0 {
+		maxInterval = defaultRetryMaxInterval
+	}
+
+	d := maxInterval
+	if shift := n - 1; shift < 32 && base<<shift > 0 && base<<shift < maxInterval {
+		d = base << shift
+	}
+	return rand.N(d) //nolint:gosec // G404: math/rand is sufficient for backoff jitter
+}
+
+// retryResponseWriter holds back the headers of an attempt until its status
+// code is known, then either commits them to the client or discards the
+// whole response of the attempt.
+// It implements Unwrap() so that we don't have to reimplement optional interfaces like Hijacker, ...
+type retryResponseWriter struct {
+	http.ResponseWriter
+	header http.Header
+	// retry reports whether a response with the given status code is
+	// discarded to retry the request.
+	retry func(code int) bool
+
+	committed     bool
+	discarded     bool
+	discardedCode int
+}
+
+func newRetryResponseWriter(w http.ResponseWriter, retry func(code int) bool) *retryResponseWriter {
+	return &retryResponseWriter{
+		ResponseWriter: w,
+		header:         w.Header().Clone(),
+		retry:          retry,
+	}
+}
+
+var _ interface{ Unwrap() http.ResponseWriter } = (*retryResponseWriter)(nil)
+
+func (rw *retryResponseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+func (rw *retryResponseWriter) Header() http.Header {
+	if rw.committed {
+		return rw.ResponseWriter.Header()
+	}
+	return rw.header
+}
+
+func (rw *retryResponseWriter) WriteHeader(statusCode int) {
+	if rw.committed || rw.discarded {
+		return
+	}
+	// Forward informational responses such as 103 Early Hints right away.
+	if statusCode >= 100 && statusCode < 200 && statusCode != http.StatusSwitchingProtocols {
+		rw.copyHeader()
+		rw.ResponseWriter.WriteHeader(statusCode)
+		return
+	}
+
+	if rw.retry(statusCode) {
+		rw.discarded = true
+		rw.discardedCode = statusCode
+		return
+	}
+	rw.copyHeader()
+	rw.committed = true
+	rw.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (rw *retryResponseWriter) Write(b []byte) (int, error) {
+	if !rw.committed {
+		rw.WriteHeader(http.StatusOK)
+	}
+	if rw.discarded {
+		return len(b), nil
+	}
+	return rw.ResponseWriter.Write(b)
+}
+
+func (rw *retryResponseWriter) FlushError() error {
+	if !rw.committed {
+		rw.WriteHeader(http.StatusOK)
+	}
+	if rw.discarded {
+		return nil
+	}
+	return http.NewResponseController(rw.ResponseWriter).Flush()
+}
+
+// finish commits the headers of an attempt that wrote no response.
+func (rw *retryResponseWriter) finish() {
+	if !rw.committed && !rw.discarded {
+		rw.copyHeader()
+	}
+}
+
+func (rw *retryResponseWriter) copyHeader() {
+	h := rw.ResponseWriter.Header()
+	clear(h)
+	maps.Copy(h, rw.header)
+}
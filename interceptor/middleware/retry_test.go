@@ -0,0 +1,345 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	discov1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	httpv1beta1 "github.com/kedacore/http-add-on/operator/apis/http/v1beta1"
+	"github.com/kedacore/http-add-on/pkg/k8s"
+	"github.com/kedacore/http-add-on/pkg/util"
+)
+
+// fakeUpstream stands in for handler.Upstream: it records each attempt in
+// the request context and answers with respond, passing the attempt number.
+type fakeUpstream struct {
+	respond func(w http.ResponseWriter, r *http.Request, attempt *util.UpstreamAttempt, n int)
+
+	hosts  []string
+	bodies []string
+}
+
+func (f *fakeUpstream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	host := util.UpstreamURLFromContext(r.Context()).Host
+	f.hosts = append(f.hosts, host)
+	if r.Body != nil {
+		b, _ := io.ReadAll(r.Body)
+		f.bodies = append(f.bodies, string(b))
+	}
+
+	attempt := util.UpstreamAttemptFromContext(r.Context())
+	if attempt != nil {
+		attempt.Host = host
+	}
+	f.respond(w, r, attempt, len(f.hosts))
+}
+
+// failFirst answers the first n attempts with code and later ones with 200.
+func failFirst(n, code int) func(http.ResponseWriter, *http.Request, *util.UpstreamAttempt, int) {
+	return func(w http.ResponseWriter, _ *http.Request, _ *util.UpstreamAttempt, attempt int) {
+		if attempt <= n {
+			w.Header().Set("X-Failed", "true")
+			w.WriteHeader(code)
+			_, _ = w.Write([]byte("failed"))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	}
+}
+
+func retryIR(policy *httpv1beta1.RetryPolicy) *httpv1beta1.InterceptorRoute {
+	ir := defaultIR()
+	ir.Spec.Retries = policy
+	return ir
+}
+
+func TestRetry_StatusCodes(t *testing.T) {
+	tests := map[string]struct {
+		policy       *httpv1beta1.RetryPolicy
+		method       string
+		failures     int
+		failureCode  int
+		wantCode     int
+		wantAttempts int
+	}{
+		"no policy": {
+			policy:       nil,
+			method:       http.MethodGet,
+			failures:     1,
+			failureCode:  http.StatusServiceUnavailable,
+			wantCode:     http.StatusServiceUnavailable,
+			wantAttempts: 1,
+		},
+		"retried until success": {
+			policy:       &httpv1beta1.RetryPolicy{},
+			method:       http.MethodGet,
+			failures:     2,
+			failureCode:  http.StatusServiceUnavailable,
+			wantCode:     http.StatusOK,
+			wantAttempts: 3,
+		},
+		"attempts exhausted": {
+			policy:       &httpv1beta1.RetryPolicy{Attempts: 2},
+			method:       http.MethodGet,
+			failures:     5,
+			failureCode:  http.StatusBadGateway,
+			wantCode:     http.StatusBadGateway,
+			wantAttempts: 2,
+		},
+		"status code not retryable": {
+			policy:       &httpv1beta1.RetryPolicy{},
+			method:       http.MethodGet,
+			failures:     1,
+			failureCode:  http.StatusInternalServerError,
+			wantCode:     http.StatusInternalServerError,
+			wantAttempts: 1,
+		},
+		"configured status code": {
+			policy:       &httpv1beta1.RetryPolicy{StatusCodes: []int32{http.StatusTooManyRequests}},
+			method:       http.MethodGet,
+			failures:     1,
+			failureCode:  http.StatusTooManyRequests,
+			wantCode:     http.StatusOK,
+			wantAttempts: 2,
+		},
+		"non-idempotent method": {
+			policy:       &httpv1beta1.RetryPolicy{},
+			method:       http.MethodPost,
+			failures:     1,
+			failureCode:  http.StatusServiceUnavailable,
+			wantCode:     http.StatusServiceUnavailable,
+			wantAttempts: 1,
+		},
+		"non-idempotent method allowed": {
+			policy:       &httpv1beta1.RetryPolicy{AllowNonIdempotent: true},
+			method:       http.MethodPost,
+			failures:     1,
+			failureCode:  http.StatusServiceUnavailable,
+			wantCode:     http.StatusOK,
+			wantAttempts: 2,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			upstream := &fakeUpstream{respond: failFirst(tt.failures, tt.failureCode)}
+			mw := NewRetry(upstream)
+
+			req := newRequest(t, retryIR(tt.policy))
+			req.Method = tt.method
+			rec := httptest.NewRecorder()
+			mw.ServeHTTP(rec, req)
+
+			if got := rec.Code; got != tt.wantCode {
+				t.Errorf("status code = %d, want %d", got, tt.wantCode)
+			}
+			if got := len(upstream.hosts); got != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", got, tt.wantAttempts)
+			}
+			if tt.wantCode == http.StatusOK {
+				if got := rec.Body.String(); got != "ok" {
+					t.Errorf("body = %q, want the body of the last attempt only", got)
+				}
+				if got := rec.Header().Get("X-Failed"); got != "" {
+					t.Errorf("X-Failed = %q, want the headers of the last attempt only", got)
+				}
+			}
+		})
+	}
+}
+
+func TestRetry_ReplaysBody(t *testing.T) {
+	upstream := &fakeUpstream{respond: failFirst(1, http.StatusServiceUnavailable)}
+	mw := NewRetry(upstream)
+
+	req := newRequest(t, retryIR(&httpv1beta1.RetryPolicy{}))
+	req.Method = http.MethodPut
+	req.Body = io.NopCloser(strings.NewReader("payload"))
+	rec := httptest.NewRecorder()
+	mw.ServeHTTP(rec, req)
+
+	if got, want := rec.Code, http.StatusOK; got != want {
+		t.Errorf("status code = %d, want %d", got, want)
+	}
+	if want := []string{"payload", "payload"}; !slices.Equal(upstream.bodies, want) {
+		t.Errorf("bodies = %q, want %q", upstream.bodies, want)
+	}
+
+	// Bodies too large to buffer are forwarded once.
+	upstream = &fakeUpstream{respond: failFirst(1, http.StatusServiceUnavailable)}
+	mw = NewRetry(upstream)
+	large := strings.Repeat("x", maxRetryBodyBytes+1)
+	req = newRequest(t, retryIR(&httpv1beta1.RetryPolicy{}))
+	req.Method = http.MethodPut
+	req.Body = io.NopCloser(strings.NewReader(large))
+	rec = httptest.NewRecorder()
+	mw.ServeHTTP(rec, req)
+
+	if got, want := rec.Code, http.StatusServiceUnavailable; got != want {
+		t.Errorf("status code = %d, want %d", got, want)
+	}
+	if len(upstream.bodies) != 1 || upstream.bodies[0] != large {
+		t.Errorf("large body forwarded %d times, want once and complete", len(upstream.bodies))
+	}
+}
+
+func TestRetry_UpstreamErrors(t *testing.T) {
+	connFailure := func(w http.ResponseWriter, _ *http.Request, attempt *util.UpstreamAttempt, n int) {
+		if n == 1 {
+			attempt.Err = syscall.ECONNRESET
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	notForwarded := func(w http.ResponseWriter, _ *http.Request, attempt *util.UpstreamAttempt, _ int) {
+		attempt.Host = ""
+		w.WriteHeader(http.StatusGatewayTimeout)
+	}
+	perTryTimeout := func(w http.ResponseWriter, r *http.Request, attempt *util.UpstreamAttempt, n int) {
+		if n == 1 {
+			<-r.Context().Done()
+			attempt.Err = r.Context().Err()
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	responseHeaderTimeout := func(w http.ResponseWriter, _ *http.Request, attempt *util.UpstreamAttempt, _ int) {
+		attempt.Err = context.DeadlineExceeded
+		w.WriteHeader(http.StatusGatewayTimeout)
+	}
+
+	tests := map[string]struct {
+		policy       httpv1beta1.RetryPolicy
+		respond      func(http.ResponseWriter, *http.Request, *util.UpstreamAttempt, int)
+		wantCode     int
+		wantAttempts int
+	}{
+		"connection failure": {
+			respond:      connFailure,
+			wantCode:     http.StatusOK,
+			wantAttempts: 2,
+		},
+		"connection failure not retried": {
+			policy:       httpv1beta1.RetryPolicy{OnConnectionFailure: ptr.To(false)},
+			respond:      connFailure,
+			wantCode:     http.StatusBadGateway,
+			wantAttempts: 1,
+		},
+		"not forwarded": {
+			respond:      notForwarded,
+			wantCode:     http.StatusGatewayTimeout,
+			wantAttempts: 1,
+		},
+		"per-try timeout": {
+			policy:       httpv1beta1.RetryPolicy{PerTryTimeout: &metav1.Duration{Duration: 20 * time.Millisecond}},
+			respond:      perTryTimeout,
+			wantCode:     http.StatusOK,
+			wantAttempts: 2,
+		},
+		"response header timeout": {
+			respond:      responseHeaderTimeout,
+			wantCode:     http.StatusGatewayTimeout,
+			wantAttempts: 1,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			upstream := &fakeUpstream{respond: tt.respond}
+			mw := NewRetry(upstream)
+
+			rec := httptest.NewRecorder()
+			mw.ServeHTTP(rec, newRequest(t, retryIR(&tt.policy)))
+
+			if got := rec.Code; got != tt.wantCode {
+				t.Errorf("status code = %d, want %d", got, tt.wantCode)
+			}
+			if got := len(upstream.hosts); got != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", got, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestRetry_DirectPodRoutingTriesOtherPods(t *testing.T) {
+	port := int32(8080)
+	cache := k8s.NewReadyEndpointsCache(logr.Discard())
+	cache.Update(testNamespace+"/"+testService, []*discov1.EndpointSlice{{
+		AddressType: discov1.AddressTypeIPv4,
+		Ports:       []discov1.EndpointPort{{Port: &port}},
+		Endpoints: []discov1.Endpoint{
+			{Addresses: []string{"10.0.0.1"}},
+			{Addresses: []string{"10.0.0.2"}},
+			{Addresses: []string{"10.0.0.3"}},
+		},
+	}})
+
+	upstream := &fakeUpstream{respond: failFirst(2, http.StatusServiceUnavailable)}
+	mw := NewRetry(NewEndpointResolver(upstream, cache, EndpointResolverConfig{DirectPodRouting: true}))
+
+	ir := retryIR(&httpv1beta1.RetryPolicy{})
+	ir.Spec.SessionAffinity = &httpv1beta1.SessionAffinitySpec{}
+	rec := httptest.NewRecorder()
+	mw.ServeHTTP(rec, newRequest(t, ir))
+
+	if got, want := rec.Code, http.StatusOK; got != want {
+		t.Errorf("status code = %d, want %d", got, want)
+	}
+	if got := len(upstream.hosts); got != 3 {
+		t.Fatalf("attempts = %d, want 3", got)
+	}
+	if sorted := slices.Sorted(slices.Values(upstream.hosts)); !slices.Equal(sorted, []string{"10.0.0.1:8080", "10.0.0.2:8080", "10.0.0.3:8080"}) {
+		t.Errorf("upstream hosts = %v, want each pod once", upstream.hosts)
+	}
+	// Only the cookie of the pod that answered is set.
+	if cookies := rec.Result().Cookies(); len(cookies) != 1 {
+		t.Errorf("cookies = %+v, want one", cookies)
+	}
+}
+
+func TestRetry_CanceledDuringBackoff(t *testing.T) {
+	upstream := &fakeUpstream{respond: failFirst(1, http.StatusServiceUnavailable)}
+	mw := NewRetry(upstream)
+
+	policy := &httpv1beta1.RetryPolicy{Backoff: httpv1beta1.RetryBackoff{
+		BaseInterval: metav1.Duration{Duration: time.Hour},
+		MaxInterval:  metav1.Duration{Duration: time.Hour},
+	}}
+	req := newRequest(t, retryIR(policy))
+	ctx, cancel := context.WithTimeout(req.Context(), 50*time.Millisecond)
+	defer cancel()
+	rec := httptest.NewRecorder()
+	mw.ServeHTTP(rec, req.WithContext(ctx))
+
+	if got, want := rec.Code, http.StatusGatewayTimeout; got != want {
+		t.Errorf("status code = %d, want %d", got, want)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	b := httpv1beta1.RetryBackoff{
+		BaseInterval: metav1.Duration{Duration: 10 * time.Millisecond},
+		MaxInterval:  metav1.Duration{Duration: 35 * time.Millisecond},
+	}
+	for n, limit := range map[int]time.Duration{1: 10 * time.Millisecond, 2: 20 * time.Millisecond, 3: 35 * time.Millisecond, 40: 35 * time.Millisecond} {
+		for range 100 {
+			if got := retryBackoff(b, n); got < 0 || got >= limit {
+				t.Fatalf("retryBackoff(%d) = %s, want in [0, %s)", n, got, limit)
+			}
+		}
+	}
+}
@@ -4,7 +4,12 @@ import "context"
 
 type contextKeyType int
 
-const routeInfoKey contextKeyType = iota
+const (
+	routeInfoKey contextKeyType = iota
+	admissionTicketKey
+	retriedHostsKey
+	podPickedKey
+)
 
 // routeInfo carries route identity through the middleware chain via a shared
 // pointer in the request context.
@@ -2,13 +2,16 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math/rand/v2"
 	"net/http"
 	"net/url"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/kedacore/http-add-on/interceptor/handler"
@@ -17,6 +20,8 @@ import (
 	"github.com/kedacore/http-add-on/pkg/util"
 )
 
+var errNoWeightedBackend = errors.New("no backend with a positive weight")
+
 type Routing struct {
 	routingTable   routing.Table
 	next           http.Handler
@@ -52,7 +57,18 @@ func (rm *Routing) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		info.Namespace = ir.Namespace
 	}
 
-	url, err := rm.resolveUpstreamURL(r.Context(), ir.Spec.Target.AsServiceRef(), ir.Namespace)
+	target := &ir.Spec.Target
+	if len(ir.Spec.Backends) > 0 {
+		target = pickWeightedBackend(ir.Spec.Backends)
+		if target == nil {
+			sh := handler.NewStatic(http.StatusInternalServerError, errNoWeightedBackend)
+			sh.ServeHTTP(w, r)
+
+			return
+		}
+	}
+
+	url, err := rm.resolveUpstreamURL(r.Context(), target.AsServiceRef(), ir.Namespace)
 	if err != nil {
 		sh := handler.NewStatic(http.StatusInternalServerError, err)
 		sh.ServeHTTP(w, r)
@@ -65,6 +81,11 @@ func (rm *Routing) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	logger := util.LoggerFromContext(ctx)
 	ctx = util.ContextWithLogger(ctx, logger.WithName("RoutingMiddleware"))
 	ctx = util.ContextWithInterceptorRoute(ctx, ir)
+	// Static routes and URL rewrites reuse the expressions compiled by the table.
+	ctx = routing.ContextWithTableMemory(ctx, rm.routingTable.Snapshot())
+	if len(ir.Spec.Backends) > 0 {
+		ctx = util.ContextWithTargetRef(ctx, target)
+	}
 	ctx = util.ContextWithUpstreamURL(ctx, url)
 	// Capture the SNI hostname before EndpointResolver may rewrite the URL to a
 	// pod IP. Empty for non-TLS upstreams.
@@ -73,7 +94,7 @@ func (rm *Routing) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		serverName = url.Hostname()
 	}
 	ctx = util.ContextWithUpstreamServerName(ctx, serverName)
-	portName := rm.resolveUpstreamPortName(ctx, ir.Spec.Target.AsServiceRef(), ir.Namespace)
+	portName := rm.resolveUpstreamPortName(ctx, target.AsServiceRef(), ir.Namespace)
 	ctx = util.ContextWithUpstreamPortName(ctx, portName)
 
 	if ir.Spec.ColdStart != nil && ir.Spec.ColdStart.Fallback != nil && ir.Spec.ColdStart.Fallback.Service != nil {
@@ -87,6 +108,28 @@ func (rm *Routing) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		ctx = util.ContextWithFallbackURL(ctx, fallbackURL)
 	}
 
+	if ir.Spec.ColdStart != nil && len(ir.Spec.ColdStart.Fallbacks) > 0 {
+		fallbackURLs, err := rm.resolveFallbackURLs(ctx, ir.Spec.ColdStart.Fallbacks, ir.Namespace)
+		if err != nil {
+			sh := handler.NewStatic(http.StatusInternalServerError, err)
+			sh.ServeHTTP(w, r)
+			return
+		}
+
+		ctx = util.ContextWithFallbackURLs(ctx, fallbackURLs)
+	}
+
+	if ir.Spec.Mirror != nil {
+		// Mirroring is best effort and must not fail the primary request.
+		mirrorURL, err := rm.resolveUpstreamURL(ctx, ir.Spec.Mirror.AsServiceRef(), ir.Namespace)
+		if err != nil {
+			logger.Error(err, "failed to resolve mirror URL, not mirroring request", "service", ir.Spec.Mirror.Service)
+		} else {
+			ctx = util.ContextWithMirrorURL(ctx, mirrorURL)
+			ctx = util.ContextWithMirrorPortName(ctx, rm.resolveUpstreamPortName(ctx, ir.Spec.Mirror.AsServiceRef(), ir.Namespace))
+		}
+	}
+
 	// Apply per-route or global request deadline
 	requestTimeout := rm.requestTimeout
 	if ir.Spec.Timeouts.Request != nil {
@@ -102,6 +145,27 @@ func (rm *Routing) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	rm.next.ServeHTTP(w, r)
 }
 
+// pickWeightedBackend selects a backend at random, proportional to its weight.
+// Backends without a weight count as weight 1. Returns nil if all weights are 0.
+func pickWeightedBackend(backends []httpv1beta.WeightedBackend) *httpv1beta.TargetRef {
+	var total int64
+	for _, b := range backends {
+		total += int64(ptr.Deref(b.Weight, 1))
+	}
+	if total <= 0 {
+		return nil
+	}
+
+	n := rand.Int64N(total) //nolint:gosec // G404: math/rand is sufficient for traffic splitting
+	for i := range backends {
+		n -= int64(ptr.Deref(backends[i].Weight, 1))
+		if n < 0 {
+			return &backends[i].TargetRef
+		}
+	}
+	return nil
+}
+
 // resolveUpstreamPortName returns the named port for direct-pod routing, or ""
 // when the port is unnamed or the Service lookup fails ("" matches unnamed
 // EndpointSlice ports).
@@ -149,6 +213,23 @@ func (rm *Routing) resolvePort(ctx context.Context, svc httpv1beta.ServiceRef, n
 	return 0, fmt.Errorf("port name %q not found in Service", svc.PortName)
 }
 
+// resolveFallbackURLs returns the URLs of the Services of fallbacks, nil for
+// static responses.
+func (rm *Routing) resolveFallbackURLs(ctx context.Context, fallbacks []httpv1beta.ColdStartFallbackTarget, namespace string) ([]*url.URL, error) {
+	urls := make([]*url.URL, len(fallbacks))
+	for i, fb := range fallbacks {
+		if fb.Service == nil {
+			continue
+		}
+		u, err := rm.resolveUpstreamURL(ctx, *fb.Service, namespace)
+		if err != nil {
+			return nil, err
+		}
+		urls[i] = u
+	}
+	return urls, nil
+}
+
 func (rm *Routing) resolveUpstreamURL(ctx context.Context, svc httpv1beta.ServiceRef, namespace string) (*url.URL, error) {
 	port, err := rm.resolvePort(ctx, svc, namespace)
 	if err != nil {
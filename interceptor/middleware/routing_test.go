@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"fmt"
+	"maps"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -11,6 +12,7 @@ import (
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
@@ -425,3 +427,135 @@ func TestRouting_PopulatesRouteInfo(t *testing.T) {
 		})
 	}
 }
+
+func TestPickWeightedBackend(t *testing.T) {
+	tests := map[string]struct {
+		backends []httpv1beta1.WeightedBackend
+		want     map[string]bool
+	}{
+		"default weight is one": {
+			backends: []httpv1beta1.WeightedBackend{
+				{TargetRef: httpv1beta1.TargetRef{Service: "a"}},
+				{TargetRef: httpv1beta1.TargetRef{Service: "b"}},
+			},
+			want: map[string]bool{"a": true, "b": true},
+		},
+		"zero weight is never picked": {
+			backends: []httpv1beta1.WeightedBackend{
+				{TargetRef: httpv1beta1.TargetRef{Service: "a"}, Weight: ptr.To[int32](0)},
+				{TargetRef: httpv1beta1.TargetRef{Service: "b"}, Weight: ptr.To[int32](5)},
+			},
+			want: map[string]bool{"b": true},
+		},
+		"all weights zero": {
+			backends: []httpv1beta1.WeightedBackend{
+				{TargetRef: httpv1beta1.TargetRef{Service: "a"}, Weight: ptr.To[int32](0)},
+			},
+			want: map[string]bool{},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := map[string]bool{}
+			for range 1000 {
+				if target := pickWeightedBackend(tc.backends); target != nil {
+					got[target.Service] = true
+				}
+			}
+			if !maps.Equal(got, tc.want) {
+				t.Fatalf("picked backends: got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRouting_WeightedBackends(t *testing.T) {
+	ir := &httpv1beta1.InterceptorRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-route",
+			Namespace: "my-ns",
+		},
+		Spec: httpv1beta1.InterceptorRouteSpec{
+			Backends: []httpv1beta1.WeightedBackend{
+				{TargetRef: httpv1beta1.TargetRef{Service: "stable", Port: 8080}, Weight: ptr.To[int32](0)},
+				{TargetRef: httpv1beta1.TargetRef{Service: "canary", Port: 9090}},
+			},
+		},
+	}
+
+	table := routingtest.NewTable()
+	table.Memory["test.example.com"] = ir
+	fakeClient := fake.NewClientBuilder().WithScheme(cache.NewScheme()).Build()
+
+	var (
+		gotURL    string
+		gotTarget *httpv1beta1.TargetRef
+	)
+	inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotURL = util.UpstreamURLFromContext(r.Context()).String()
+		gotTarget = util.TargetRefFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest("GET", "/path", nil)
+	req.Host = "test.example.com"
+	rec := httptest.NewRecorder()
+	NewRouting(inner, table, fakeClient, false, 0).ServeHTTP(rec, req)
+
+	if got, want := rec.Code, http.StatusOK; got != want {
+		t.Fatalf("status: got %d, want %d", got, want)
+	}
+	if got, want := gotURL, "http://canary.my-ns:9090"; got != want {
+		t.Fatalf("upstream URL: got %q, want %q", got, want)
+	}
+	if gotTarget == nil || gotTarget.Service != "canary" {
+		t.Fatalf("target in context: got %v, want canary", gotTarget)
+	}
+}
+
+func TestRouting_ChainedFallbacks(t *testing.T) {
+	ir := &httpv1beta1.InterceptorRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-route",
+			Namespace: "my-ns",
+		},
+		Spec: httpv1beta1.InterceptorRouteSpec{
+			Target: httpv1beta1.TargetRef{Service: "app", Port: 8080},
+			ColdStart: &httpv1beta1.ColdStartSpec{
+				Fallbacks: []httpv1beta1.ColdStartFallbackTarget{
+					{Service: &httpv1beta1.ServiceRef{Name: "warm", Port: 9090}},
+					{Response: &httpv1beta1.StaticResponse{}},
+				},
+			},
+		},
+	}
+
+	table := routingtest.NewTable()
+	table.Memory["test.example.com"] = ir
+	fakeClient := fake.NewClientBuilder().WithScheme(cache.NewScheme()).Build()
+
+	var gotURLs []string
+	inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, u := range util.FallbackURLsFromContext(r.Context()) {
+			if u == nil {
+				gotURLs = append(gotURLs, "")
+				continue
+			}
+			gotURLs = append(gotURLs, u.String())
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest("GET", "/path", nil)
+	req.Host = "test.example.com"
+	rec := httptest.NewRecorder()
+	NewRouting(inner, table, fakeClient, false, 0).ServeHTTP(rec, req)
+
+	if got, want := rec.Code, http.StatusOK; got != want {
+		t.Fatalf("status: got %d, want %d", got, want)
+	}
+	if got, want := fmt.Sprint(gotURLs), "[http://warm.my-ns:9090 ]"; got != want {
+		t.Fatalf("fallback URLs: got %s, want %s", got, want)
+	}
+}
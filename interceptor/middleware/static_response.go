@@ -25,7 +25,25 @@ const (
 
 // serveStaticResponse writes an HTTP response from the StaticResponse spec.
 func serveStaticResponse(w http.ResponseWriter, r *http.Request, reader client.Reader, ir *httpv1beta1.InterceptorRoute, resp *httpv1beta1.StaticResponse, defaultStatusCode int) {
+	serveRenderedResponse(w, r, reader, ir, resp, defaultStatusCode, nil)
+}
+
+// serveRenderedResponse is serveStaticResponse with the body rendered by
+// render, given the Content-Type of the response. A nil render serves the
+// body as is.
+func serveRenderedResponse(
+	w http.ResponseWriter,
+	r *http.Request,
+	reader client.Reader,
+	ir *httpv1beta1.InterceptorRoute,
+	resp *httpv1beta1.StaticResponse,
+	defaultStatusCode int,
+	render func(body, contentType string) (string, error),
+) {
 	body, contentType, err := resolveBody(r, reader, ir, resp)
+	if err == nil && render != nil {
+		body, err = render(body, responseContentType(resp, contentType))
+	}
 	if err != nil {
 		logger := util.LoggerFromContext(r.Context())
 		logger.Error(err, "failed to resolve static response body",
@@ -83,11 +101,7 @@ func resolveBody(r *http.Request, reader client.Reader, ir *httpv1beta1.Intercep
 		return "", "", fmt.Errorf("failed to get ConfigMap %s/%s: %w", ir.Namespace, ref.Name, err)
 	}
 
-	key := ref.Key
-	if key == "" {
-		key = configMapKeyFromPath(r.URL.Path)
-	}
-
+	key := configMapKey(r, ref)
 	val, ok := cm.Data[key]
 	if !ok {
 		// Explicit key miss is an error; path-derived key miss returns an empty body
@@ -102,6 +116,36 @@ func resolveBody(r *http.Request, reader client.Reader, ir *httpv1beta1.Intercep
 	return val, ct, nil
 }
 
+// declaredContentType returns the Content-Type resp is served with for r,
+// explicitly set in its headers or detected from its ConfigMap key, "" if
+// none.
+func declaredContentType(r *http.Request, resp *httpv1beta1.StaticResponse) string {
+	detected := ""
+	if resp.Body == nil && resp.BodyFromConfigMap != nil {
+		detected = mime.TypeByExtension(path.Ext(configMapKey(r, resp.BodyFromConfigMap)))
+	}
+	return responseContentType(resp, detected)
+}
+
+// configMapKey returns the key of the body of r in the ConfigMap of ref.
+func configMapKey(r *http.Request, ref *httpv1beta1.ConfigMapKeyRef) string {
+	if ref.Key != "" {
+		return ref.Key
+	}
+	return configMapKeyFromPath(r.URL.Path)
+}
+
+// responseContentType returns the Content-Type of resp, explicitly set in
+// its headers or else the detected one.
+func responseContentType(resp *httpv1beta1.StaticResponse, detected string) string {
+	for k, v := range resp.Headers {
+		if strings.EqualFold(k, headerContentType) {
+			return v
+		}
+	}
+	return detected
+}
+
 // configMapKeyFromPath derives a ConfigMap key from the request path.
 // ConfigMap keys cannot contain "/", so nested paths won't match.
 func configMapKeyFromPath(urlPath string) string {
@@ -1,7 +1,11 @@
 package middleware
 
 import (
+	"net"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -45,8 +49,20 @@ func (sr *StaticRouting) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if matched.Redirect != nil {
+		// The plain and TLS listeners share static routes: a request already
+		// at the redirect location, e.g. over TLS for an https redirect, is
+		// served normally instead of being redirected to itself.
+		if location := redirectLocation(r, matched.Redirect); location != nil {
+			serveRedirect(w, r, location, matched.Redirect)
+			return
+		}
+		sr.next.ServeHTTP(w, r)
+		return
+	}
+
 	if matched.ResponseMode != httpv1beta1.StaticRouteResponseModeAlways {
-		serviceKey := ir.Namespace + "/" + ir.Spec.Target.Service
+		serviceKey := ir.Namespace + "/" + util.TargetRefFromContext(r.Context()).Service
 		if sr.readyCache.HasReadyEndpoints(serviceKey) {
 			sr.upstream.ServeHTTP(w, r)
 			return
@@ -68,3 +84,87 @@ func matchStaticRoute(r *http.Request, routes []httpv1beta1.StaticRoute) *httpv1
 	}
 	return nil
 }
+
+// redirectLocation returns the location r is redirected to by redirect, or
+// nil if r is already at that location.
+func redirectLocation(r *http.Request, redirect *httpv1beta1.Redirect) *url.URL {
+	requestScheme := "http"
+	if r.TLS != nil {
+		requestScheme = "https"
+	}
+	scheme := requestScheme
+	port := requestPort(r)
+	if redirect.Scheme != "" && redirect.Scheme != scheme {
+		scheme = redirect.Scheme
+		port = ""
+	}
+	if redirect.Port != 0 {
+		port = strconv.Itoa(int(redirect.Port))
+	}
+	if port == "" {
+		port = defaultPort(scheme)
+	}
+
+	hostname := routing.RequestHost(r)
+	if redirect.Hostname != "" {
+		hostname = redirect.Hostname
+	}
+	host := hostname
+	if port != defaultPort(scheme) {
+		host = net.JoinHostPort(hostname, port)
+	}
+
+	location := &url.URL{
+		Scheme:   scheme,
+		Host:     host,
+		Path:     r.URL.Path,
+		RawPath:  r.URL.RawPath,
+		RawQuery: r.URL.RawQuery,
+	}
+	if redirect.Path != nil {
+		location.Path = *redirect.Path
+		location.RawPath = ""
+	}
+	if redirect.StripQuery {
+		location.RawQuery = ""
+	}
+
+	currentPort := requestPort(r)
+	if currentPort == "" {
+		currentPort = defaultPort(requestScheme)
+	}
+	if scheme == requestScheme &&
+		strings.EqualFold(hostname, routing.RequestHost(r)) &&
+		port == currentPort &&
+		location.EscapedPath() == r.URL.EscapedPath() &&
+		location.RawQuery == r.URL.RawQuery {
+		return nil
+	}
+	return location
+}
+
+// serveRedirect redirects the request to location with the status code of
+// redirect.
+func serveRedirect(w http.ResponseWriter, r *http.Request, location *url.URL, redirect *httpv1beta1.Redirect) {
+	statusCode := int(redirect.StatusCode)
+	if statusCode == 0 {
+		statusCode = http.StatusFound
+	}
+	http.Redirect(w, r, location.String(), statusCode)
+}
+
+// requestPort returns the port of the request's Host, or "" if it has none.
+func requestPort(r *http.Request) string {
+	_, port, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		return ""
+	}
+	return port
+}
+
+func defaultPort(scheme string) string {
+	if scheme == "https" {
+		return "443"
+	}
+	return "80"
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"crypto/tls"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -214,6 +215,120 @@ func TestStaticRouting_StaticResponse(t *testing.T) {
 	}
 }
 
+func TestStaticRouting_Redirect(t *testing.T) {
+	tests := map[string]struct {
+		redirect     httpv1beta1.Redirect
+		host         string
+		url          string
+		tls          bool
+		wantCode     int
+		wantLocation string
+		wantNext     bool
+	}{
+		"http to https keeps path and query": {
+			redirect:     httpv1beta1.Redirect{Scheme: "https", StatusCode: http.StatusMovedPermanently},
+			host:         "example.com:8080",
+			url:          "/orders?id=1",
+			wantCode:     http.StatusMovedPermanently,
+			wantLocation: "https://example.com/orders?id=1",
+		},
+		"https with explicit port": {
+			redirect:     httpv1beta1.Redirect{Scheme: "https", Port: 8443},
+			host:         "example.com:8080",
+			url:          "/orders",
+			wantCode:     http.StatusFound,
+			wantLocation: "https://example.com:8443/orders",
+		},
+		"same scheme keeps port": {
+			redirect:     httpv1beta1.Redirect{Hostname: "new.example.com"},
+			host:         "example.com:8080",
+			url:          "/orders",
+			wantCode:     http.StatusFound,
+			wantLocation: "http://new.example.com:8080/orders",
+		},
+		"replace path and strip query": {
+			redirect:     httpv1beta1.Redirect{Path: ptr.To("/v2/orders"), StripQuery: true, StatusCode: http.StatusPermanentRedirect},
+			host:         "example.com",
+			url:          "/v1/orders?id=1",
+			wantCode:     http.StatusPermanentRedirect,
+			wantLocation: "http://example.com/v2/orders",
+		},
+		"default port is omitted": {
+			redirect:     httpv1beta1.Redirect{Port: 80, StatusCode: http.StatusTemporaryRedirect},
+			host:         "example.com:8080",
+			url:          "/",
+			wantCode:     http.StatusTemporaryRedirect,
+			wantLocation: "http://example.com/",
+		},
+		"https request to https redirect is passed through": {
+			redirect: httpv1beta1.Redirect{Scheme: "https", StatusCode: http.StatusMovedPermanently},
+			host:     "example.com",
+			url:      "/orders?id=1",
+			tls:      true,
+			wantNext: true,
+		},
+		"https request on explicit port is passed through": {
+			redirect: httpv1beta1.Redirect{Scheme: "https", Port: 8443},
+			host:     "example.com:8443",
+			url:      "/orders",
+			tls:      true,
+			wantNext: true,
+		},
+		"https request on another port is redirected": {
+			redirect:     httpv1beta1.Redirect{Scheme: "https", Port: 8443},
+			host:         "example.com",
+			url:          "/orders",
+			tls:          true,
+			wantCode:     http.StatusFound,
+			wantLocation: "https://example.com:8443/orders",
+		},
+		"request at redirect path is passed through": {
+			redirect: httpv1beta1.Redirect{Path: ptr.To("/v2/orders")},
+			host:     "example.com",
+			url:      "/v2/orders",
+			wantNext: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			readyCache := k8s.NewReadyEndpointsCache(logr.Discard())
+			addReadyEndpoint(readyCache)
+			ir := staticRouteIR(httpv1beta1.StaticRoute{
+				Rules:    []httpv1beta1.RoutingRule{{}},
+				Redirect: &tc.redirect,
+			})
+
+			var nextCalled bool
+			mw := NewStaticRouting(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { nextCalled = true }),
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { t.Fatal("upstream called") }),
+				readyCache, nil,
+			)
+			rec := httptest.NewRecorder()
+			req := newStaticRouteRequest(t, ir, http.MethodGet, tc.url)
+			req.Host = tc.host
+			if tc.tls {
+				req.TLS = &tls.ConnectionState{}
+			}
+			mw.ServeHTTP(rec, req)
+
+			if nextCalled != tc.wantNext {
+				t.Fatalf("next called = %t, want %t", nextCalled, tc.wantNext)
+			}
+			if tc.wantNext {
+				return
+			}
+			if got := rec.Code; got != tc.wantCode {
+				t.Fatalf("status code = %d, want %d", got, tc.wantCode)
+			}
+			if got := rec.Header().Get("Location"); got != tc.wantLocation {
+				t.Fatalf("Location = %q, want %q", got, tc.wantLocation)
+			}
+		})
+	}
+}
+
 func TestMatchStaticRoute(t *testing.T) {
 	tests := map[string]struct {
 		routes []httpv1beta1.StaticRoute
@@ -11,6 +11,7 @@ import (
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/kedacore/http-add-on/interceptor/circuitbreaker"
 	"github.com/kedacore/http-add-on/interceptor/config"
 	"github.com/kedacore/http-add-on/interceptor/handler"
 	"github.com/kedacore/http-add-on/interceptor/metrics"
@@ -34,6 +35,9 @@ type ProxyHandlerConfig struct {
 	TLSConfig    *tls.Config
 	Tracing      config.Tracing
 	Instruments  *metrics.Instruments
+	// Circuits holds the circuit breakers of the backends. If nil, a new
+	// registry is used.
+	Circuits *circuitbreaker.Registry
 
 	// dialAddressOverride redirects all dial attempts to this address (for testing).
 	// If empty, dials to the original target address.
@@ -85,16 +89,33 @@ func BuildProxyHandler(cfg *ProxyHandlerConfig) http.Handler {
 	// Build handler chain (innermost to outermost)
 	upstream := handler.NewUpstream(baseTransport, cfg.Reader, cfg.Tracing, cfg.Timeouts.ResponseHeader)
 
-	var h http.Handler = middleware.NewEndpointResolver(upstream, cfg.ReadyCache, middleware.EndpointResolverConfig{
+	var h http.Handler = middleware.NewConcurrencyLimit(upstream)
+
+	h = middleware.NewEndpointResolver(h, cfg.ReadyCache, middleware.EndpointResolverConfig{
 		ReadinessTimeout:      cfg.Timeouts.Readiness,
 		EnableColdStartHeader: cfg.Serving.EnableColdStartHeader,
 		DirectPodRouting:      cfg.Serving.DirectPodRouting,
+		Reader:                cfg.Reader,
 	})
 
+	circuits := cfg.Circuits
+	if circuits == nil {
+		circuits = circuitbreaker.NewRegistry(nil)
+	}
+	h = middleware.NewCircuitBreaker(h, upstream, cfg.ReadyCache, circuits, cfg.Reader)
+
+	h = middleware.NewHedging(h, cfg.ReadyCache, cfg.Serving.DirectPodRouting)
+
+	h = middleware.NewRetry(h)
+
+	h = middleware.NewAdmission(h, cfg.ReadyCache)
+
 	h = middleware.NewPlaceholder(h, cfg.ReadyCache, cfg.Reader)
 
 	h = middleware.NewCounting(h, cfg.Queue, cfg.Instruments)
 
+	h = middleware.NewMirror(h, upstream, cfg.ReadyCache, cfg.Queue, cfg.Timeouts.Request)
+
 	h = middleware.NewStaticRouting(h, upstream, cfg.ReadyCache, cfg.Reader)
 
 	h = middleware.NewRouting(
@@ -0,0 +1,219 @@
+// Package standalone runs the interceptor without Kubernetes. Routes,
+// Services, EndpointSlices and ConfigMaps are loaded from the YAML files of a
+// local directory instead of a controller-runtime cache.
+package standalone
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-logr/logr"
+	discov1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	httpv1beta1 "github.com/kedacore/http-add-on/operator/apis/http/v1beta1"
+	kedacache "github.com/kedacore/http-add-on/pkg/cache"
+	"github.com/kedacore/http-add-on/pkg/k8s"
+)
+
+// DefaultNamespace is the namespace of namespaced objects loaded without one.
+const DefaultNamespace = "default"
+
+// reloadDelay coalesces the bursts of events caused by a single edit or an
+// atomic ConfigMap volume update into one reload.
+const reloadDelay = 100 * time.Millisecond
+
+// Source loads the objects of a directory into a Store and feeds the
+// EndpointSlices among them to a ReadyEndpointsCache. Every *.yaml, *.yml and
+// *.json file directly in the directory is loaded; a file may hold several
+// objects separated by "---". Hidden files and subdirectories are ignored.
+type Source struct {
+	*Store
+
+	lggr       logr.Logger
+	dir        string
+	readyCache *k8s.ReadyEndpointsCache
+	decoder    runtime.Decoder
+
+	// services are the keys of the services fed to readyCache by the last load.
+	services map[string]struct{}
+}
+
+// NewSource returns a Source and loads dir once. It fails if dir cannot be
+// loaded.
+func NewSource(lggr logr.Logger, dir string, readyCache *k8s.ReadyEndpointsCache) (*Source, error) {
+	scheme := kedacache.NewScheme()
+	s := &Source{
+		Store:      NewStore(scheme),
+		lggr:       lggr.WithName("standalone"),
+		dir:        dir,
+		readyCache: readyCache,
+		decoder:    serializer.NewCodecFactory(scheme).UniversalDeserializer(),
+	}
+	if err := s.Load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Load reloads the directory. On error the previously loaded objects are
+// kept.
+func (s *Source) Load() error {
+	objs, err := s.readDir()
+	if err != nil {
+		return fmt.Errorf("loading %s: %w", s.dir, err)
+	}
+	if err := s.Replace(objs); err != nil {
+		return fmt.Errorf("loading %s: %w", s.dir, err)
+	}
+	s.updateReadyCache(objs)
+	return nil
+}
+
+// Start watches the directory until ctx is done, reloading it on changes and
+// calling onChange after every successful reload.
+func (s *Source) Start(ctx context.Context, onChange func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("creating watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	if err := watcher.Add(s.dir); err != nil {
+		return fmt.Errorf("watching %s: %w", s.dir, err)
+	}
+
+	timer := time.NewTimer(reloadDelay)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case _, ok := <-watcher.Events:
+			if !ok {
+				return errors.New("watcher closed")
+			}
+			timer.Reset(reloadDelay)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return errors.New("watcher closed")
+			}
+			s.lggr.Error(err, "watching directory", "dir", s.dir)
+		case <-timer.C:
+			if err := s.Load(); err != nil {
+				s.lggr.Error(err, "reloading directory, keeping the previous objects")
+				continue
+			}
+			s.lggr.Info("reloaded directory", "dir", s.dir)
+			onChange()
+		}
+	}
+}
+
+func (s *Source) readDir() ([]client.Object, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var objs []client.Object
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || strings.HasPrefix(name, ".") {
+			continue
+		}
+		switch filepath.Ext(name) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+
+		path := filepath.Join(s.dir, name)
+		fileObjs, err := s.readFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		objs = append(objs, fileObjs...)
+	}
+	return objs, nil
+}
+
+func (s *Source) readFile(path string) ([]client.Object, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: the directory is configured by the operator
+	if err != nil {
+		return nil, err
+	}
+
+	var objs []client.Object
+	reader := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	for i := 0; ; i++ {
+		var raw runtime.RawExtension
+		if err := reader.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return objs, nil
+			}
+			return nil, fmt.Errorf("document %d: %w", i, err)
+		}
+		if len(bytes.TrimSpace(raw.Raw)) == 0 || string(bytes.TrimSpace(raw.Raw)) == "null" {
+			continue
+		}
+
+		decoded, _, err := s.decoder.Decode(raw.Raw, nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("document %d: %w", i, err)
+		}
+		obj, ok := decoded.(client.Object)
+		if !ok {
+			return nil, fmt.Errorf("document %d: unsupported object %T", i, decoded)
+		}
+		if _, clusterScoped := obj.(*httpv1beta1.HostClaim); !clusterScoped && obj.GetNamespace() == "" {
+			obj.SetNamespace(DefaultNamespace)
+		}
+		objs = append(objs, obj)
+	}
+}
+
+// updateReadyCache feeds the EndpointSlices of objs to the ready cache by
+// service, and clears services whose EndpointSlices were removed.
+func (s *Source) updateReadyCache(objs []client.Object) {
+	slices := make(map[string][]*discov1.EndpointSlice)
+	for _, obj := range objs {
+		es, ok := obj.(*discov1.EndpointSlice)
+		if !ok {
+			continue
+		}
+		svcName := es.Labels[discov1.LabelServiceName]
+		if svcName == "" {
+			s.lggr.Info("ignoring EndpointSlice without service label", "name", es.Name, "namespace", es.Namespace, "label", discov1.LabelServiceName)
+			continue
+		}
+		key := k8s.ResourceKey(es.Namespace, svcName)
+		slices[key] = append(slices[key], es)
+	}
+
+	for key := range s.services {
+		if _, ok := slices[key]; !ok {
+			s.readyCache.Update(key, nil)
+		}
+	}
+
+	services := make(map[string]struct{}, len(slices))
+	for key, ess := range slices {
+		s.readyCache.Update(key, ess)
+		services[key] = struct{}{}
+	}
+	s.services = services
+}
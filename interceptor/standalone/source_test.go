@@ -0,0 +1,190 @@
+package standalone
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	discov1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	httpv1beta1 "github.com/kedacore/http-add-on/operator/apis/http/v1beta1"
+	"github.com/kedacore/http-add-on/pkg/k8s"
+)
+
+const testRoutes = `
+apiVersion: http.keda.sh/v1beta1
+kind: InterceptorRoute
+metadata:
+  name: app
+spec:
+  target:
+    service: app
+    port: 8080
+  rules:
+  - hosts: [app.local]
+---
+apiVersion: http.keda.sh/v1beta1
+kind: HostClaim
+metadata:
+  name: local
+spec:
+  hosts: [app.local]
+  namespaces: [default]
+`
+
+const testEndpoints = `
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+spec:
+  ports:
+  - name: http
+    port: 8080
+---
+apiVersion: discovery.k8s.io/v1
+kind: EndpointSlice
+metadata:
+  name: app-local
+  labels:
+    kubernetes.io/service-name: app
+addressType: IPv4
+endpoints:
+- addresses: [127.0.0.1]
+ports:
+- name: http
+  port: 9000
+`
+
+func writeTestFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+		t.Fatalf("writing %s: %v", name, err)
+	}
+}
+
+func TestSourceLoad(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "routes.yaml", testRoutes)
+	writeTestFile(t, dir, "endpoints.yml", testEndpoints)
+	writeTestFile(t, dir, "README.md", "not loaded")
+	writeTestFile(t, dir, ".hidden.yaml", "not: loaded: at all")
+
+	readyCache := k8s.NewReadyEndpointsCache(logr.Discard())
+	src, err := NewSource(logr.Discard(), dir, readyCache)
+	if err != nil {
+		t.Fatalf("NewSource() error = %v", err)
+	}
+	ctx := context.Background()
+
+	var ir httpv1beta1.InterceptorRoute
+	if err := src.Get(ctx, types.NamespacedName{Namespace: DefaultNamespace, Name: "app"}, &ir); err != nil {
+		t.Fatalf("Get(InterceptorRoute) error = %v", err)
+	}
+	if ir.Spec.Target.Service != "app" || ir.Spec.Target.Port != 8080 {
+		t.Errorf("Get(InterceptorRoute) target = %+v", ir.Spec.Target)
+	}
+
+	var claims httpv1beta1.HostClaimList
+	if err := src.List(ctx, &claims); err != nil {
+		t.Fatalf("List(HostClaimList) error = %v", err)
+	}
+	if len(claims.Items) != 1 || claims.Items[0].Namespace != "" {
+		t.Errorf("List(HostClaimList) = %+v, want one cluster-scoped claim", claims.Items)
+	}
+
+	var slices discov1.EndpointSliceList
+	if err := src.List(ctx, &slices, client.InNamespace(DefaultNamespace), client.MatchingLabels{discov1.LabelServiceName: "app"}); err != nil {
+		t.Fatalf("List(EndpointSliceList) error = %v", err)
+	}
+	if len(slices.Items) != 1 {
+		t.Errorf("List(EndpointSliceList) = %d items, want 1", len(slices.Items))
+	}
+	if err := src.List(ctx, &slices, client.InNamespace("other")); err != nil || len(slices.Items) != 0 {
+		t.Errorf("List(EndpointSliceList) in other namespace = %d items, %v, want none", len(slices.Items), err)
+	}
+
+	var svc corev1.Service
+	err = src.Get(ctx, types.NamespacedName{Namespace: DefaultNamespace, Name: "missing"}, &svc)
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("Get(missing Service) error = %v, want NotFound", err)
+	}
+
+	_, host, err := readyCache.WaitForReady(ctx, "default/app", "http")
+	if err != nil || host != "127.0.0.1:9000" {
+		t.Errorf("WaitForReady() = %q, %v, want 127.0.0.1:9000", host, err)
+	}
+}
+
+func TestSourceLoadInvalid(t *testing.T) {
+	tests := map[string]string{
+		"malformed yaml": "kind: [",
+		"unknown kind":   "apiVersion: example.com/v1\nkind: Widget\nmetadata:\n  name: w\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTestFile(t, dir, "bad.yaml", content)
+			if _, err := NewSource(logr.Discard(), dir, k8s.NewReadyEndpointsCache(logr.Discard())); err == nil {
+				t.Error("NewSource() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestSourceStartReloads(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "routes.yaml", testRoutes)
+	writeTestFile(t, dir, "endpoints.yaml", testEndpoints)
+
+	readyCache := k8s.NewReadyEndpointsCache(logr.Discard())
+	src, err := NewSource(logr.Discard(), dir, readyCache)
+	if err != nil {
+		t.Fatalf("NewSource() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changed := make(chan struct{}, 10)
+	go func() { _ = src.Start(ctx, func() { changed <- struct{}{} }) }()
+
+	waitChanged := func() {
+		t.Helper()
+		select {
+		case <-changed:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for reload")
+		}
+	}
+
+	// An invalid file keeps the previous objects and does not signal.
+	time.Sleep(50 * time.Millisecond)
+	writeTestFile(t, dir, "bad.yaml", "kind: [")
+	time.Sleep(3 * reloadDelay)
+	if !readyCache.HasReadyEndpoints("default/app") {
+		t.Error("endpoints removed by an invalid reload")
+	}
+
+	if err := os.Remove(filepath.Join(dir, "bad.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "endpoints.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	waitChanged()
+
+	if readyCache.HasReadyEndpoints("default/app") {
+		t.Error("endpoints of removed EndpointSlice still ready")
+	}
+	var ir httpv1beta1.InterceptorRoute
+	if err := src.Get(ctx, types.NamespacedName{Namespace: DefaultNamespace, Name: "app"}, &ir); err != nil {
+		t.Errorf("Get(InterceptorRoute) after reload error = %v", err)
+	}
+}
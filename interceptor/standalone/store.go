@@ -0,0 +1,103 @@
+package standalone
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// objectSet holds the objects of a snapshot by kind.
+type objectSet map[schema.GroupVersionKind][]client.Object
+
+// Store is a client.Reader serving an immutable snapshot of objects, which
+// is replaced as a whole when the source files change.
+type Store struct {
+	scheme  *runtime.Scheme
+	objects atomic.Pointer[objectSet]
+}
+
+var _ client.Reader = (*Store)(nil)
+
+// NewStore returns an empty Store for the kinds registered in scheme.
+func NewStore(scheme *runtime.Scheme) *Store {
+	s := &Store{scheme: scheme}
+	s.objects.Store(&objectSet{})
+	return s
+}
+
+// Replace atomically replaces the objects served by the store.
+func (s *Store) Replace(objs []client.Object) error {
+	set := make(objectSet)
+	for _, obj := range objs {
+		gvk, err := apiutil.GVKForObject(obj, s.scheme)
+		if err != nil {
+			return err
+		}
+		set[gvk] = append(set[gvk], obj)
+	}
+	s.objects.Store(&set)
+	return nil
+}
+
+func (s *Store) Get(_ context.Context, key client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+	gvk, err := apiutil.GVKForObject(obj, s.scheme)
+	if err != nil {
+		return err
+	}
+
+	for _, stored := range (*s.objects.Load())[gvk] {
+		if stored.GetNamespace() == key.Namespace && stored.GetName() == key.Name {
+			return copyInto(stored, obj)
+		}
+	}
+
+	gr := schema.GroupResource{Group: gvk.Group, Resource: strings.ToLower(gvk.Kind)}
+	return apierrors.NewNotFound(gr, key.Name)
+}
+
+func (s *Store) List(_ context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	listGVK, err := apiutil.GVKForObject(list, s.scheme)
+	if err != nil {
+		return err
+	}
+	gvk := listGVK.GroupVersion().WithKind(strings.TrimSuffix(listGVK.Kind, "List"))
+
+	var lo client.ListOptions
+	lo.ApplyOptions(opts)
+	if lo.FieldSelector != nil && !lo.FieldSelector.Empty() {
+		return fmt.Errorf("field selectors are not supported in standalone mode")
+	}
+
+	var items []runtime.Object
+	for _, stored := range (*s.objects.Load())[gvk] {
+		if lo.Namespace != "" && stored.GetNamespace() != lo.Namespace {
+			continue
+		}
+		if lo.LabelSelector != nil && !lo.LabelSelector.Matches(labels.Set(stored.GetLabels())) {
+			continue
+		}
+		items = append(items, stored.DeepCopyObject())
+	}
+	return apimeta.SetList(list, items)
+}
+
+// copyInto sets the typed object obj to a deep copy of src.
+func copyInto(src, obj client.Object) error {
+	dst := reflect.ValueOf(obj)
+	cp := reflect.ValueOf(src.DeepCopyObject())
+	if dst.Type() != cp.Type() {
+		return fmt.Errorf("cannot copy %T into %T", src, obj)
+	}
+	dst.Elem().Set(cp.Elem())
+	return nil
+}
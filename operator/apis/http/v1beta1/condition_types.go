@@ -4,10 +4,23 @@ package v1beta1
 const (
 	// ConditionTypeReady indicates whether the InterceptorRoute is ready.
 	ConditionTypeReady = "Ready"
+	// ConditionTypeConflicted indicates whether a rule of the InterceptorRoute
+	// is shadowed by another InterceptorRoute claiming the same or a broader match.
+	// Only InterceptorRoutes are compared: routes converted from
+	// HTTPScaledObjects, HTTPRoutes and Ingresses, and hosts refused by HostClaim
+	// ownership, are not reflected.
+	ConditionTypeConflicted = "Conflicted"
 )
 
 // Condition reasons for InterceptorRoute.
 const (
 	// ConditionReasonReconciled indicates reconciliation completed successfully.
 	ConditionReasonReconciled = "Reconciled"
+	// ConditionReasonRouteShadowed indicates a rule is shadowed by another route.
+	ConditionReasonRouteShadowed = "RouteShadowed"
+	// ConditionReasonNoConflict indicates no rule is shadowed by another route.
+	ConditionReasonNoConflict = "NoConflict"
+	// ConditionReasonInvalidSpec indicates the spec is invalid in a way the
+	// CRD validation cannot tell.
+	ConditionReasonInvalidSpec = "InvalidSpec"
 )
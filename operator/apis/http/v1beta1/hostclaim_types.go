@@ -0,0 +1,67 @@
+/*
+Copyright 2026 The KEDA Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// HostClaimSpec defines the hosts claimed and the namespaces owning them.
+type HostClaimSpec struct {
+	// Hostnames claimed. Wildcard patterns (e.g. "*.example.com") claim all
+	// matching hostnames and a single "*" claims every hostname. A hostname is
+	// governed by its most specific claim: the exact hostname first, then the
+	// most specific wildcard, then "*".
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:items:MinLength=1
+	// +listType=set
+	Hosts []string `json:"hosts"`
+	// Namespaces whose InterceptorRoutes may route the claimed hosts.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:items:MinLength=1
+	// +listType=set
+	Namespaces []string `json:"namespaces"`
+}
+
+// HostClaim reserves hostnames for InterceptorRoutes in a set of namespaces.
+// Rules of InterceptorRoutes in any other namespace are not loaded for a
+// claimed hostname.
+//
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Hosts",type="string",JSONPath=".spec.hosts"
+// +kubebuilder:printcolumn:name="Namespaces",type="string",JSONPath=".spec.namespaces"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+type HostClaim struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitzero"`
+
+	Spec HostClaimSpec `json:"spec,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// HostClaimList contains a list of HostClaim.
+type HostClaimList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitzero"`
+	Items           []HostClaim `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&HostClaim{}, &HostClaimList{})
+}
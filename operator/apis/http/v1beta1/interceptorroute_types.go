@@ -20,24 +20,107 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// PathMatchType specifies how a PathMatch value is compared to the request path.
+// +kubebuilder:validation:Enum=Exact;PathPrefix;RegularExpression
+type PathMatchType string
+
+const (
+	// PathMatchExact matches the request path exactly. Leading and trailing
+	// slashes are ignored, so "/foo" and "/foo/" are equivalent.
+	PathMatchExact PathMatchType = "Exact"
+	// PathMatchPathPrefix matches the request path by path segment prefix,
+	// e.g. "/foo" matches "/foo" and "/foo/bar" but not "/foobar".
+	PathMatchPathPrefix PathMatchType = "PathPrefix"
+	// PathMatchRegularExpression matches the request path against an RE2
+	// regular expression. The expression must match the entire path.
+	PathMatchRegularExpression PathMatchType = "RegularExpression"
+)
+
 // PathMatch defines a path matching rule.
 type PathMatch struct {
-	// Path prefix to match against. The longest matching prefix wins.
+	// Type of the path match. When multiple paths match a request, Exact
+	// matches take priority over PathPrefix matches (longest prefix wins),
+	// which take priority over RegularExpression matches (first declared wins).
+	// +optional
+	// +kubebuilder:default=PathPrefix
+	Type PathMatchType `json:"type,omitzero"`
+	// Path, path prefix or regular expression to match against, depending on type.
 	// +kubebuilder:validation:MinLength=1
 	Value string `json:"value"`
 }
 
+// HeaderMatchType specifies how a HeaderMatch value is compared.
+// +kubebuilder:validation:Enum=Exact;Present;Prefix;RegularExpression;NotPresent
+type HeaderMatchType string
+
+const (
+	// HeaderMatchExact matches when any value of the header equals value.
+	HeaderMatchExact HeaderMatchType = "Exact"
+	// HeaderMatchPresent matches when the header is present with any value.
+	HeaderMatchPresent HeaderMatchType = "Present"
+	// HeaderMatchPrefix matches when any value of the header starts with value.
+	HeaderMatchPrefix HeaderMatchType = "Prefix"
+	// HeaderMatchRegularExpression matches when any value of the header fully
+	// matches the RE2 regular expression in value.
+	HeaderMatchRegularExpression HeaderMatchType = "RegularExpression"
+	// HeaderMatchNotPresent matches when the header is absent.
+	HeaderMatchNotPresent HeaderMatchType = "NotPresent"
+)
+
 // HeaderMatch defines a header matching rule.
+// +kubebuilder:validation:XValidation:rule="!has(self.type) || self.type == 'Present' || self.type == 'NotPresent' || has(self.value)",message="'value' is required for Exact, Prefix and RegularExpression matches"
+// +kubebuilder:validation:XValidation:rule="!has(self.type) || (self.type != 'Present' && self.type != 'NotPresent') || !has(self.value)",message="'value' must not be set for Present and NotPresent matches"
 type HeaderMatch struct {
 	// Name of the HTTP header.
 	// +kubebuilder:validation:MinLength=1
 	Name string `json:"name"`
-	// Value to match against (exact match). If omitted, matches any value for given header name.
+	// Type of the match. If omitted, Exact is used when value is set and
+	// Present otherwise.
+	// +optional
+	Type HeaderMatchType `json:"type,omitzero"`
+	// Value, prefix or regular expression to match against, depending on type.
+	// If omitted without a type, matches any value for given header name.
 	// +optional
 	Value *string `json:"value,omitzero"`
 }
 
+// QueryParamMatchType specifies how a QueryParamMatch value is compared.
+// +kubebuilder:validation:Enum=Exact;Present;RegularExpression
+type QueryParamMatchType string
+
+const (
+	// QueryParamMatchExact matches when any value of the parameter equals value.
+	QueryParamMatchExact QueryParamMatchType = "Exact"
+	// QueryParamMatchPresent matches when the parameter is present with any value.
+	QueryParamMatchPresent QueryParamMatchType = "Present"
+	// QueryParamMatchRegularExpression matches when any value of the parameter
+	// fully matches the RE2 regular expression in value.
+	QueryParamMatchRegularExpression QueryParamMatchType = "RegularExpression"
+)
+
+// QueryParamMatch defines a query parameter matching rule.
+// +kubebuilder:validation:XValidation:rule="!has(self.type) || self.type == 'Present' || has(self.value)",message="'value' is required for Exact and RegularExpression matches"
+// +kubebuilder:validation:XValidation:rule="!has(self.type) || self.type != 'Present' || !has(self.value)",message="'value' must not be set for Present matches"
+type QueryParamMatch struct {
+	// Name of the query parameter (case-sensitive).
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Type of the match. If omitted, Exact is used when value is set and
+	// Present otherwise.
+	// +optional
+	Type QueryParamMatchType `json:"type,omitzero"`
+	// Value or regular expression to match against, depending on type.
+	// +optional
+	Value *string `json:"value,omitzero"`
+}
+
+// HTTPMethod is an HTTP request method.
+// +kubebuilder:validation:Enum=GET;HEAD;POST;PUT;DELETE;CONNECT;OPTIONS;TRACE;PATCH
+type HTTPMethod string
+
 // RoutingRule defines a set of matching criteria for routing requests.
+// Regular expressions that are not valid RE2 never match, and set the Ready
+// condition of the InterceptorRoute to false.
 type RoutingRule struct {
 	// Match any of these hostnames. Wildcard patterns (e.g. "*.example.com")
 	// are supported. A single "*" acts as a catch-all. Exact matches take
@@ -46,18 +129,27 @@ type RoutingRule struct {
 	// +optional
 	// +listType=set
 	Hosts []string `json:"hosts,omitzero"`
-	// Match any of these path prefixes. When multiple paths match,
-	// the longest prefix wins.
+	// Match any of these paths. When multiple paths match, exact matches
+	// win over the longest prefix, which wins over regular expressions.
 	// +optional
 	// +listType=atomic
 	Paths []PathMatch `json:"paths,omitzero"`
 	// All listed headers must match the request (AND semantics).
-	// If a header's Value is omitted, the header must be present but any
-	// value is accepted.
+	// If a header's Type and Value are omitted, the header must be present
+	// but any value is accepted.
 	// +optional
 	// +listType=map
 	// +listMapKey=name
 	Headers []HeaderMatch `json:"headers,omitzero"`
+	// Match any of these HTTP methods. If omitted, all methods match.
+	// +optional
+	// +listType=set
+	Methods []HTTPMethod `json:"methods,omitzero"`
+	// All listed query parameters must match the request (AND semantics).
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	QueryParams []QueryParamMatch `json:"queryParams,omitzero"`
 }
 
 // ConcurrencyTargetSpec defines concurrency-based scaling.
@@ -123,6 +215,76 @@ func (t TargetRef) AsServiceRef() ServiceRef {
 	}
 }
 
+// WeightedBackend is a backend Service that receives a share of the route's
+// traffic proportional to its weight.
+type WeightedBackend struct {
+	TargetRef `json:",inline"`
+	// Relative weight of this backend. A backend with weight 0 receives no
+	// traffic. Defaults to 1.
+	// +optional
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=1000000
+	Weight *int32 `json:"weight,omitzero"`
+}
+
+// HeaderModifier sets, adds and removes HTTP headers. Header names are case
+// insensitive. Modifications are applied in the order set, add, remove.
+type HeaderModifier struct {
+	// Headers to set, replacing any existing values.
+	// +optional
+	Set map[string]string `json:"set,omitzero"`
+	// Headers to add, keeping any existing values.
+	// +optional
+	Add map[string]string `json:"add,omitzero"`
+	// Names of headers to remove.
+	// +optional
+	// +listType=set
+	Remove []string `json:"remove,omitzero"`
+}
+
+// URLRewrite modifies the request before it is forwarded to the backend.
+// +kubebuilder:validation:XValidation:rule="!(has(self.replacePrefixMatch) && has(self.replaceFullPath))",message="at most one of 'replacePrefixMatch' or 'replaceFullPath' may be set"
+// +kubebuilder:validation:XValidation:rule="has(self.replacePrefixMatch) || has(self.replaceFullPath) || has(self.hostname)",message="at least one of 'replacePrefixMatch', 'replaceFullPath' or 'hostname' must be set"
+type URLRewrite struct {
+	// Replaces the longest path prefix matched by the route's rules, e.g. with
+	// a "/api/orders" prefix and "/" as replacement "/api/orders/1" is
+	// forwarded as "/1". Rules without paths match the root prefix. Escaped
+	// characters of the rest of the path are kept, and paths whose prefix ends
+	// within an escaped segment, like "/api/orders%2F1", are not rewritten.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^/`
+	ReplacePrefixMatch *string `json:"replacePrefixMatch,omitzero"`
+	// Replaces the full request path.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^/`
+	ReplaceFullPath *string `json:"replaceFullPath,omitzero"`
+	// Host header sent to the backend instead of the client's Host header.
+	// The original host is still passed in X-Forwarded-Host.
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	Hostname *string `json:"hostname,omitzero"`
+}
+
+// MirrorSpec configures shadow traffic sent to a secondary Service. Mirrored
+// requests are sent asynchronously and their responses are discarded.
+type MirrorSpec struct {
+	TargetRef `json:",inline"`
+	// Percentage of requests to mirror. Defaults to 100.
+	// +optional
+	// +kubebuilder:default=100
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	Percent *int32 `json:"percent,omitzero"`
+	// Track concurrency and request rate of mirrored requests so that the
+	// mirror can be scaled, including from zero, by a ScaledObject whose
+	// "backend" scaler metadata is set to the mirror's service. Mirrored
+	// requests never count toward the route's own metrics.
+	// +optional
+	CountRequests bool `json:"countRequests,omitzero"`
+}
+
 // ServiceRef identifies a Kubernetes Service by name and port.
 // Exactly one of port or portName must be set.
 // +kubebuilder:validation:XValidation:rule="has(self.port) != has(self.portName)",message="exactly one of 'port' or 'portName' must be set"
@@ -184,19 +346,88 @@ type ColdStartFallback struct {
 	Service *ServiceRef `json:"service,omitzero"`
 }
 
+// ColdStartFallbackTarget is one step of a chain of cold-start fallbacks:
+// either a Service, used once it has ready endpoints, or a static response
+// ending the chain.
+// +kubebuilder:validation:XValidation:rule="has(self.service) != has(self.response)",message="exactly one of 'service' or 'response' must be set"
+type ColdStartFallbackTarget struct {
+	// Kubernetes Service to use as the fallback target.
+	// +optional
+	Service *ServiceRef `json:"service,omitzero"`
+	// Time to wait for the Service to have ready endpoints before moving on
+	// to the next fallback. Defaults to 5s; 0: the Service is used without
+	// waiting.
+	// +optional
+	ReadinessTimeout *metav1.Duration `json:"readinessTimeout,omitzero"`
+	// Static response to return, ending the chain. Defaults to HTTP 503.
+	// +optional
+	Response *StaticResponse `json:"response,omitzero"`
+}
+
+// ColdStartPlaceholderMode determines how the placeholder response is
+// rendered.
+// +kubebuilder:validation:Enum=Static;Template;AutoRefresh
+type ColdStartPlaceholderMode string
+
+const (
+	// ColdStartPlaceholderModeStatic serves the response as is.
+	ColdStartPlaceholderModeStatic ColdStartPlaceholderMode = "Static"
+	// ColdStartPlaceholderModeTemplate renders the body of the response as a
+	// Go template. Bodies without a Content-Type, from the headers or the
+	// ConfigMap key extension, are HTML: they are escaped as html/template
+	// does and only served to clients accepting text/html, others get a JSON
+	// HTTP 503 body. The Path of JSON bodies is escaped for JSON strings;
+	// bodies of other types get the data as is. The
+	// template data has the fields Name and Namespace of the route, the
+	// request Path, the Elapsed time since the cold start began and the
+	// RetryAfter seconds sent in the Retry-After header. An inline body that
+	// is not a valid template sets the Ready condition of the route to false.
+	ColdStartPlaceholderModeTemplate ColdStartPlaceholderMode = "Template"
+	// ColdStartPlaceholderModeAutoRefresh serves clients accepting text/html
+	// an HTML page refreshing every RetryAfter seconds until the backend is
+	// ready: the body of the response rendered as a template, or a built-in
+	// page without one. Other clients get a JSON HTTP 503 body.
+	ColdStartPlaceholderModeAutoRefresh ColdStartPlaceholderMode = "AutoRefresh"
+)
+
 // ColdStartPlaceholder configures the placeholder behavior during cold start.
+// +kubebuilder:validation:XValidation:rule="has(self.response) || (has(self.mode) && self.mode == 'AutoRefresh')",message="'response' must be set unless 'mode' is AutoRefresh"
 type ColdStartPlaceholder struct {
 	// Static response to return immediately when the backend has no ready endpoints.
+	// +optional
 	Response *StaticResponse `json:"response,omitzero"`
+	// How the response is rendered.
+	// +optional
+	// +kubebuilder:default=Static
+	Mode ColdStartPlaceholderMode `json:"mode,omitzero"`
+	// Longest time clients are told to wait before retrying, in the
+	// Retry-After header, in Template and AutoRefresh modes. Clients are told
+	// to retry sooner when the previous cold start of the backend suggests it
+	// is about to be ready.
+	// +optional
+	// +kubebuilder:default="5s"
+	RetryAfter metav1.Duration `json:"retryAfter,omitzero"`
 }
 
 // ColdStartSpec configures behavior while the target is not ready.
-// +kubebuilder:validation:XValidation:rule="has(self.fallback) || has(self.placeholder)",message="at least one of 'fallback' or 'placeholder' must be set"
+// +kubebuilder:validation:XValidation:rule="has(self.fallback) || has(self.fallbacks) || has(self.placeholder)",message="at least one of 'fallback', 'fallbacks' or 'placeholder' must be set"
+// +kubebuilder:validation:XValidation:rule="!(has(self.fallback) && has(self.fallbacks))",message="at most one of 'fallback' or 'fallbacks' may be set"
+// +kubebuilder:validation:XValidation:rule="!has(self.fallbacks) || self.fallbacks.all(f, !has(f.response)) || (self.fallbacks.filter(f, has(f.response)).size() == 1 && has(self.fallbacks[size(self.fallbacks) - 1].response))",message="only the last of 'fallbacks' may be a static response"
 type ColdStartSpec struct {
 	// Fallback target to route to when the primary backend does not become
 	// ready within the readiness timeout.
 	// +optional
 	Fallback *ColdStartFallback `json:"fallback,omitzero"`
+	// Fallbacks tried in order when the primary backend does not become
+	// ready within the readiness timeout. The request goes to the first
+	// Service with ready endpoints within its own readiness timeout, or gets
+	// the static response ending the chain. Without one, requests fail with
+	// HTTP 504 once every fallback timed out.
+	// +optional
+	// +listType=atomic
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=8
+	Fallbacks []ColdStartFallbackTarget `json:"fallbacks,omitzero"`
 	// Placeholder response to serve while the target has no ready endpoints.
 	// +optional
 	Placeholder *ColdStartPlaceholder `json:"placeholder,omitzero"`
@@ -228,6 +459,222 @@ type InterceptorRouteTimeouts struct {
 	ResponseHeader *metav1.Duration `json:"responseHeader,omitzero"`
 }
 
+// AdmissionSpec bounds the requests of a route held by each interceptor
+// replica. A request is pending while it waits for the backend to have ready
+// endpoints or for a concurrency slot, and concurrent while it is forwarded.
+type AdmissionSpec struct {
+	// Maximum number of requests forwarded to the backend at once. Further
+	// requests wait in the pending queue. Unset: unlimited.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxConcurrentRequests *int32 `json:"maxConcurrentRequests,omitzero"`
+	// Maximum number of pending requests. Further requests are rejected
+	// immediately. Set to 0 to reject every request that would have to wait.
+	// Unset: unlimited.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	MaxPendingRequests *int32 `json:"maxPendingRequests,omitzero"`
+	// HTTP status code of rejected requests.
+	// +optional
+	// +kubebuilder:default=503
+	// +kubebuilder:validation:Enum=429;503
+	RejectStatusCode int32 `json:"rejectStatusCode,omitzero"`
+	// Retry-After of rejected requests, rounded up to whole seconds.
+	// +optional
+	// +kubebuilder:default="1s"
+	RetryAfter metav1.Duration `json:"retryAfter,omitzero"`
+	// Request header carrying the integer priority of a request. Pending
+	// requests with a higher priority get a concurrency slot first, requests
+	// of equal priority in arrival order. Requests without a valid priority
+	// have priority 0.
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	PriorityHeader string `json:"priorityHeader,omitzero"`
+}
+
+// LoadBalancingPolicy selects the ready pod a request is routed to.
+// +kubebuilder:validation:Enum=Random;RoundRobin;LeastRequest;PowerOfTwoChoices;ConsistentHash
+type LoadBalancingPolicy string
+
+const (
+	// LoadBalancingRandom picks a random ready pod.
+	LoadBalancingRandom LoadBalancingPolicy = "Random"
+	// LoadBalancingRoundRobin cycles through the ready pods.
+	LoadBalancingRoundRobin LoadBalancingPolicy = "RoundRobin"
+	// LoadBalancingLeastRequest picks the ready pod with the fewest requests
+	// in flight from this interceptor replica.
+	LoadBalancingLeastRequest LoadBalancingPolicy = "LeastRequest"
+	// LoadBalancingPowerOfTwoChoices picks the pod with fewer requests in
+	// flight from this interceptor replica out of two random ready pods.
+	LoadBalancingPowerOfTwoChoices LoadBalancingPolicy = "PowerOfTwoChoices"
+	// LoadBalancingConsistentHash routes requests with the same hash key to
+	// the same pod, moving as few keys as possible when pods change.
+	LoadBalancingConsistentHash LoadBalancingPolicy = "ConsistentHash"
+)
+
+// HashKey is the part of a request hashed by consistent hashing. Exactly one
+// field must be set.
+// +kubebuilder:validation:XValidation:rule="[has(self.header), has(self.cookie), has(self.sourceIP)].filter(x, x).size() == 1",message="exactly one of 'header', 'cookie' or 'sourceIP' must be set"
+type HashKey struct {
+	// Name of the request header to hash.
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	Header string `json:"header,omitzero"`
+	// Name of the request cookie to hash.
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	Cookie string `json:"cookie,omitzero"`
+	// Hash the client IP address.
+	// +optional
+	SourceIP bool `json:"sourceIP,omitzero"`
+}
+
+// LoadBalancingSpec configures how requests are spread across the ready pods
+// of the backend. Only applies with direct pod routing
+// (KEDA_HTTP_DIRECT_POD_ROUTING); otherwise the Service balances requests.
+// +kubebuilder:validation:XValidation:rule="(has(self.policy) && self.policy == 'ConsistentHash') == has(self.hashKey)",message="'hashKey' must be set if and only if the policy is ConsistentHash"
+type LoadBalancingSpec struct {
+	// Policy selecting the pod of each request.
+	// +optional
+	// +kubebuilder:default=Random
+	Policy LoadBalancingPolicy `json:"policy,omitzero"`
+	// Part of the request hashed by the ConsistentHash policy. Requests
+	// without the hashed header or cookie are routed to a random pod.
+	// +optional
+	HashKey *HashKey `json:"hashKey,omitzero"`
+}
+
+// SessionAffinitySpec routes the requests of a client to the same pod with a
+// cookie naming the pod. Only applies with direct pod routing
+// (KEDA_HTTP_DIRECT_POD_ROUTING). Requests whose pod is no longer ready are
+// routed by the load-balancing policy and get a new cookie.
+type SessionAffinitySpec struct {
+	// Name of the cookie.
+	// +optional
+	// +kubebuilder:default="keda-http-affinity"
+	// +kubebuilder:validation:MinLength=1
+	CookieName string `json:"cookieName,omitzero"`
+	// Lifetime of the cookie. Unset: the cookie expires with the client's
+	// session.
+	// +optional
+	MaxAge *metav1.Duration `json:"maxAge,omitzero"`
+}
+
+// RetryPolicy retries requests the backend failed. Only requests with an
+// idempotent method (GET, HEAD, OPTIONS, TRACE, PUT and DELETE) are retried,
+// unless allowNonIdempotent is set, and only if their body is at most 64KiB as
+// it is buffered for replay. With direct pod routing
+// (KEDA_HTTP_DIRECT_POD_ROUTING), each attempt goes to a pod not tried yet
+// when there is one.
+type RetryPolicy struct {
+	// Maximum number of attempts, including the first one.
+	// +optional
+	// +kubebuilder:default=3
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=10
+	Attempts int32 `json:"attempts,omitzero"`
+	// Backend response status codes retried.
+	// +optional
+	// +kubebuilder:default={502,503,504}
+	// +listType=set
+	// +kubebuilder:validation:items:Minimum=400
+	// +kubebuilder:validation:items:Maximum=599
+	StatusCodes []int32 `json:"statusCodes,omitzero"`
+	// Retry requests whose connection to the backend failed or was reset
+	// before the response headers were received. Unset: true.
+	// +optional
+	OnConnectionFailure *bool `json:"onConnectionFailure,omitzero"`
+	// Time allowed for each attempt to receive the response headers. Attempts
+	// timing out are retried. Unset: attempts are bounded only by the
+	// request and response header timeouts.
+	// +optional
+	PerTryTimeout *metav1.Duration `json:"perTryTimeout,omitzero"`
+	// Wait between attempts.
+	// +optional
+	// +kubebuilder:default={}
+	Backoff RetryBackoff `json:"backoff,omitzero"`
+	// Also retry requests with non-idempotent methods, such as POST and PATCH.
+	// Only safe if the backend handles repeated requests.
+	// +optional
+	AllowNonIdempotent bool `json:"allowNonIdempotent,omitzero"`
+}
+
+// RetryBackoff is an exponential backoff with full jitter: the wait before
+// retry n is random between 0 and baseInterval * 2^(n-1), capped at
+// maxInterval.
+type RetryBackoff struct {
+	// Base of the exponential backoff.
+	// +optional
+	// +kubebuilder:default="25ms"
+	BaseInterval metav1.Duration `json:"baseInterval,omitzero"`
+	// Maximum wait between attempts.
+	// +optional
+	// +kubebuilder:default="250ms"
+	MaxInterval metav1.Duration `json:"maxInterval,omitzero"`
+}
+
+// CircuitBreakerSpec stops forwarding requests to a backend Service failing
+// too many of them. While the circuit is open, requests get the route's
+// cold-start placeholder, go through its cold-start fallback or fallbacks, or
+// are rejected with 503. After coolDown, halfOpenRequests probe requests are
+// forwarded: the circuit closes if they all succeed and opens again
+// otherwise. Connection errors and 5xx responses count as failures. Routes
+// with the same backend Service share its circuit on each interceptor
+// replica, which forgets it after 10 minutes without requests.
+// +kubebuilder:validation:XValidation:rule="has(self.consecutiveFailures) || has(self.failurePercentage)",message="at least one of 'consecutiveFailures' or 'failurePercentage' must be set"
+type CircuitBreakerSpec struct {
+	// Number of failed requests in a row opening the circuit.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	ConsecutiveFailures *int32 `json:"consecutiveFailures,omitzero"`
+	// Percentage of failed requests within interval opening the circuit.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	FailurePercentage *int32 `json:"failurePercentage,omitzero"`
+	// Minimum number of requests within interval for failurePercentage to
+	// apply.
+	// +optional
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=1
+	MinimumRequests int32 `json:"minimumRequests,omitzero"`
+	// Window over which failurePercentage is measured.
+	// +optional
+	// +kubebuilder:default="10s"
+	Interval metav1.Duration `json:"interval,omitzero"`
+	// Time the circuit stays open before probing the backend.
+	// +optional
+	// +kubebuilder:default="30s"
+	CoolDown metav1.Duration `json:"coolDown,omitzero"`
+	// Number of probe requests forwarded while the circuit is half-open.
+	// +optional
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	HalfOpenRequests int32 `json:"halfOpenRequests,omitzero"`
+}
+
+// HedgingPolicy sends a second, hedged request to another ready pod when the
+// response headers of a request are late, returning the response that
+// arrives first and cancelling the other. It requires direct pod routing
+// (KEDA_HTTP_DIRECT_POD_ROUTING) and a second ready pod, and applies to GET
+// and HEAD requests without a body. Both requests count as one for scaling.
+// +kubebuilder:validation:XValidation:rule="!self.enabled || has(self.delay) || has(self.percentile)",message="'delay' or 'percentile' must be set when hedging is enabled"
+type HedgingPolicy struct {
+	// Send hedged requests.
+	// +optional
+	Enabled bool `json:"enabled,omitzero"`
+	// Time to wait for the response headers before hedging. With percentile,
+	// used until enough response times of the route are known.
+	// +optional
+	Delay *metav1.Duration `json:"delay,omitzero"`
+	// Percentile of the recent response header times of the route to wait
+	// for before hedging, e.g. 95. Measured by each interceptor replica.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=99
+	Percentile *int32 `json:"percentile,omitzero"`
+}
+
 // StaticRouteResponseMode determines when the static response is served.
 // +kubebuilder:validation:Enum=Always;WhenUnavailable
 type StaticRouteResponseMode string
@@ -241,17 +688,56 @@ const (
 	StaticRouteResponseModeWhenUnavailable StaticRouteResponseMode = "WhenUnavailable"
 )
 
+// Redirect configures an HTTP redirect. Unset fields keep the corresponding
+// part of the request URL.
+type Redirect struct {
+	// Scheme of the redirect location.
+	// +optional
+	// +kubebuilder:validation:Enum=http;https
+	Scheme string `json:"scheme,omitzero"`
+	// Hostname of the redirect location.
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	Hostname string `json:"hostname,omitzero"`
+	// Port of the redirect location. If omitted, the request port is kept
+	// unless the scheme changes, in which case the scheme's default port is used.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port,omitzero"`
+	// Path of the redirect location, replacing the request path.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^/`
+	Path *string `json:"path,omitzero"`
+	// Drop the request query string instead of keeping it.
+	// +optional
+	StripQuery bool `json:"stripQuery,omitzero"`
+	// HTTP status code of the redirect.
+	// +optional
+	// +kubebuilder:default=302
+	// +kubebuilder:validation:Enum=301;302;307;308
+	StatusCode int32 `json:"statusCode,omitzero"`
+}
+
 // StaticRoute defines a sub-route excluded from scaling metrics.
 // Multiple rules within a StaticRoute use OR semantics.
 // Multiple StaticRoute entries use first-match-wins ordering.
+// +kubebuilder:validation:XValidation:rule="has(self.response) != has(self.redirect)",message="exactly one of 'response' or 'redirect' must be set"
 type StaticRoute struct {
 	// Matching rules for this static route. A request matching any rule
 	// is handled by this static route.
 	// +kubebuilder:validation:MinItems=1
 	// +listType=atomic
 	Rules []RoutingRule `json:"rules"`
-	// Static response configuration.
-	Response StaticResponse `json:"response"`
+	// Static response configuration. Mutually exclusive with redirect.
+	// +optional
+	Response StaticResponse `json:"response,omitzero"`
+	// Redirect returned instead of a static response. Redirects are always
+	// served, regardless of responseMode, except to requests already at the
+	// redirect location, which are forwarded. Mutually exclusive with response.
+	// +optional
+	Redirect *Redirect `json:"redirect,omitzero"`
 	// Determines when the static response is served.
 	// Always: the response is always returned, the backend is never contacted.
 	// WhenUnavailable: the request is forwarded to the backend when it has
@@ -262,15 +748,58 @@ type StaticRoute struct {
 }
 
 // InterceptorRouteSpec defines the desired state of InterceptorRoute.
+// +kubebuilder:validation:XValidation:rule="has(self.target) != has(self.backends)",message="exactly one of 'target' or 'backends' must be set"
+// +kubebuilder:validation:XValidation:rule="!has(self.mirror) || (!has(self.target) || self.target.service != self.mirror.service) && (!has(self.backends) || !self.backends.exists(b, b.service == self.mirror.service))",message="mirror service must not be the target or one of the backends"
 type InterceptorRouteSpec struct {
-	// Backend service to route traffic to.
-	Target TargetRef `json:"target"`
+	// Backend service to route traffic to. Mutually exclusive with backends.
+	// +optional
+	Target TargetRef `json:"target,omitzero"`
+	// Backend services to split traffic across by weight. Concurrency and
+	// request rate are tracked per backend: set the "backend" scaler metadata
+	// of a ScaledObject to the backend's service to scale it on its own share.
+	// Mutually exclusive with target.
+	// +optional
+	// +listType=map
+	// +listMapKey=service
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:XValidation:rule="self.exists(b, !has(b.weight) || b.weight > 0)",message="at least one backend must have a positive weight"
+	Backends []WeightedBackend `json:"backends,omitzero"`
+	// Rewrites the path and Host header of requests forwarded to the backend.
+	// +optional
+	Rewrite *URLRewrite `json:"rewrite,omitzero"`
+	// Modifies the headers of requests forwarded to the backend.
+	// +optional
+	RequestHeaders *HeaderModifier `json:"requestHeaders,omitzero"`
+	// Modifies the headers of responses returned by the backend.
+	// +optional
+	ResponseHeaders *HeaderModifier `json:"responseHeaders,omitzero"`
+	// Secondary service receiving a copy of the route's traffic.
+	// +optional
+	Mirror *MirrorSpec `json:"mirror,omitzero"`
 	// Cold start behavior when scaling from zero.
 	// +optional
 	ColdStart *ColdStartSpec `json:"coldStart,omitzero"`
 	// Timeout configuration for request handling.
 	// +optional
 	Timeouts InterceptorRouteTimeouts `json:"timeouts,omitzero"`
+	// Limits of the requests held and forwarded by each interceptor replica.
+	// +optional
+	Admission *AdmissionSpec `json:"admission,omitzero"`
+	// Spreading of requests across the ready pods of the backend.
+	// +optional
+	LoadBalancing *LoadBalancingSpec `json:"loadBalancing,omitzero"`
+	// Routing of the requests of a client to the same pod.
+	// +optional
+	SessionAffinity *SessionAffinitySpec `json:"sessionAffinity,omitzero"`
+	// Retries of requests the backend failed.
+	// +optional
+	Retries *RetryPolicy `json:"retries,omitzero"`
+	// Circuit breaker failing fast while the backend fails too many requests.
+	// +optional
+	CircuitBreaker *CircuitBreakerSpec `json:"circuitBreaker,omitzero"`
+	// Hedging of requests whose response is late.
+	// +optional
+	Hedging *HedgingPolicy `json:"hedging,omitzero"`
 	// Routing rules that define how requests are matched to this target.
 	// +optional
 	// +listType=atomic
@@ -298,6 +827,7 @@ type InterceptorRouteStatus struct {
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="TargetService",type="string",JSONPath=".spec.target.service"
+// +kubebuilder:printcolumn:name="Backends",type="string",JSONPath=".spec.backends"
 // +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 type InterceptorRoute struct {
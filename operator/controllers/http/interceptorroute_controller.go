@@ -2,29 +2,45 @@ package http
 
 import (
 	"context"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"sync"
+	"text/template"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	httpv1beta1 "github.com/kedacore/http-add-on/operator/apis/http/v1beta1"
+	"github.com/kedacore/http-add-on/pkg/k8s"
+	"github.com/kedacore/http-add-on/pkg/routing"
 )
 
 // InterceptorRouteReconciler reconciles InterceptorRoute objects.
 type InterceptorRouteReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+
+	conflicts conflictCache
 }
 
 // +kubebuilder:rbac:groups=http.keda.sh,resources=interceptorroutes,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=http.keda.sh,resources=interceptorroutes/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=http.keda.sh,resources=interceptorroutes/finalizers,verbs=update
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 func (r *InterceptorRouteReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
@@ -39,21 +55,122 @@ func (r *InterceptorRouteReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		return ctrl.Result{}, err
 	}
 
-	// We only set the Ready condition right now keeping this reconciler basically as a placeholder.
+	var irList httpv1beta1.InterceptorRouteList
+	if err := r.List(ctx, &irList); err != nil {
+		logger.Error(err, "Failed to list InterceptorRoutes")
+		return ctrl.Result{}, err
+	}
+
 	// TODO(v1): decide if we want to keep and extend or remove this reconciler
-	meta.SetStatusCondition(&ir.Status.Conditions, metav1.Condition{
+	meta.SetStatusCondition(&ir.Status.Conditions, readyCondition(&ir))
+
+	conflicted := r.setConflictedCondition(&ir, r.conflicts.get(irList.Items))
+
+	if err := r.Client.Status().Update(ctx, &ir); err != nil {
+		logger.Error(err, "Failed to update status")
+		return ctrl.Result{}, err
+	}
+
+	if conflicted != nil && r.Recorder != nil {
+		r.Recorder.Event(&ir, corev1.EventTypeWarning, httpv1beta1.ConditionReasonRouteShadowed, conflicted.Message)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// readyCondition returns the Ready condition of ir, false if its spec is
+// invalid in a way the CRD validation cannot tell.
+func readyCondition(ir *httpv1beta1.InterceptorRoute) metav1.Condition {
+	cond := metav1.Condition{
 		Type:               httpv1beta1.ConditionTypeReady,
 		Status:             metav1.ConditionTrue,
 		ObservedGeneration: ir.Generation,
 		Reason:             httpv1beta1.ConditionReasonReconciled,
 		Message:            "InterceptorRoute reconciled",
-	})
-	if err := r.Client.Status().Update(ctx, &ir); err != nil {
-		logger.Error(err, "Failed to update status")
-		return ctrl.Result{}, err
 	}
+	if err := validateInterceptorRoute(ir); err != nil {
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = httpv1beta1.ConditionReasonInvalidSpec
+		cond.Message = err.Error()
+	}
+	return cond
+}
 
-	return ctrl.Result{}, nil
+// validateInterceptorRoute checks what the CRD validation of ir cannot: that
+// its regular expressions are valid RE2 expressions, and that the inline body
+// of a templated placeholder parses as a template.
+func validateInterceptorRoute(ir *httpv1beta1.InterceptorRoute) error {
+	for i, rule := range ir.Spec.Rules {
+		if err := routing.ValidateRoutingRule(rule); err != nil {
+			return fmt.Errorf("invalid rules[%d]: %w", i, err)
+		}
+	}
+	for i, sr := range ir.Spec.StaticRoutes {
+		for j, rule := range sr.Rules {
+			if err := routing.ValidateRoutingRule(rule); err != nil {
+				return fmt.Errorf("invalid staticRoutes[%d].rules[%d]: %w", i, j, err)
+			}
+		}
+	}
+	if cs := ir.Spec.ColdStart; cs != nil && cs.Placeholder != nil {
+		ph := cs.Placeholder
+		templated := ph.Mode == httpv1beta1.ColdStartPlaceholderModeTemplate || ph.Mode == httpv1beta1.ColdStartPlaceholderModeAutoRefresh
+		if templated && ph.Response != nil && ph.Response.Body != nil {
+			if _, err := template.New("placeholder").Parse(*ph.Response.Body); err != nil {
+				return fmt.Errorf("invalid coldStart.placeholder.response.body template: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// setConflictedCondition sets the Conflicted condition of ir from conflicts.
+// Returns the condition if ir newly became conflicted, nil otherwise.
+func (r *InterceptorRouteReconciler) setConflictedCondition(ir *httpv1beta1.InterceptorRoute, conflicts map[string]routing.Conflict) *metav1.Condition {
+	cond := conflictedCondition(ir, conflicts)
+	prev := meta.FindStatusCondition(ir.Status.Conditions, httpv1beta1.ConditionTypeConflicted)
+	newlyConflicted := cond.Status == metav1.ConditionTrue && (prev == nil || prev.Status != cond.Status || prev.Message != cond.Message)
+	meta.SetStatusCondition(&ir.Status.Conditions, cond)
+
+	if newlyConflicted {
+		return &cond
+	}
+	return nil
+}
+
+// conflictedCondition returns the Conflicted condition of ir for conflicts.
+func conflictedCondition(ir *httpv1beta1.InterceptorRoute, conflicts map[string]routing.Conflict) metav1.Condition {
+	cond := metav1.Condition{
+		Type:               httpv1beta1.ConditionTypeConflicted,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: ir.Generation,
+		Reason:             httpv1beta1.ConditionReasonNoConflict,
+		Message:            "No rule is shadowed by another InterceptorRoute (other route sources and HostClaims are not checked)",
+	}
+	if c, ok := conflicts[k8s.ResourceKey(ir.Namespace, ir.Name)]; ok {
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = httpv1beta1.ConditionReasonRouteShadowed
+		cond.Message = fmt.Sprintf("Rule for %q is shadowed by InterceptorRoute %s, which receives its traffic", c.Key, c.Winner)
+	}
+	return cond
+}
+
+// enqueueConflictChanges requeues the InterceptorRoutes whose conflicts
+// changed, since a change to one route can create or resolve conflicts with
+// any other.
+func (r *InterceptorRouteReconciler) enqueueConflictChanges(ctx context.Context, _ client.Object) []reconcile.Request {
+	var irList httpv1beta1.InterceptorRouteList
+	if err := r.List(ctx, &irList); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list InterceptorRoutes")
+		return nil
+	}
+
+	var reqs []reconcile.Request
+	for _, key := range r.conflicts.changed(irList.Items) {
+		namespace, name, _ := strings.Cut(key, "/")
+		reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}})
+	}
+	return reqs
 }
 
 func (r *InterceptorRouteReconciler) SetupWithManager(mgr ctrl.Manager) error {
@@ -61,6 +178,80 @@ func (r *InterceptorRouteReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		For(&httpv1beta1.InterceptorRoute{}, builder.WithPredicates(
 			predicate.GenerationChangedPredicate{},
 		)).
+		Watches(
+			&httpv1beta1.InterceptorRoute{},
+			handler.EnqueueRequestsFromMapFunc(r.enqueueConflictChanges),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		).
 		Named("interceptorroute").
 		Complete(r)
 }
+
+// conflictCache memoizes the conflicts between InterceptorRoutes, so that a
+// change computes them once instead of once per reconciled route.
+type conflictCache struct {
+	mu          sync.Mutex
+	fingerprint uint64
+	conflicts   map[string]routing.Conflict
+	// notified are the conflicts routes were last requeued for, nil until
+	// changed is first called.
+	notified map[string]routing.Conflict
+}
+
+// get returns the conflicts between irs, computing them only if irs changed
+// since the last call.
+func (c *conflictCache) get(irs []httpv1beta1.InterceptorRoute) map[string]routing.Conflict {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.getLocked(irs)
+}
+
+func (c *conflictCache) getLocked(irs []httpv1beta1.InterceptorRoute) map[string]routing.Conflict {
+	fingerprint := conflictFingerprint(irs)
+	if c.conflicts == nil || fingerprint != c.fingerprint {
+		c.conflicts = routing.FindConflicts(irs)
+		c.fingerprint = fingerprint
+	}
+	return c.conflicts
+}
+
+// changed returns the namespace/name of the routes whose conflict changed
+// since the last call, or of every route on the first call.
+func (c *conflictCache) changed(irs []httpv1beta1.InterceptorRoute) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	conflicts := c.getLocked(irs)
+	var keys []string
+	if c.notified == nil {
+		for i := range irs {
+			keys = append(keys, k8s.ResourceKey(irs[i].Namespace, irs[i].Name))
+		}
+	} else {
+		for key, conflict := range conflicts {
+			if prev, ok := c.notified[key]; !ok || prev != conflict {
+				keys = append(keys, key)
+			}
+		}
+		for key := range c.notified {
+			if _, ok := conflicts[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+	}
+	c.notified = conflicts
+	return keys
+}
+
+// conflictFingerprint hashes what the conflicts between irs depend on,
+// independently of their order: routes are only compared again once one is
+// created, deleted or has its spec changed.
+func conflictFingerprint(irs []httpv1beta1.InterceptorRoute) uint64 {
+	var sum uint64
+	for i := range irs {
+		h := fnv.New64a()
+		_, _ = fmt.Fprintf(h, "%s/%s/%s/%d", irs[i].Namespace, irs[i].Name, irs[i].UID, irs[i].Generation)
+		sum += h.Sum64()
+	}
+	return sum
+}
@@ -1,13 +1,17 @@
 package http
 
 import (
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
@@ -95,3 +99,232 @@ func TestInterceptorRouteReconcile_SetsReadyCondition(t *testing.T) {
 		t.Errorf("got Ready reason %q, want %q", cond.Reason, httpv1beta1.ConditionReasonReconciled)
 	}
 }
+
+func TestReadyCondition(t *testing.T) {
+	validTemplate := `<p>{{.Path}}</p>`
+	invalidTemplate := `<p>{{.Path</p>`
+
+	invalidRegex := "v("
+
+	tests := map[string]struct {
+		rules        []httpv1beta1.RoutingRule
+		staticRoutes []httpv1beta1.StaticRoute
+		placeholder  *httpv1beta1.ColdStartPlaceholder
+		wantStatus   metav1.ConditionStatus
+		wantReason   string
+	}{
+		"without placeholder": {
+			wantStatus: metav1.ConditionTrue,
+			wantReason: httpv1beta1.ConditionReasonReconciled,
+		},
+		"invalid rule regular expression": {
+			rules: []httpv1beta1.RoutingRule{{
+				Headers: []httpv1beta1.HeaderMatch{{Name: "X-Version", Type: httpv1beta1.HeaderMatchRegularExpression, Value: &invalidRegex}},
+			}},
+			wantStatus: metav1.ConditionFalse,
+			wantReason: httpv1beta1.ConditionReasonInvalidSpec,
+		},
+		"invalid static route regular expression": {
+			staticRoutes: []httpv1beta1.StaticRoute{{Rules: []httpv1beta1.RoutingRule{{
+				Paths: []httpv1beta1.PathMatch{{Type: httpv1beta1.PathMatchRegularExpression, Value: "/(?!admin).*"}},
+			}}}},
+			wantStatus: metav1.ConditionFalse,
+			wantReason: httpv1beta1.ConditionReasonInvalidSpec,
+		},
+		"valid template": {
+			placeholder: &httpv1beta1.ColdStartPlaceholder{
+				Mode:     httpv1beta1.ColdStartPlaceholderModeTemplate,
+				Response: &httpv1beta1.StaticResponse{Body: &validTemplate},
+			},
+			wantStatus: metav1.ConditionTrue,
+			wantReason: httpv1beta1.ConditionReasonReconciled,
+		},
+		"invalid template": {
+			placeholder: &httpv1beta1.ColdStartPlaceholder{
+				Mode:     httpv1beta1.ColdStartPlaceholderModeTemplate,
+				Response: &httpv1beta1.StaticResponse{Body: &invalidTemplate},
+			},
+			wantStatus: metav1.ConditionFalse,
+			wantReason: httpv1beta1.ConditionReasonInvalidSpec,
+		},
+		"invalid auto-refresh template": {
+			placeholder: &httpv1beta1.ColdStartPlaceholder{
+				Mode:     httpv1beta1.ColdStartPlaceholderModeAutoRefresh,
+				Response: &httpv1beta1.StaticResponse{Body: &invalidTemplate},
+			},
+			wantStatus: metav1.ConditionFalse,
+			wantReason: httpv1beta1.ConditionReasonInvalidSpec,
+		},
+		"static body not parsed": {
+			placeholder: &httpv1beta1.ColdStartPlaceholder{
+				Mode:     httpv1beta1.ColdStartPlaceholderModeStatic,
+				Response: &httpv1beta1.StaticResponse{Body: &invalidTemplate},
+			},
+			wantStatus: metav1.ConditionTrue,
+			wantReason: httpv1beta1.ConditionReasonReconciled,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ir := &httpv1beta1.InterceptorRoute{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-route", Generation: 2},
+				Spec: httpv1beta1.InterceptorRouteSpec{
+					Target:       httpv1beta1.TargetRef{Service: "test-service", Port: 8080},
+					Rules:        tt.rules,
+					StaticRoutes: tt.staticRoutes,
+				},
+			}
+			if tt.placeholder != nil {
+				ir.Spec.ColdStart = &httpv1beta1.ColdStartSpec{Placeholder: tt.placeholder}
+			}
+
+			cond := readyCondition(ir)
+			if cond.Status != tt.wantStatus {
+				t.Errorf("got Ready status %s, want %s", cond.Status, tt.wantStatus)
+			}
+			if cond.Reason != tt.wantReason {
+				t.Errorf("got Ready reason %q, want %q", cond.Reason, tt.wantReason)
+			}
+			if cond.ObservedGeneration != ir.Generation {
+				t.Errorf("got observed generation %d, want %d", cond.ObservedGeneration, ir.Generation)
+			}
+		})
+	}
+}
+
+func TestInterceptorRouteReconcile_SetsConflictedCondition(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(httpv1beta1.AddToScheme(scheme))
+
+	newIR := func(name string, created time.Time) *httpv1beta1.InterceptorRoute {
+		return &httpv1beta1.InterceptorRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:         "default",
+				Name:              name,
+				CreationTimestamp: metav1.NewTime(created),
+			},
+			Spec: httpv1beta1.InterceptorRouteSpec{
+				Target: httpv1beta1.TargetRef{
+					Service: name,
+					Port:    8080,
+				},
+				Rules: []httpv1beta1.RoutingRule{{
+					Hosts: []string{"example.com"},
+				}},
+			},
+		}
+	}
+	winner := newIR("winner", time.Now().Add(-time.Hour))
+	loser := newIR("loser", time.Now())
+
+	client := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(winner, loser).
+		WithStatusSubresource(winner, loser).
+		Build()
+
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &InterceptorRouteReconciler{
+		Client:   client,
+		Scheme:   client.Scheme(),
+		Recorder: recorder,
+	}
+
+	tests := map[string]struct {
+		ir         *httpv1beta1.InterceptorRoute
+		wantStatus metav1.ConditionStatus
+		wantReason string
+		wantEvents int
+	}{
+		"winner is not conflicted": {
+			ir:         winner,
+			wantStatus: metav1.ConditionFalse,
+			wantReason: httpv1beta1.ConditionReasonNoConflict,
+		},
+		"loser is conflicted": {
+			ir:         loser,
+			wantStatus: metav1.ConditionTrue,
+			wantReason: httpv1beta1.ConditionReasonRouteShadowed,
+			wantEvents: 1,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			nn := types.NamespacedName{Namespace: tt.ir.Namespace, Name: tt.ir.Name}
+			if _, err := reconciler.Reconcile(t.Context(), ctrl.Request{NamespacedName: nn}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var updated httpv1beta1.InterceptorRoute
+			if err := client.Get(t.Context(), nn, &updated); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			cond := meta.FindStatusCondition(updated.Status.Conditions, httpv1beta1.ConditionTypeConflicted)
+			if cond == nil {
+				t.Fatal("expected Conflicted condition was not found")
+			}
+			if cond.Status != tt.wantStatus {
+				t.Errorf("got Conflicted status %s, want %s", cond.Status, tt.wantStatus)
+			}
+			if cond.Reason != tt.wantReason {
+				t.Errorf("got Conflicted reason %q, want %q", cond.Reason, tt.wantReason)
+			}
+			if tt.wantStatus == metav1.ConditionTrue && !strings.Contains(cond.Message, "default/winner") {
+				t.Errorf("expected Conflicted message to name the winning route, got %q", cond.Message)
+			}
+
+			if got := len(recorder.Events); got != tt.wantEvents {
+				t.Errorf("got %d events, want %d", got, tt.wantEvents)
+			}
+			for range len(recorder.Events) {
+				<-recorder.Events
+			}
+		})
+	}
+}
+
+func TestConflictCache_Changed(t *testing.T) {
+	newIR := func(name, host string, created time.Time) httpv1beta1.InterceptorRoute {
+		return httpv1beta1.InterceptorRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:         "default",
+				Name:              name,
+				Generation:        1,
+				CreationTimestamp: metav1.NewTime(created),
+			},
+			Spec: httpv1beta1.InterceptorRouteSpec{
+				Target: httpv1beta1.TargetRef{Service: name, Port: 8080},
+				Rules:  []httpv1beta1.RoutingRule{{Hosts: []string{host}}},
+			},
+		}
+	}
+	now := time.Now()
+	a := newIR("a", "a.example.com", now.Add(-2*time.Hour))
+	b := newIR("b", "b.example.com", now.Add(-time.Hour))
+	c := newIR("c", "c.example.com", now)
+
+	var cache conflictCache
+	if got := cache.changed([]httpv1beta1.InterceptorRoute{a, b, c}); len(got) != 3 {
+		t.Fatalf("changed on first call = %v, want every route", got)
+	}
+	if got := cache.changed([]httpv1beta1.InterceptorRoute{a, b, c}); len(got) != 0 {
+		t.Fatalf("changed without changes = %v, want none", got)
+	}
+
+	// c now claims the host of a, which shadows it: only c is requeued.
+	c.Spec.Rules[0].Hosts = []string{"a.example.com"}
+	c.Generation++
+	if got := cache.changed([]httpv1beta1.InterceptorRoute{a, b, c}); !slices.Equal(got, []string{"default/c"}) {
+		t.Fatalf("changed after conflict = %v, want [default/c]", got)
+	}
+	if _, ok := cache.get([]httpv1beta1.InterceptorRoute{a, b, c})["default/c"]; !ok {
+		t.Error("expected default/c to be conflicted")
+	}
+
+	// Deleting a resolves the conflict of c.
+	if got := cache.changed([]httpv1beta1.InterceptorRoute{b, c}); !slices.Equal(got, []string{"default/c"}) {
+		t.Fatalf("changed after deletion = %v, want [default/c]", got)
+	}
+}
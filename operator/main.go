@@ -137,8 +137,9 @@ func main() {
 		os.Exit(1)
 	}
 	if err = (&httpcontrollers.InterceptorRouteReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("interceptorroute-controller"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "InterceptorRoute")
 		os.Exit(1)
@@ -6,6 +6,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	discoveryv1 "k8s.io/api/discovery/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	httpv1alpha1 "github.com/kedacore/http-add-on/operator/apis/http/v1alpha1"
 	httpv1beta1 "github.com/kedacore/http-add-on/operator/apis/http/v1beta1"
@@ -32,6 +33,14 @@ func TestNewScheme(t *testing.T) {
 			kind: "InterceptorRoute",
 			obj:  &httpv1beta1.InterceptorRoute{},
 		},
+		"httpv1beta1 HostClaim": {
+			kind: "HostClaim",
+			obj:  &httpv1beta1.HostClaim{},
+		},
+		"gatewayv1": {
+			kind: "HTTPRoute",
+			obj:  &gatewayv1.HTTPRoute{},
+		},
 	}
 
 	for name, tt := range tests {
@@ -13,3 +13,9 @@ func ResourceKeyFromNamespacedName(nn types.NamespacedName) string {
 func ResourceKey(namespace, name string) string {
 	return fmt.Sprintf("%s/%s", namespace, name)
 }
+
+// BackendResourceKey is the queue key of a single weighted backend Service of
+// the resource, so that each backend's traffic can be counted separately.
+func BackendResourceKey(namespace, name, service string) string {
+	return fmt.Sprintf("%s/%s/%s", namespace, name, service)
+}
@@ -0,0 +1,209 @@
+package k8s
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OutlierDetection configures the passive health checking of ready pods:
+// pods failing ConsecutiveFailures requests in a row are ejected from pod
+// selection for a while, even though their EndpointSlice still reports them
+// ready.
+type OutlierDetection struct {
+	// ConsecutiveFailures is the number of failed requests in a row ejecting
+	// a pod. 0 disables outlier detection.
+	ConsecutiveFailures int
+	// BaseEjectionTime is how long a pod is ejected. A pod ejected again
+	// without a successful request in between is ejected for a multiple of
+	// it, up to MaxEjectionTime.
+	BaseEjectionTime time.Duration
+	MaxEjectionTime  time.Duration
+	// MaxEjectionPercent caps the percentage of the pods of a service ejected
+	// at the same time.
+	MaxEjectionPercent int
+	// OnEject, if set, is called for each ejection.
+	OnEject func(serviceKey, host string)
+}
+
+// outlierDetector tracks the consecutive failures and ejections of the pods
+// of each service. Requests to services without failing pods, and picks
+// among pods of services without ejected pods, take no lock.
+type outlierDetector struct {
+	cfg OutlierDetection
+	now func() time.Time
+
+	// "namespace/service" -> *serviceHealth
+	services sync.Map
+}
+
+// serviceHealth is the health of the pods of a service with failures.
+type serviceHealth struct {
+	// tracked is the number of pods in hosts.
+	tracked atomic.Int64
+	// ejectedUntil is when the last ejection of a pod ends, in Unix
+	// nanoseconds.
+	ejectedUntil atomic.Int64
+
+	mu sync.Mutex
+	// "ip:port" -> health of pods with failures
+	hosts map[string]*hostHealth
+}
+
+// hostHealth is the health of a pod that recently failed.
+type hostHealth struct {
+	failures     int
+	ejections    int
+	ejectedUntil time.Time
+}
+
+func newOutlierDetector(cfg OutlierDetection) *outlierDetector {
+	return &outlierDetector{
+		cfg: cfg,
+		now: time.Now,
+	}
+}
+
+// service returns the health of the pods of serviceKey, nil if none failed
+// yet and create is false.
+func (d *outlierDetector) service(serviceKey string, create bool) *serviceHealth {
+	if v, ok := d.services.Load(serviceKey); ok {
+		return v.(*serviceHealth)
+	}
+	if !create {
+		return nil
+	}
+	v, _ := d.services.LoadOrStore(serviceKey, &serviceHealth{hosts: make(map[string]*hostHealth)})
+	return v.(*serviceHealth)
+}
+
+// report records the result of a request to host. total is the number of
+// pods of the service, bounding the ejected pods to MaxEjectionPercent.
+// Returns true if host got ejected.
+func (d *outlierDetector) report(serviceKey, host string, success bool, total int) bool {
+	sh := d.service(serviceKey, !success)
+	if success && (sh == nil || sh.tracked.Load() == 0) {
+		return false
+	}
+
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	defer func() { sh.tracked.Store(int64(len(sh.hosts))) }()
+
+	if success {
+		// Forget pods succeeding after their ejection ended, resetting their
+		// ejection time.
+		if h, ok := sh.hosts[host]; ok && !h.ejectedUntil.After(d.now()) {
+			delete(sh.hosts, host)
+		}
+		return false
+	}
+
+	h, ok := sh.hosts[host]
+	if !ok {
+		h = &hostHealth{}
+		sh.hosts[host] = h
+	}
+
+	now := d.now()
+	if h.ejectedUntil.After(now) {
+		// Picked while ejected, as all pods are.
+		return false
+	}
+	h.failures++
+	if h.failures < d.cfg.ConsecutiveFailures {
+		return false
+	}
+
+	ejected := 0
+	for _, other := range sh.hosts {
+		if other.ejectedUntil.After(now) {
+			ejected++
+		}
+	}
+	if (ejected+1)*100 > d.cfg.MaxEjectionPercent*total {
+		return false
+	}
+
+	h.failures = 0
+	h.ejections++
+	ejection := time.Duration(h.ejections) * d.cfg.BaseEjectionTime
+	if d.cfg.MaxEjectionTime > 0 && ejection > d.cfg.MaxEjectionTime {
+		ejection = d.cfg.MaxEjectionTime
+	}
+	h.ejectedUntil = now.Add(ejection)
+	if until := h.ejectedUntil.UnixNano(); until > sh.ejectedUntil.Load() {
+		sh.ejectedUntil.Store(until)
+	}
+	return true
+}
+
+// healthy returns the hosts not ejected. It returns hosts itself when none
+// is ejected, and nil when all are.
+func (d *outlierDetector) healthy(serviceKey string, hosts []string) []string {
+	if d == nil || len(hosts) == 0 {
+		return hosts
+	}
+
+	sh := d.service(serviceKey, false)
+	if sh == nil {
+		return hosts
+	}
+	now := d.now()
+	if now.UnixNano() >= sh.ejectedUntil.Load() {
+		return hosts
+	}
+
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	var out []string
+	for i, host := range hosts {
+		if h, ok := sh.hosts[host]; ok && h.ejectedUntil.After(now) {
+			if out == nil {
+				out = make([]string, i, len(hosts))
+				copy(out, hosts[:i])
+			}
+			continue
+		}
+		if out != nil {
+			out = append(out, host)
+		}
+	}
+	if out == nil {
+		return hosts
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// retain forgets the pods of serviceKey not in hosts, or the whole service
+// if hosts is nil.
+func (d *outlierDetector) retain(serviceKey string, hosts map[string][]string) {
+	if hosts == nil {
+		d.services.Delete(serviceKey)
+		return
+	}
+	sh := d.service(serviceKey, false)
+	if sh == nil {
+		return
+	}
+
+	current := make(map[string]struct{})
+	for _, hs := range hosts {
+		for _, host := range hs {
+			current[host] = struct{}{}
+		}
+	}
+
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	for host := range sh.hosts {
+		if _, ok := current[host]; !ok {
+			delete(sh.hosts, host)
+		}
+	}
+	sh.tracked.Store(int64(len(sh.hosts)))
+}
@@ -0,0 +1,122 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/require"
+	discov1 "k8s.io/api/discovery/v1"
+	"k8s.io/utils/ptr"
+)
+
+func newTestOutlierDetector(cfg OutlierDetection) (*outlierDetector, *time.Time) {
+	now := time.Unix(0, 0)
+	d := newOutlierDetector(cfg)
+	d.now = func() time.Time { return now }
+	return d, &now
+}
+
+func TestOutlierDetector_EjectsAfterConsecutiveFailures(t *testing.T) {
+	r := require.New(t)
+	d, now := newTestOutlierDetector(OutlierDetection{
+		ConsecutiveFailures: 3,
+		BaseEjectionTime:    10 * time.Second,
+		MaxEjectionPercent:  100,
+	})
+	const key = "ns/svc"
+	hosts := []string{"a:80", "b:80"}
+
+	r.False(d.report(key, "a:80", false, 2))
+	r.False(d.report(key, "a:80", false, 2))
+	r.False(d.report(key, "a:80", true, 2), "a success resets the failures")
+	r.False(d.report(key, "a:80", false, 2))
+	r.False(d.report(key, "a:80", false, 2))
+	r.True(d.report(key, "a:80", false, 2))
+	r.Equal([]string{"b:80"}, d.healthy(key, hosts))
+
+	*now = now.Add(10 * time.Second)
+	r.Equal(hosts, d.healthy(key, hosts), "the ejection ends after the base ejection time")
+}
+
+func TestOutlierDetector_EjectionTimeGrows(t *testing.T) {
+	r := require.New(t)
+	d, now := newTestOutlierDetector(OutlierDetection{
+		ConsecutiveFailures: 1,
+		BaseEjectionTime:    10 * time.Second,
+		MaxEjectionTime:     25 * time.Second,
+		MaxEjectionPercent:  100,
+	})
+	const key = "ns/svc"
+	hosts := []string{"a:80"}
+
+	for _, want := range []time.Duration{10 * time.Second, 20 * time.Second, 25 * time.Second} {
+		r.True(d.report(key, "a:80", false, 1))
+		*now = now.Add(want - time.Second)
+		r.Nil(d.healthy(key, hosts))
+		*now = now.Add(time.Second)
+		r.Equal(hosts, d.healthy(key, hosts))
+	}
+
+	// A success after the ejection forgets the pod.
+	r.False(d.report(key, "a:80", true, 1))
+	r.True(d.report(key, "a:80", false, 1))
+	*now = now.Add(10 * time.Second)
+	r.Equal(hosts, d.healthy(key, hosts))
+}
+
+func TestOutlierDetector_MaxEjectionPercent(t *testing.T) {
+	r := require.New(t)
+	d, _ := newTestOutlierDetector(OutlierDetection{
+		ConsecutiveFailures: 1,
+		BaseEjectionTime:    time.Minute,
+		MaxEjectionPercent:  50,
+	})
+	const key = "ns/svc"
+
+	r.False(d.report(key, "a:80", false, 1), "the only pod must not be ejected")
+	r.True(d.report(key, "a:80", false, 4))
+	r.True(d.report(key, "b:80", false, 4))
+	r.False(d.report(key, "c:80", false, 4), "at most half the pods are ejected")
+	r.Equal([]string{"c:80", "d:80"}, d.healthy(key, []string{"a:80", "b:80", "c:80", "d:80"}))
+}
+
+func TestReadyEndpointsCache_OutlierDetection(t *testing.T) {
+	r := require.New(t)
+	c := NewReadyEndpointsCache(logr.Discard())
+	var ejected []string
+	c.SetOutlierDetection(OutlierDetection{
+		ConsecutiveFailures: 1,
+		BaseEjectionTime:    time.Minute,
+		MaxEjectionPercent:  100,
+		OnEject:             func(serviceKey, host string) { ejected = append(ejected, serviceKey+" "+host) },
+	})
+	const key = "testns/testsvc"
+	slice := func(addresses ...string) *discov1.EndpointSlice {
+		sl := newReadySlice("testns", "testsvc", addresses...)
+		sl.Ports = []discov1.EndpointPort{{Port: ptr.To(int32(8080))}}
+		return sl
+	}
+	c.Update(key, []*discov1.EndpointSlice{slice("1.2.3.4", "5.6.7.8")})
+
+	c.ReportResult(key, "1.2.3.4:8080", false)
+	r.Equal([]string{"testns/testsvc 1.2.3.4:8080"}, ejected)
+	r.Equal([]string{"5.6.7.8:8080"}, c.ReadyHosts(key, ""))
+	for range 10 {
+		_, host, err := c.WaitForReady(context.Background(), key, "")
+		r.NoError(err)
+		r.Equal("5.6.7.8:8080", host)
+	}
+
+	// With every pod ejected, pods are picked anyway.
+	c.ReportResult(key, "5.6.7.8:8080", false)
+	r.Equal([]string{"1.2.3.4:8080", "5.6.7.8:8080"}, c.ReadyHosts(key, ""))
+	_, host, err := c.WaitForReady(context.Background(), key, "")
+	r.NoError(err)
+	r.NotEmpty(host)
+
+	// Pods leaving the EndpointSlice are forgotten.
+	c.Update(key, []*discov1.EndpointSlice{slice("5.6.7.8")})
+	r.NotContains(c.outliers.service(key, false).hosts, "1.2.3.4:8080")
+}
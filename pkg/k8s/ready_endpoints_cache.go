@@ -5,21 +5,34 @@ import (
 	"fmt"
 	"math/rand/v2"
 	"net"
+	"sort"
 	"strconv"
 	"sync"
 
 	"github.com/go-logr/logr"
 	discov1 "k8s.io/api/discovery/v1"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/ptr"
 	ctrlcache "sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // endpoint is one ready pod: its address paired with the container port from
-// the same EndpointSlice.
+// the same EndpointSlice, and the pod's topology.
 type endpoint struct {
 	ip   string
 	port int32
+
+	zone     string
+	nodeName string
+	// hintZones are the zones the EndpointSlice controller hinted the pod for.
+	hintZones []string
+}
+
+// endpointAddr identifies an endpoint for deduplication.
+type endpointAddr struct {
+	ip   string
+	port int32
 }
 
 // serviceState is an immutable snapshot of a service's ready pods, swapped
@@ -28,8 +41,18 @@ type serviceState struct {
 	ready bool // true if the service has at least one ready endpoint
 	// candidates maps portName ("" for unnamed) to its ready pods.
 	candidates map[string][]endpoint
+	// hosts maps portName to the sorted "ip:port" hosts of its candidates.
+	hosts map[string][]string
+	// zoneHosts maps portName and zone to the sorted hosts of the candidates
+	// in, or hinted for, the zone.
+	zoneHosts map[string]map[string][]string
 }
 
+// HostPicker selects one of the sorted, non-empty "ip:port" hosts of a
+// service port. It must not modify hosts. It may return "" to reject all of
+// hosts, moving on to the pods of other zones.
+type HostPicker func(hosts []string) string
+
 func (s *serviceState) hasReady() bool { return s != nil && s.ready }
 
 // ReadyEndpointsCache maintains a derived map of service -> serviceState
@@ -41,6 +64,11 @@ type ReadyEndpointsCache struct {
 	// "namespace/service" -> *serviceState
 	states sync.Map
 
+	// zone is the zone of this interceptor replica, "" if unknown.
+	zone string
+	// outliers tracks failing pods, nil if outlier detection is disabled.
+	outliers *outlierDetector
+
 	// Broadcast mechanism: the channel is closed on any change,
 	// then replaced with a fresh one. Waiters select on the channel.
 	mu       sync.Mutex
@@ -55,6 +83,48 @@ func NewReadyEndpointsCache(lggr logr.Logger) *ReadyEndpointsCache {
 	}
 }
 
+// SetLocalZone makes pod selection prefer the ready pods in zone, falling
+// back to pods in other zones when zone has none. It must be called before
+// the cache is used.
+func (c *ReadyEndpointsCache) SetLocalZone(zone string) {
+	c.zone = zone
+}
+
+// SetOutlierDetection ejects pods failing cfg.ConsecutiveFailures requests
+// in a row, as reported by ReportResult, from pod selection. It must be called
+// before the cache is used.
+func (c *ReadyEndpointsCache) SetOutlierDetection(cfg OutlierDetection) {
+	if cfg.ConsecutiveFailures <= 0 {
+		c.outliers = nil
+		return
+	}
+	c.outliers = newOutlierDetector(cfg)
+}
+
+// ReportResult records whether a request to the pod host of serviceKey
+// succeeded, for outlier detection.
+func (c *ReadyEndpointsCache) ReportResult(serviceKey, host string, success bool) {
+	if c.outliers == nil {
+		return
+	}
+
+	v, ok := c.states.Load(serviceKey)
+	if !ok {
+		return
+	}
+	total := 0
+	for _, hosts := range v.(*serviceState).hosts {
+		total = max(total, len(hosts))
+	}
+
+	if c.outliers.report(serviceKey, host, success, total) {
+		c.lggr.Info("ejecting failing endpoint", "key", serviceKey, "host", host)
+		if c.outliers.cfg.OnEject != nil {
+			c.outliers.cfg.OnEject(serviceKey, host)
+		}
+	}
+}
+
 // HasReadyEndpoints returns true if the service has at least one ready endpoint.
 // This is the fast hot-path check (one atomic load).
 func (c *ReadyEndpointsCache) HasReadyEndpoints(serviceKey string) bool {
@@ -64,6 +134,21 @@ func (c *ReadyEndpointsCache) HasReadyEndpoints(serviceKey string) bool {
 	return false
 }
 
+// ReadyHosts returns the sorted "ip:port" hosts of the ready pods of
+// serviceKey for portName, skipping ejected pods unless all are ejected.
+// The returned slice must not be modified.
+func (c *ReadyEndpointsCache) ReadyHosts(serviceKey, portName string) []string {
+	v, ok := c.states.Load(serviceKey)
+	if !ok {
+		return nil
+	}
+	hosts := v.(*serviceState).hosts[portName]
+	if healthy := c.outliers.healthy(serviceKey, hosts); len(healthy) > 0 {
+		return healthy
+	}
+	return hosts
+}
+
 // WaitForReady waits until the service has at least one ready endpoint or
 // the context is cancelled/timed out.
 //
@@ -72,12 +157,18 @@ func (c *ReadyEndpointsCache) HasReadyEndpoints(serviceKey string) bool {
 //   - (true, podHost, nil)   — cold start, backend became ready
 //   - (false, "", error)     — context cancelled or timed out
 //
-// podHost is "ip:port" for portName, or "" when portName has no candidates
-// (signals direct-pod routing isn't possible).
+// podHost is a random "ip:port" for portName, or "" when portName has no
+// candidates (signals direct-pod routing isn't possible).
 func (c *ReadyEndpointsCache) WaitForReady(ctx context.Context, serviceKey, portName string) (isColdStart bool, podHost string, err error) {
+	return c.WaitForReadyWithPicker(ctx, serviceKey, portName, nil)
+}
+
+// WaitForReadyWithPicker is WaitForReady with podHost selected by pick. A nil
+// pick selects a random host.
+func (c *ReadyEndpointsCache) WaitForReadyWithPicker(ctx context.Context, serviceKey, portName string, pick HostPicker) (isColdStart bool, podHost string, err error) {
 	if v, ok := c.states.Load(serviceKey); ok {
 		if state := v.(*serviceState); state.hasReady() {
-			return false, pickHost(state, portName), nil
+			return false, c.pickHost(serviceKey, state, portName, pick), nil
 		}
 	}
 
@@ -91,7 +182,7 @@ func (c *ReadyEndpointsCache) WaitForReady(ctx context.Context, serviceKey, port
 	// is still the warm/fast path.
 	if v, ok := c.states.Load(serviceKey); ok {
 		if state := v.(*serviceState); state.hasReady() {
-			return false, pickHost(state, portName), nil
+			return false, c.pickHost(serviceKey, state, portName, pick), nil
 		}
 	}
 
@@ -109,7 +200,7 @@ func (c *ReadyEndpointsCache) WaitForReady(ctx context.Context, serviceKey, port
 			if v, ok := c.states.Load(serviceKey); ok {
 				if state := v.(*serviceState); state.hasReady() {
 					c.lggr.Info("cold-start: endpoints became ready", "key", serviceKey)
-					return true, pickHost(state, portName), nil
+					return true, c.pickHost(serviceKey, state, portName, pick), nil
 				}
 			}
 			// Not our service — get the new channel and re-check
@@ -121,28 +212,55 @@ func (c *ReadyEndpointsCache) WaitForReady(ctx context.Context, serviceKey, port
 			if v, ok := c.states.Load(serviceKey); ok {
 				if state := v.(*serviceState); state.hasReady() {
 					c.lggr.Info("cold-start: endpoints became ready", "key", serviceKey)
-					return true, pickHost(state, portName), nil
+					return true, c.pickHost(serviceKey, state, portName, pick), nil
 				}
 			}
 		}
 	}
 }
 
-// pickHost selects a random ready pod for portName from state and returns its
-// "ip:port" host string. Returns "" if portName has no candidates.
-func pickHost(state *serviceState, portName string) string {
-	eps := state.candidates[portName]
-	if len(eps) == 0 {
+// pickHost selects a ready pod for portName from state with pick, or a random
+// one if pick is nil, and returns its "ip:port" host string. Pods in the local
+// zone are preferred when there are any, and ejected pods are skipped unless
+// all are ejected. Returns "" if portName has no candidates.
+func (c *ReadyEndpointsCache) pickHost(serviceKey string, state *serviceState, portName string, pick HostPicker) string {
+	hosts := state.hosts[portName]
+	if len(hosts) == 0 {
 		return ""
 	}
-	ep := eps[rand.IntN(len(eps))] //nolint:gosec // G404: math/rand is sufficient for load-balancing endpoint selection
-	return net.JoinHostPort(ep.ip, strconv.Itoa(int(ep.port)))
+	// Prefer the healthy pods of the local zone, then any healthy pod.
+	for _, candidates := range [][]string{state.zoneHosts[portName][c.zone], hosts} {
+		candidates = c.outliers.healthy(serviceKey, candidates)
+		if len(candidates) == 0 {
+			continue
+		}
+		if pick == nil {
+			return randomHost(candidates)
+		}
+		if host := pick(candidates); host != "" {
+			return host
+		}
+	}
+	// Every pod is ejected or rejected by pick.
+	if pick != nil {
+		if host := pick(hosts); host != "" {
+			return host
+		}
+	}
+	return randomHost(hosts)
+}
+
+func randomHost(hosts []string) string {
+	return hosts[rand.IntN(len(hosts))] //nolint:gosec // G404: math/rand is sufficient for load-balancing endpoint selection
 }
 
 // Update checks the given EndpointSlices, builds a new serviceState snapshot,
 // and atomically replaces the old one. serviceKey is "namespace/service".
 func (c *ReadyEndpointsCache) Update(serviceKey string, endpointSlices []*discov1.EndpointSlice) {
 	if len(endpointSlices) == 0 {
+		if c.outliers != nil {
+			c.outliers.retain(serviceKey, nil)
+		}
 		if v, ok := c.states.LoadAndDelete(serviceKey); ok {
 			if v.(*serviceState).hasReady() {
 				c.broadcast()
@@ -152,6 +270,9 @@ func (c *ReadyEndpointsCache) Update(serviceKey string, endpointSlices []*discov
 	}
 
 	newState := collectServiceState(endpointSlices)
+	if c.outliers != nil {
+		c.outliers.retain(serviceKey, newState.hosts)
+	}
 	old, loaded := c.states.Swap(serviceKey, newState)
 
 	var oldState *serviceState
@@ -179,7 +300,7 @@ func (c *ReadyEndpointsCache) broadcast() {
 // ("" for unnamed). Address families are not distinguished.
 func collectServiceState(slices []*discov1.EndpointSlice) *serviceState {
 	// Dedup (ip, port) per portName so overlapping slices don't weight a pod twice.
-	seen := make(map[string]map[endpoint]struct{})
+	seen := make(map[string]map[endpointAddr]struct{})
 	candidates := make(map[string][]endpoint)
 	anyReady := false
 
@@ -201,43 +322,102 @@ func collectServiceState(slices []*discov1.EndpointSlice) *serviceState {
 			slicePorts = append(slicePorts, slicePort{name, *p.Port})
 		}
 
-		// Collect this slice's ready pod IPs (the canonical address per pod).
-		var readyIPs []string
+		// Collect this slice's ready pods (the canonical address per pod).
+		var readyPods []endpoint
 		for i := range sl.Endpoints {
 			ep := &sl.Endpoints[i]
 			// Kubernetes guarantees that Ready is false for terminating pods, so a
 			// separate Terminating check is unnecessary. For services with
 			// publishNotReadyAddresses, Ready is always true — we respect that.
 			if (ep.Conditions.Ready == nil || *ep.Conditions.Ready) && len(ep.Addresses) > 0 {
-				readyIPs = append(readyIPs, ep.Addresses[0])
+				readyPods = append(readyPods, readyPod(ep))
 				anyReady = true
 			}
 		}
 
-		if len(readyIPs) == 0 || len(slicePorts) == 0 {
+		if len(readyPods) == 0 || len(slicePorts) == 0 {
 			continue
 		}
 
 		// Cross-product: pair every ready pod with every port from this slice.
 		for _, sp := range slicePorts {
 			if seen[sp.name] == nil {
-				seen[sp.name] = make(map[endpoint]struct{})
+				seen[sp.name] = make(map[endpointAddr]struct{})
 			}
-			for _, ip := range readyIPs {
-				k := endpoint{ip: ip, port: sp.port}
+			for _, pod := range readyPods {
+				k := endpointAddr{ip: pod.ip, port: sp.port}
 				if _, dup := seen[sp.name][k]; dup {
 					continue
 				}
 				seen[sp.name][k] = struct{}{}
-				candidates[sp.name] = append(candidates[sp.name], k)
+				pod.port = sp.port
+				candidates[sp.name] = append(candidates[sp.name], pod)
 			}
 		}
 	}
 
+	hosts := make(map[string][]string, len(candidates))
+	zoneHosts := make(map[string]map[string][]string, len(candidates))
+	for name, eps := range candidates {
+		h := make([]string, 0, len(eps))
+		for _, ep := range eps {
+			h = append(h, net.JoinHostPort(ep.ip, strconv.Itoa(int(ep.port))))
+		}
+		sort.Strings(h)
+		hosts[name] = h
+		zoneHosts[name] = hostsByZone(eps)
+	}
+
 	return &serviceState{
 		ready:      anyReady,
 		candidates: candidates,
+		hosts:      hosts,
+		zoneHosts:  zoneHosts,
+	}
+}
+
+// readyPod returns the endpoint of a ready pod, without its port.
+func readyPod(ep *discov1.Endpoint) endpoint {
+	pod := endpoint{
+		ip:       ep.Addresses[0],
+		zone:     ptr.Deref(ep.Zone, ""),
+		nodeName: ptr.Deref(ep.NodeName, ""),
+	}
+	if ep.Hints != nil {
+		for _, z := range ep.Hints.ForZones {
+			pod.hintZones = append(pod.hintZones, z.Name)
+		}
+	}
+	return pod
+}
+
+// hostsByZone groups the sorted hosts of eps by zone. As with kube-proxy's
+// topology-aware routing, zone hints are followed only when every endpoint
+// has them; otherwise endpoints are grouped by their own zone.
+func hostsByZone(eps []endpoint) map[string][]string {
+	useHints := true
+	for _, ep := range eps {
+		if len(ep.hintZones) == 0 {
+			useHints = false
+			break
+		}
+	}
+
+	byZone := make(map[string][]string)
+	for _, ep := range eps {
+		host := net.JoinHostPort(ep.ip, strconv.Itoa(int(ep.port)))
+		if useHints {
+			for _, z := range ep.hintZones {
+				byZone[z] = append(byZone[z], host)
+			}
+		} else if ep.zone != "" {
+			byZone[ep.zone] = append(byZone[ep.zone], host)
+		}
+	}
+	for _, h := range byZone {
+		sort.Strings(h)
 	}
+	return byZone
 }
 
 // updateReadyCache updates the ready cache for the service that owns
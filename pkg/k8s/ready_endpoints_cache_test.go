@@ -136,6 +136,109 @@ func TestWaitForReady_ReturnsPodHost(t *testing.T) {
 	r.Equal("1.2.3.4:8080", podHost)
 }
 
+func TestWaitForReadyWithPicker_SortedHosts(t *testing.T) {
+	r := require.New(t)
+	c := NewReadyEndpointsCache(logr.Discard())
+	const key = "testns/testsvc"
+
+	c.Update(key, []*discov1.EndpointSlice{
+		{
+			AddressType: discov1.AddressTypeIPv4,
+			Ports:       []discov1.EndpointPort{{Port: ptr.To(int32(8080))}},
+			Endpoints: []discov1.Endpoint{
+				{Addresses: []string{"5.6.7.8"}},
+				{Addresses: []string{"1.2.3.4"}},
+			},
+		},
+	})
+
+	var got []string
+	_, podHost, err := c.WaitForReadyWithPicker(context.Background(), key, "", func(hosts []string) string {
+		got = hosts
+		return hosts[len(hosts)-1]
+	})
+	r.NoError(err)
+	r.Equal([]string{"1.2.3.4:8080", "5.6.7.8:8080"}, got)
+	r.Equal("5.6.7.8:8080", podHost)
+}
+
+func TestWaitForReadyWithPicker_PrefersLocalZone(t *testing.T) {
+	const key = "testns/testsvc"
+	zonedSlice := func(zones map[string]string) *discov1.EndpointSlice {
+		sl := &discov1.EndpointSlice{
+			AddressType: discov1.AddressTypeIPv4,
+			Ports:       []discov1.EndpointPort{{Port: ptr.To(int32(8080))}},
+		}
+		for addr, zone := range zones {
+			sl.Endpoints = append(sl.Endpoints, discov1.Endpoint{Addresses: []string{addr}, Zone: ptr.To(zone)})
+		}
+		return sl
+	}
+
+	tests := map[string]struct {
+		localZone string
+		slice     *discov1.EndpointSlice
+		wantHosts []string
+	}{
+		"same zone": {
+			localZone: "zone-a",
+			slice:     zonedSlice(map[string]string{"1.2.3.4": "zone-a", "5.6.7.8": "zone-b", "9.9.9.9": "zone-a"}),
+			wantHosts: []string{"1.2.3.4:8080", "9.9.9.9:8080"},
+		},
+		"falls back to other zones": {
+			localZone: "zone-c",
+			slice:     zonedSlice(map[string]string{"1.2.3.4": "zone-a", "5.6.7.8": "zone-b"}),
+			wantHosts: []string{"1.2.3.4:8080", "5.6.7.8:8080"},
+		},
+		"unknown local zone": {
+			localZone: "",
+			slice:     zonedSlice(map[string]string{"1.2.3.4": "zone-a", "5.6.7.8": "zone-b"}),
+			wantHosts: []string{"1.2.3.4:8080", "5.6.7.8:8080"},
+		},
+		"hints": {
+			localZone: "zone-b",
+			slice: &discov1.EndpointSlice{
+				AddressType: discov1.AddressTypeIPv4,
+				Ports:       []discov1.EndpointPort{{Port: ptr.To(int32(8080))}},
+				Endpoints: []discov1.Endpoint{
+					{Addresses: []string{"1.2.3.4"}, Zone: ptr.To("zone-a"), Hints: &discov1.EndpointHints{ForZones: []discov1.ForZone{{Name: "zone-b"}}}},
+					{Addresses: []string{"5.6.7.8"}, Zone: ptr.To("zone-b"), Hints: &discov1.EndpointHints{ForZones: []discov1.ForZone{{Name: "zone-a"}}}},
+				},
+			},
+			wantHosts: []string{"1.2.3.4:8080"},
+		},
+		"hints ignored unless on every endpoint": {
+			localZone: "zone-b",
+			slice: &discov1.EndpointSlice{
+				AddressType: discov1.AddressTypeIPv4,
+				Ports:       []discov1.EndpointPort{{Port: ptr.To(int32(8080))}},
+				Endpoints: []discov1.Endpoint{
+					{Addresses: []string{"1.2.3.4"}, Zone: ptr.To("zone-a"), Hints: &discov1.EndpointHints{ForZones: []discov1.ForZone{{Name: "zone-b"}}}},
+					{Addresses: []string{"5.6.7.8"}, Zone: ptr.To("zone-b")},
+				},
+			},
+			wantHosts: []string{"5.6.7.8:8080"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := require.New(t)
+			c := NewReadyEndpointsCache(logr.Discard())
+			c.SetLocalZone(tt.localZone)
+			c.Update(key, []*discov1.EndpointSlice{tt.slice})
+
+			var got []string
+			_, _, err := c.WaitForReadyWithPicker(context.Background(), key, "", func(hosts []string) string {
+				got = hosts
+				return hosts[0]
+			})
+			r.NoError(err)
+			r.Equal(tt.wantHosts, got)
+		})
+	}
+}
+
 func TestWaitForReady_NamedPortSelectsCorrectHost(t *testing.T) {
 	r := require.New(t)
 	c := NewReadyEndpointsCache(logr.Discard())
@@ -449,6 +552,31 @@ func TestCollectServiceState_SkipsEndpointWithNoAddresses(t *testing.T) {
 
 // --- Update tests ---
 
+func TestCollectServiceState_RetainsTopology(t *testing.T) {
+	r := require.New(t)
+	s := collectServiceState([]*discov1.EndpointSlice{
+		{
+			AddressType: discov1.AddressTypeIPv4,
+			Ports:       []discov1.EndpointPort{{Port: ptr.To(int32(8080))}},
+			Endpoints: []discov1.Endpoint{
+				{
+					Addresses: []string{"1.2.3.4"},
+					Zone:      ptr.To("zone-a"),
+					NodeName:  ptr.To("node-1"),
+					Hints:     &discov1.EndpointHints{ForZones: []discov1.ForZone{{Name: "zone-a"}, {Name: "zone-b"}}},
+				},
+			},
+		},
+	})
+	r.Equal([]endpoint{{
+		ip:        "1.2.3.4",
+		port:      8080,
+		zone:      "zone-a",
+		nodeName:  "node-1",
+		hintZones: []string{"zone-a", "zone-b"},
+	}}, s.candidates[""])
+}
+
 func TestUpdate_ClearsStateOnEmptySlices(t *testing.T) {
 	r := require.New(t)
 	c := NewReadyEndpointsCache(logr.Discard())
@@ -15,6 +15,15 @@ const (
 	ScalerAddressKey    = "scalerAddress"
 	HTTPScaledObjectKey = "httpScaledObject"
 	InterceptorRouteKey = "interceptorRoute"
+	// InterceptorRouteBackendKey selects a single weighted backend Service of
+	// the InterceptorRoute to report metrics for.
+	InterceptorRouteBackendKey = "backend"
+	// HTTPRouteKey references a Gateway API HTTPRoute to report metrics for,
+	// as an alternative to InterceptorRouteKey.
+	HTTPRouteKey = "httpRoute"
+	// IngressKey references an Ingress to report metrics for, as an
+	// alternative to InterceptorRouteKey.
+	IngressKey = "ingress"
 )
 
 // NewScaledObject creates a new ScaledObject in memory
@@ -0,0 +1,133 @@
+package routing
+
+import (
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	httpv1beta1 "github.com/kedacore/http-add-on/operator/apis/http/v1beta1"
+	"github.com/kedacore/http-add-on/pkg/k8s"
+)
+
+// Conflict describes a routing rule that never receives traffic because
+// another route claims the same host and path, and matches at least every
+// request it matches, e.g. with the same or fewer conditions.
+type Conflict struct {
+	// Winner is the route receiving the traffic, as namespace/name.
+	Winner string
+	// Key is the claimed host/path key, or the hostname for regex paths.
+	Key string
+}
+
+// FindConflicts returns the first conflict of every shadowed route, keyed by
+// namespace/name. The winner of a conflict is the route Route would pick
+// among irs alone: the routes of other sources and HostClaim ownership, which
+// the Table also applies, are not taken into account.
+func FindConflicts(irs []httpv1beta1.InterceptorRoute) map[string]Conflict {
+	tm := NewTableMemory()
+	for i := range irs {
+		tm = tm.Remember(&irs[i])
+	}
+
+	conflicts := make(map[string]Conflict)
+	for _, s := range []*store{tm.exact, tm.store, tm.regex} {
+		s.Root().Walk(func(k []byte, entries []routeEntry) bool {
+			for j, loser := range entries {
+				loserKey := k8s.ResourceKey(loser.ir.Namespace, loser.ir.Name)
+				if _, found := conflicts[loserKey]; found {
+					continue
+				}
+				for _, winner := range entries[:j] {
+					if winner.ir.Namespace == loser.ir.Namespace && winner.ir.Name == loser.ir.Name {
+						continue
+					}
+					if shadows(winner, loser) {
+						conflicts[loserKey] = Conflict{
+							Winner: k8s.ResourceKey(winner.ir.Namespace, winner.ir.Name),
+							Key:    string(k),
+						}
+						break
+					}
+				}
+			}
+			return false
+		})
+	}
+	return conflicts
+}
+
+// shadows reports whether winner matches every request loser matches for
+// their key, so that loser, evaluated after it, never matches. Regular
+// expressions are only known to match the same requests if they are equal.
+func shadows(winner, loser routeEntry) bool {
+	if (winner.path == nil) != (loser.path == nil) || (winner.path != nil && winner.path.String() != loser.path.String()) {
+		return false
+	}
+	if len(winner.methods) > 0 && (len(loser.methods) == 0 || !isSubset(loser.methods, winner.methods)) {
+		return false
+	}
+	for _, w := range winner.headers {
+		if !slices.ContainsFunc(loser.headers, func(l httpv1beta1.HeaderMatch) bool {
+			return http.CanonicalHeaderKey(l.Name) == http.CanonicalHeaderKey(w.Name) && headerImplies(l, w)
+		}) {
+			return false
+		}
+	}
+	for _, w := range winner.query {
+		if !slices.ContainsFunc(loser.query, func(l httpv1beta1.QueryParamMatch) bool {
+			return l.Name == w.Name && queryImplies(l, w)
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+func isSubset[T comparable](sub, set []T) bool {
+	for _, v := range sub {
+		if !slices.Contains(set, v) {
+			return false
+		}
+	}
+	return true
+}
+
+// headerImplies reports whether every request matching l, a matcher of the
+// same header as w, matches w.
+func headerImplies(l, w httpv1beta1.HeaderMatch) bool {
+	lt, wt := headerMatchType(l), headerMatchType(w)
+	if lt == httpv1beta1.HeaderMatchNotPresent || wt == httpv1beta1.HeaderMatchNotPresent {
+		return lt == wt
+	}
+	switch wt {
+	case httpv1beta1.HeaderMatchPresent:
+		return true
+	case httpv1beta1.HeaderMatchPrefix:
+		return (lt == httpv1beta1.HeaderMatchExact || lt == httpv1beta1.HeaderMatchPrefix) && strings.HasPrefix(*l.Value, *w.Value)
+	case httpv1beta1.HeaderMatchRegularExpression:
+		return (lt == wt && *l.Value == *w.Value) || (lt == httpv1beta1.HeaderMatchExact && matchesRegex(*w.Value, *l.Value))
+	default:
+		return lt == wt && *l.Value == *w.Value
+	}
+}
+
+// queryImplies reports whether every request matching l, a matcher of the
+// same query parameter as w, matches w.
+func queryImplies(l, w httpv1beta1.QueryParamMatch) bool {
+	lt, wt := queryParamMatchType(l), queryParamMatchType(w)
+	switch wt {
+	case httpv1beta1.QueryParamMatchPresent:
+		return true
+	case httpv1beta1.QueryParamMatchRegularExpression:
+		return (lt == wt && *l.Value == *w.Value) || (lt == httpv1beta1.QueryParamMatchExact && matchesRegex(*w.Value, *l.Value))
+	default:
+		return lt == wt && *l.Value == *w.Value
+	}
+}
+
+// matchesRegex reports whether pattern fully matches value.
+func matchesRegex(pattern, value string) bool {
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	return err == nil && re.MatchString(value)
+}
@@ -0,0 +1,169 @@
+package routing
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	httpv1beta1 "github.com/kedacore/http-add-on/operator/apis/http/v1beta1"
+)
+
+func TestFindConflicts(t *testing.T) {
+	older := metav1.NewTime(time.Now().Add(-time.Hour))
+	newer := metav1.NewTime(time.Now())
+
+	newIR := func(name string, created metav1.Time, rules ...httpv1beta1.RoutingRule) httpv1beta1.InterceptorRoute {
+		return httpv1beta1.InterceptorRoute{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, CreationTimestamp: created},
+			Spec:       httpv1beta1.InterceptorRouteSpec{Rules: rules},
+		}
+	}
+	apiRule := httpv1beta1.RoutingRule{
+		Hosts: []string{"example.com"},
+		Paths: []httpv1beta1.PathMatch{{Value: "/api"}},
+	}
+	headerRule := func(headers ...httpv1beta1.HeaderMatch) httpv1beta1.RoutingRule {
+		r := apiRule
+		r.Headers = headers
+		return r
+	}
+	methodRule := func(methods ...httpv1beta1.HTTPMethod) httpv1beta1.RoutingRule {
+		r := apiRule
+		r.Methods = methods
+		return r
+	}
+	queryRule := func(query ...httpv1beta1.QueryParamMatch) httpv1beta1.RoutingRule {
+		r := apiRule
+		r.QueryParams = query
+		return r
+	}
+
+	tests := map[string]struct {
+		irs  []httpv1beta1.InterceptorRoute
+		want map[string]Conflict
+	}{
+		"same host and path": {
+			irs: []httpv1beta1.InterceptorRoute{
+				newIR("new", newer, apiRule),
+				newIR("old", older, apiRule),
+			},
+			want: map[string]Conflict{
+				"default/new": {Winner: "default/old", Key: "example.com/api/"},
+			},
+		},
+		"same headers in different order": {
+			irs: []httpv1beta1.InterceptorRoute{
+				newIR("old", older, headerRule(
+					httpv1beta1.HeaderMatch{Name: "x-a", Value: ptr.To("1")},
+					httpv1beta1.HeaderMatch{Name: "X-B", Type: httpv1beta1.HeaderMatchPresent},
+				)),
+				newIR("new", newer, headerRule(
+					httpv1beta1.HeaderMatch{Name: "X-B"},
+					httpv1beta1.HeaderMatch{Name: "X-A", Type: httpv1beta1.HeaderMatchExact, Value: ptr.To("1")},
+				)),
+			},
+			want: map[string]Conflict{
+				"default/new": {Winner: "default/old", Key: "example.com/api/"},
+			},
+		},
+		"different headers do not conflict": {
+			irs: []httpv1beta1.InterceptorRoute{
+				newIR("old", older, apiRule),
+				newIR("new", newer, headerRule(httpv1beta1.HeaderMatch{Name: "X-Canary", Value: ptr.To("true")})),
+			},
+			want: map[string]Conflict{},
+		},
+		"different path types do not conflict": {
+			irs: []httpv1beta1.InterceptorRoute{
+				newIR("old", older, apiRule),
+				newIR("new", newer, httpv1beta1.RoutingRule{
+					Hosts: []string{"example.com"},
+					Paths: []httpv1beta1.PathMatch{{Value: "/api", Type: httpv1beta1.PathMatchExact}},
+				}),
+			},
+			want: map[string]Conflict{},
+		},
+		"same regular expression": {
+			irs: []httpv1beta1.InterceptorRoute{
+				newIR("old", older, httpv1beta1.RoutingRule{
+					Hosts: []string{"example.com"},
+					Paths: []httpv1beta1.PathMatch{{Value: "/api/v[0-9]+", Type: httpv1beta1.PathMatchRegularExpression}},
+				}),
+				newIR("new", newer, httpv1beta1.RoutingRule{
+					Hosts: []string{"example.com"},
+					Paths: []httpv1beta1.PathMatch{{Value: "/api/v[0-9]+", Type: httpv1beta1.PathMatchRegularExpression}},
+				}),
+			},
+			want: map[string]Conflict{
+				"default/new": {Winner: "default/old", Key: "example.com"},
+			},
+		},
+		"subset of the methods": {
+			irs: []httpv1beta1.InterceptorRoute{
+				newIR("old", older, methodRule(http.MethodGet, http.MethodPost)),
+				newIR("new", newer, methodRule(http.MethodPost)),
+			},
+			want: map[string]Conflict{
+				"default/new": {Winner: "default/old", Key: "example.com/api/"},
+			},
+		},
+		"superset of the methods does not conflict": {
+			irs: []httpv1beta1.InterceptorRoute{
+				newIR("old", older, methodRule(http.MethodGet)),
+				newIR("new", newer, methodRule(http.MethodGet, http.MethodPost)),
+			},
+			want: map[string]Conflict{},
+		},
+		"query parameter value of a present parameter": {
+			irs: []httpv1beta1.InterceptorRoute{
+				newIR("old", older, queryRule(httpv1beta1.QueryParamMatch{Name: "version"})),
+				newIR("new", newer, queryRule(httpv1beta1.QueryParamMatch{Name: "version", Value: ptr.To("2")})),
+			},
+			want: map[string]Conflict{
+				"default/new": {Winner: "default/old", Key: "example.com/api/"},
+			},
+		},
+		"query parameter value matching a regular expression": {
+			irs: []httpv1beta1.InterceptorRoute{
+				newIR("old", older, queryRule(httpv1beta1.QueryParamMatch{Name: "version", Type: httpv1beta1.QueryParamMatchRegularExpression, Value: ptr.To("[0-9]+")})),
+				newIR("new", newer, queryRule(httpv1beta1.QueryParamMatch{Name: "version", Value: ptr.To("2")})),
+			},
+			want: map[string]Conflict{
+				"default/new": {Winner: "default/old", Key: "example.com/api/"},
+			},
+		},
+		"query parameter value not matching a regular expression does not conflict": {
+			irs: []httpv1beta1.InterceptorRoute{
+				newIR("old", older, queryRule(httpv1beta1.QueryParamMatch{Name: "version", Type: httpv1beta1.QueryParamMatchRegularExpression, Value: ptr.To("[0-9]+")})),
+				newIR("new", newer, queryRule(httpv1beta1.QueryParamMatch{Name: "version", Value: ptr.To("beta")})),
+			},
+			want: map[string]Conflict{},
+		},
+		"newer superset does not conflict": {
+			irs: []httpv1beta1.InterceptorRoute{
+				newIR("old", older, queryRule(httpv1beta1.QueryParamMatch{Name: "version", Value: ptr.To("2")})),
+				newIR("new", newer, queryRule(httpv1beta1.QueryParamMatch{Name: "version"})),
+			},
+			want: map[string]Conflict{},
+		},
+		"overlapping rules within a route do not conflict": {
+			irs: []httpv1beta1.InterceptorRoute{
+				newIR("old", older, apiRule, apiRule),
+			},
+			want: map[string]Conflict{},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := FindConflicts(tt.irs)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindConflicts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
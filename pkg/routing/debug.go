@@ -0,0 +1,174 @@
+package routing
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-logr/logr"
+
+	httpv1beta1 "github.com/kedacore/http-add-on/operator/apis/http/v1beta1"
+	"github.com/kedacore/http-add-on/pkg/k8s"
+)
+
+const (
+	debugRoutesPath = "/debug/routes"
+	debugMatchPath  = "/debug/match"
+)
+
+// EntryKind identifies the routing table store holding an entry.
+type EntryKind string
+
+const (
+	EntryKindExact  EntryKind = "exact"
+	EntryKindPrefix EntryKind = "prefix"
+	EntryKindRegex  EntryKind = "regex"
+)
+
+// RouteEntryInfo describes a routing table entry.
+type RouteEntryInfo struct {
+	Kind EntryKind `json:"kind"`
+	// Key is the host/path key, or the hostname for regex entries.
+	Key string `json:"key"`
+	// Order is the evaluation order of the entry within its key.
+	Order       int                           `json:"order"`
+	Route       string                        `json:"route"`
+	Path        string                        `json:"path,omitempty"`
+	Methods     []httpv1beta1.HTTPMethod      `json:"methods,omitempty"`
+	Headers     []httpv1beta1.HeaderMatch     `json:"headers,omitempty"`
+	QueryParams []httpv1beta1.QueryParamMatch `json:"queryParams,omitempty"`
+}
+
+// MatchStep records a routing table entry considered while routing a request.
+type MatchStep struct {
+	Kind  EntryKind `json:"kind"`
+	Key   string    `json:"key"`
+	Route string    `json:"route"`
+	// Reason is empty for the matching entry and explains why any other
+	// entry was skipped.
+	Reason string `json:"reason,omitempty"`
+}
+
+// MatchResult explains how a request was routed.
+type MatchResult struct {
+	// Route is the matched InterceptorRoute as namespace/name, empty if none matched.
+	Route string      `json:"route"`
+	Steps []MatchStep `json:"steps"`
+}
+
+// Entries returns all entries of the table in evaluation order per key.
+func (tm *TableMemory) Entries() []RouteEntryInfo {
+	infos := []RouteEntryInfo{}
+	for _, s := range []struct {
+		kind  EntryKind
+		store *store
+	}{
+		{EntryKindExact, tm.exact},
+		{EntryKindPrefix, tm.store},
+		{EntryKindRegex, tm.regex},
+	} {
+		s.store.Root().Walk(func(k []byte, entries []routeEntry) bool {
+			for i, e := range entries {
+				info := RouteEntryInfo{
+					Kind:        s.kind,
+					Key:         string(k),
+					Order:       i,
+					Route:       k8s.ResourceKey(e.ir.Namespace, e.ir.Name),
+					Methods:     e.methods,
+					Headers:     e.headers,
+					QueryParams: e.query,
+				}
+				if e.path != nil {
+					info.Path = e.path.String()
+				}
+				infos = append(infos, info)
+			}
+			return false
+		})
+	}
+	return infos
+}
+
+// Explain routes like Route and records every entry considered on the way.
+func (tm *TableMemory) Explain(hostname, path, method string, query url.Values, headers http.Header) MatchResult {
+	res := MatchResult{Steps: []MatchStep{}}
+	if ir := tm.route(hostname, path, method, query, headers, &res.Steps); ir != nil {
+		res.Route = k8s.ResourceKey(ir.Namespace, ir.Name)
+	}
+	return res
+}
+
+// traceStep returns a callback appending the entries considered for key to
+// trace, or nil if trace is nil.
+func traceStep(trace *[]MatchStep, kind EntryKind, key []byte) func(routeEntry, string) {
+	if trace == nil {
+		return nil
+	}
+	return func(e routeEntry, reason string) {
+		*trace = append(*trace, MatchStep{
+			Kind:   kind,
+			Key:    string(key),
+			Route:  k8s.ResourceKey(e.ir.Namespace, e.ir.Name),
+			Reason: reason,
+		})
+	}
+}
+
+// AddDebugRoutes registers the routing debug endpoints on mux:
+// /debug/routes dumps the table entries and /debug/match?host=&path= explains
+// which route a request would be routed to. The optional method, query and
+// repeatable header ("Name: value") parameters describe the request further.
+func AddDebugRoutes(lggr logr.Logger, mux *http.ServeMux, table Table) {
+	lggr = lggr.WithName("pkg.routing.AddDebugRoutes")
+	lggr.Info("adding routing debug routes", "paths", []string{debugRoutesPath, debugMatchPath})
+
+	mux.HandleFunc(debugRoutesPath, func(w http.ResponseWriter, _ *http.Request) {
+		tm := table.Snapshot()
+		if tm == nil {
+			http.Error(w, errNotSyncedTable.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		writeDebugJSON(lggr, w, tm.Entries())
+	})
+
+	mux.HandleFunc(debugMatchPath, func(w http.ResponseWriter, r *http.Request) {
+		tm := table.Snapshot()
+		if tm == nil {
+			http.Error(w, errNotSyncedTable.Error(), http.StatusServiceUnavailable)
+			return
+		}
+
+		params := r.URL.Query()
+		method := params.Get("method")
+		if method == "" {
+			method = http.MethodGet
+		}
+		query, err := url.ParseQuery(params.Get("query"))
+		if err != nil {
+			http.Error(w, "invalid query: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		headers := http.Header{}
+		for _, h := range params["header"] {
+			name, value, ok := strings.Cut(h, ":")
+			if !ok {
+				http.Error(w, `invalid header, expected "Name: value": `+h, http.StatusBadRequest)
+				return
+			}
+			headers.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+		}
+
+		res := tm.Explain(StripPort(params.Get("host")), params.Get("path"), method, query, headers)
+		writeDebugJSON(lggr, w, res)
+	})
+}
+
+func writeDebugJSON(lggr logr.Logger, w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		lggr.Error(err, "encoding debug response")
+	}
+}
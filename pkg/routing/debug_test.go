@@ -0,0 +1,165 @@
+package routing
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	httpv1beta1 "github.com/kedacore/http-add-on/operator/apis/http/v1beta1"
+)
+
+func newDebugTestMemory() *TableMemory {
+	return NewTableMemory().
+		Remember(&httpv1beta1.InterceptorRoute{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "api"},
+			Spec: httpv1beta1.InterceptorRouteSpec{
+				Rules: []httpv1beta1.RoutingRule{{
+					Hosts: []string{"example.com"},
+					Paths: []httpv1beta1.PathMatch{{Value: "/api"}},
+				}},
+			},
+		}).
+		Remember(&httpv1beta1.InterceptorRoute{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "canary"},
+			Spec: httpv1beta1.InterceptorRouteSpec{
+				Rules: []httpv1beta1.RoutingRule{{
+					Hosts:   []string{"example.com"},
+					Paths:   []httpv1beta1.PathMatch{{Value: "/api"}},
+					Headers: []httpv1beta1.HeaderMatch{{Name: "X-Canary", Value: ptr.To("true")}},
+				}},
+			},
+		})
+}
+
+func TestTableMemoryEntries(t *testing.T) {
+	entries := newDebugTestMemory().Entries()
+
+	want := []RouteEntryInfo{
+		{
+			Kind:    EntryKindPrefix,
+			Key:     "example.com/api/",
+			Order:   0,
+			Route:   "default/canary",
+			Headers: []httpv1beta1.HeaderMatch{{Name: "X-Canary", Value: ptr.To("true")}},
+		},
+		{
+			Kind:  EntryKindPrefix,
+			Key:   "example.com/api/",
+			Order: 1,
+			Route: "default/api",
+		},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("Entries() = %+v, want %+v", entries, want)
+	}
+}
+
+func TestTableMemoryExplain(t *testing.T) {
+	tm := newDebugTestMemory()
+
+	tests := map[string]struct {
+		hostname string
+		path     string
+		headers  http.Header
+		want     MatchResult
+	}{
+		"header match": {
+			hostname: "example.com",
+			path:     "/api/orders",
+			headers:  http.Header{"X-Canary": {"true"}},
+			want: MatchResult{
+				Route: "default/canary",
+				Steps: []MatchStep{
+					{Kind: EntryKindPrefix, Key: "example.com/api/", Route: "default/canary"},
+				},
+			},
+		},
+		"skipped entry has reason": {
+			hostname: "example.com",
+			path:     "/api/orders",
+			want: MatchResult{
+				Route: "default/api",
+				Steps: []MatchStep{
+					{Kind: EntryKindPrefix, Key: "example.com/api/", Route: "default/canary", Reason: "headers do not match"},
+					{Kind: EntryKindPrefix, Key: "example.com/api/", Route: "default/api"},
+				},
+			},
+		},
+		"no match": {
+			hostname: "other.com",
+			path:     "/api",
+			want:     MatchResult{Steps: []MatchStep{}},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := tm.Explain(tt.hostname, tt.path, http.MethodGet, nil, tt.headers)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Explain() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddDebugRoutes(t *testing.T) {
+	tbl := &table{}
+	mux := http.NewServeMux()
+	AddDebugRoutes(logr.Discard(), mux, tbl)
+
+	t.Run("not synced", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/routes", nil))
+		if got, want := rec.Code, http.StatusServiceUnavailable; got != want {
+			t.Fatalf("status = %d, want %d", got, want)
+		}
+	})
+
+	tbl.memoryHolder.Set(newDebugTestMemory())
+
+	t.Run("routes", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/routes", nil))
+		if got, want := rec.Code, http.StatusOK; got != want {
+			t.Fatalf("status = %d, want %d", got, want)
+		}
+
+		var entries []RouteEntryInfo
+		if err := json.Unmarshal(rec.Body.Bytes(), &entries); err != nil {
+			t.Fatalf("decoding response: %v", err)
+		}
+		if got, want := len(entries), 2; got != want {
+			t.Fatalf("got %d entries, want %d", got, want)
+		}
+	})
+
+	t.Run("match", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/match?host=example.com:8080&path=/api&header=X-Canary:%20true", nil))
+		if got, want := rec.Code, http.StatusOK; got != want {
+			t.Fatalf("status = %d, want %d", got, want)
+		}
+
+		var res MatchResult
+		if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+			t.Fatalf("decoding response: %v", err)
+		}
+		if got, want := res.Route, "default/canary"; got != want {
+			t.Fatalf("route = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("invalid header", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/match?host=example.com&header=invalid", nil))
+		if got, want := rec.Code, http.StatusBadRequest; got != want {
+			t.Fatalf("status = %d, want %d", got, want)
+		}
+	})
+}
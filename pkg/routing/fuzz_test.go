@@ -85,7 +85,7 @@ func FuzzTableMemoryRoute(f *testing.F) {
 			headers = http.Header{headerKey: {headerVal}}
 		}
 
-		ir := tm.Route(hostname, path, headers)
+		ir := tm.Route(hostname, path, http.MethodGet, nil, headers)
 		if ir != nil && !knownRouteNames[ir.Name] {
 			t.Errorf("Route returned unknown InterceptorRoute name %q", ir.Name)
 		}
@@ -0,0 +1,214 @@
+package routing
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	httpv1beta1 "github.com/kedacore/http-add-on/operator/apis/http/v1beta1"
+)
+
+// HTTPRouteParent identifies the parent HTTPRoutes must reference in their
+// parentRefs to be routed by the interceptor.
+type HTTPRouteParent struct {
+	// Kind is "Service" for the interceptor proxy Service or "Gateway".
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// ParseHTTPRouteParent parses a parent as "[kind/]namespace/name". The kind
+// defaults to Service.
+func ParseHTTPRouteParent(s string) (HTTPRouteParent, error) {
+	parts := strings.Split(s, "/")
+	switch {
+	case len(parts) == 2:
+		parts = append([]string{"Service"}, parts...)
+	case len(parts) != 3:
+		return HTTPRouteParent{}, fmt.Errorf("invalid HTTPRoute parent %q, expected [kind/]namespace/name", s)
+	}
+
+	p := HTTPRouteParent{Kind: parts[0], Namespace: parts[1], Name: parts[2]}
+	if p.Kind != "Service" && p.Kind != "Gateway" {
+		return HTTPRouteParent{}, fmt.Errorf("invalid HTTPRoute parent kind %q, expected Service or Gateway", p.Kind)
+	}
+	if p.Namespace == "" || p.Name == "" {
+		return HTTPRouteParent{}, fmt.Errorf("invalid HTTPRoute parent %q, expected [kind/]namespace/name", s)
+	}
+	return p, nil
+}
+
+// ReferencedBy reports whether any parentRef of hr references p.
+func (p HTTPRouteParent) ReferencedBy(hr *gatewayv1.HTTPRoute) bool {
+	group := gatewayv1.GroupName
+	if p.Kind == "Service" {
+		group = corev1.GroupName
+	}
+
+	for _, ref := range hr.Spec.ParentRefs {
+		if string(ptr.Deref(ref.Group, gatewayv1.GroupName)) == group &&
+			string(ptr.Deref(ref.Kind, "Gateway")) == p.Kind &&
+			string(ptr.Deref(ref.Namespace, gatewayv1.Namespace(hr.Namespace))) == p.Namespace &&
+			string(ref.Name) == p.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// InterceptorRoutesFromHTTPRoute converts every rule of hr into an
+// InterceptorRoute named after hr, routing to the rule's backendRefs as
+// weighted backends so that each backend is counted separately. Rules that
+// cannot be converted are skipped and reported in the returned error.
+func InterceptorRoutesFromHTTPRoute(hr *gatewayv1.HTTPRoute) ([]*httpv1beta1.InterceptorRoute, error) {
+	scalingMetric, err := scalingMetricFromAnnotations(hr.Annotations)
+	if err != nil {
+		return nil, err
+	}
+
+	hosts := make([]string, 0, len(hr.Spec.Hostnames))
+	for _, h := range hr.Spec.Hostnames {
+		hosts = append(hosts, string(h))
+	}
+
+	var irs []*httpv1beta1.InterceptorRoute
+	var errs []error
+	for i, rule := range hr.Spec.Rules {
+		ir := &httpv1beta1.InterceptorRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				CreationTimestamp: hr.CreationTimestamp,
+				Name:              hr.Name,
+				Namespace:         hr.Namespace,
+			},
+			Spec: httpv1beta1.InterceptorRouteSpec{
+				ScalingMetric: scalingMetric,
+			},
+		}
+		if err := convertHTTPRouteRule(hr.Namespace, hosts, rule, &ir.Spec); err != nil {
+			errs = append(errs, fmt.Errorf("rule %d: %w", i, err))
+			continue
+		}
+		irs = append(irs, ir)
+	}
+	return irs, errors.Join(errs...)
+}
+
+// ScalingRouteFromHTTPRoute returns an InterceptorRoute describing how the
+// backends of all rules of hr are scaled.
+func ScalingRouteFromHTTPRoute(hr *gatewayv1.HTTPRoute) (*httpv1beta1.InterceptorRoute, error) {
+	return scalingRoute(InterceptorRoutesFromHTTPRoute(hr))
+}
+
+func convertHTTPRouteRule(namespace string, hosts []string, rule gatewayv1.HTTPRouteRule, spec *httpv1beta1.InterceptorRouteSpec) error {
+	for _, ref := range rule.BackendRefs {
+		if len(ref.Filters) > 0 {
+			return errors.New("backendRef filters are not supported")
+		}
+		if string(ptr.Deref(ref.Group, "")) != corev1.GroupName || string(ptr.Deref(ref.Kind, "Service")) != "Service" {
+			return fmt.Errorf("backendRef %s: only Services are supported", ref.Name)
+		}
+		if ref.Namespace != nil && string(*ref.Namespace) != namespace {
+			return fmt.Errorf("backendRef %s: cross-namespace backends are not supported", ref.Name)
+		}
+		if ref.Port == nil {
+			return fmt.Errorf("backendRef %s: port is required", ref.Name)
+		}
+		spec.Backends = append(spec.Backends, httpv1beta1.WeightedBackend{
+			TargetRef: httpv1beta1.TargetRef{Service: string(ref.Name), Port: *ref.Port},
+			Weight:    ptr.To(ptr.Deref(ref.Weight, 1)),
+		})
+	}
+	if len(spec.Backends) == 0 {
+		return errors.New("no backendRefs")
+	}
+
+	for _, f := range rule.Filters {
+		switch {
+		case f.Type == gatewayv1.HTTPRouteFilterRequestHeaderModifier && f.RequestHeaderModifier != nil:
+			spec.RequestHeaders = headerModifierFromFilter(f.RequestHeaderModifier)
+		case f.Type == gatewayv1.HTTPRouteFilterResponseHeaderModifier && f.ResponseHeaderModifier != nil:
+			spec.ResponseHeaders = headerModifierFromFilter(f.ResponseHeaderModifier)
+		case f.Type == gatewayv1.HTTPRouteFilterURLRewrite && f.URLRewrite != nil:
+			spec.Rewrite = urlRewriteFromFilter(f.URLRewrite)
+		default:
+			return fmt.Errorf("filter %s is not supported", f.Type)
+		}
+	}
+
+	matches := rule.Matches
+	if len(matches) == 0 {
+		matches = []gatewayv1.HTTPRouteMatch{{}}
+	}
+	for _, m := range matches {
+		spec.Rules = append(spec.Rules, routingRuleFromMatch(hosts, m))
+	}
+	return nil
+}
+
+// routingRuleFromMatch converts an HTTPRouteMatch into a RoutingRule. All
+// conditions of a match must hold, so each match becomes its own rule.
+func routingRuleFromMatch(hosts []string, m gatewayv1.HTTPRouteMatch) httpv1beta1.RoutingRule {
+	rr := httpv1beta1.RoutingRule{Hosts: hosts}
+	if m.Path != nil {
+		rr.Paths = []httpv1beta1.PathMatch{{
+			Type:  httpv1beta1.PathMatchType(ptr.Deref(m.Path.Type, gatewayv1.PathMatchPathPrefix)),
+			Value: ptr.Deref(m.Path.Value, "/"),
+		}}
+	}
+	for _, h := range m.Headers {
+		rr.Headers = append(rr.Headers, httpv1beta1.HeaderMatch{
+			Name:  string(h.Name),
+			Type:  httpv1beta1.HeaderMatchType(ptr.Deref(h.Type, gatewayv1.HeaderMatchExact)),
+			Value: ptr.To(h.Value),
+		})
+	}
+	for _, q := range m.QueryParams {
+		rr.QueryParams = append(rr.QueryParams, httpv1beta1.QueryParamMatch{
+			Name:  string(q.Name),
+			Type:  httpv1beta1.QueryParamMatchType(ptr.Deref(q.Type, gatewayv1.QueryParamMatchExact)),
+			Value: ptr.To(q.Value),
+		})
+	}
+	if m.Method != nil {
+		rr.Methods = []httpv1beta1.HTTPMethod{httpv1beta1.HTTPMethod(*m.Method)}
+	}
+	return rr
+}
+
+func headerModifierFromFilter(f *gatewayv1.HTTPHeaderFilter) *httpv1beta1.HeaderModifier {
+	m := &httpv1beta1.HeaderModifier{Remove: f.Remove}
+	for _, h := range f.Set {
+		if m.Set == nil {
+			m.Set = make(map[string]string, len(f.Set))
+		}
+		m.Set[string(h.Name)] = h.Value
+	}
+	for _, h := range f.Add {
+		if m.Add == nil {
+			m.Add = make(map[string]string, len(f.Add))
+		}
+		m.Add[string(h.Name)] = h.Value
+	}
+	return m
+}
+
+func urlRewriteFromFilter(f *gatewayv1.HTTPURLRewriteFilter) *httpv1beta1.URLRewrite {
+	rw := &httpv1beta1.URLRewrite{}
+	if f.Hostname != nil {
+		rw.Hostname = ptr.To(string(*f.Hostname))
+	}
+	if f.Path != nil {
+		switch f.Path.Type {
+		case gatewayv1.PrefixMatchHTTPPathModifier:
+			rw.ReplacePrefixMatch = f.Path.ReplacePrefixMatch
+		case gatewayv1.FullPathHTTPPathModifier:
+			rw.ReplaceFullPath = f.Path.ReplaceFullPath
+		}
+	}
+	return rw
+}
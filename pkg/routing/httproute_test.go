@@ -0,0 +1,187 @@
+package routing
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	httpv1beta1 "github.com/kedacore/http-add-on/operator/apis/http/v1beta1"
+)
+
+func newTestHTTPRoute(rules ...gatewayv1.HTTPRouteRule) *gatewayv1.HTTPRoute {
+	return &gatewayv1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+		Spec: gatewayv1.HTTPRouteSpec{
+			CommonRouteSpec: gatewayv1.CommonRouteSpec{
+				ParentRefs: []gatewayv1.ParentReference{{
+					Group:     ptr.To[gatewayv1.Group](""),
+					Kind:      ptr.To[gatewayv1.Kind]("Service"),
+					Namespace: ptr.To[gatewayv1.Namespace]("keda"),
+					Name:      "interceptor-proxy",
+				}},
+			},
+			Hostnames: []gatewayv1.Hostname{"example.com"},
+			Rules:     rules,
+		},
+	}
+}
+
+func testBackendRef(name string, weight *int32) gatewayv1.HTTPBackendRef {
+	return gatewayv1.HTTPBackendRef{BackendRef: gatewayv1.BackendRef{
+		BackendObjectReference: gatewayv1.BackendObjectReference{Name: gatewayv1.ObjectName(name), Port: ptr.To[gatewayv1.PortNumber](8080)},
+		Weight:                 weight,
+	}}
+}
+
+func TestParseHTTPRouteParent(t *testing.T) {
+	tests := map[string]struct {
+		in      string
+		want    HTTPRouteParent
+		wantErr bool
+	}{
+		"default kind": {in: "keda/interceptor-proxy", want: HTTPRouteParent{Kind: "Service", Namespace: "keda", Name: "interceptor-proxy"}},
+		"gateway":      {in: "Gateway/infra/public", want: HTTPRouteParent{Kind: "Gateway", Namespace: "infra", Name: "public"}},
+		"unknown kind": {in: "Ingress/infra/public", wantErr: true},
+		"missing name": {in: "keda/", wantErr: true},
+		"name only":    {in: "interceptor-proxy", wantErr: true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseHTTPRouteParent(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseHTTPRouteParent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseHTTPRouteParent() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPRouteParentReferencedBy(t *testing.T) {
+	hr := newTestHTTPRoute()
+
+	tests := map[string]struct {
+		parent HTTPRouteParent
+		want   bool
+	}{
+		"matching service": {parent: HTTPRouteParent{Kind: "Service", Namespace: "keda", Name: "interceptor-proxy"}, want: true},
+		"other namespace":  {parent: HTTPRouteParent{Kind: "Service", Namespace: "default", Name: "interceptor-proxy"}},
+		"other kind":       {parent: HTTPRouteParent{Kind: "Gateway", Namespace: "keda", Name: "interceptor-proxy"}},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := tt.parent.ReferencedBy(hr); got != tt.want {
+				t.Errorf("ReferencedBy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("gateway defaults", func(t *testing.T) {
+		hr := newTestHTTPRoute()
+		hr.Spec.ParentRefs = []gatewayv1.ParentReference{{Name: "public"}}
+		if !(HTTPRouteParent{Kind: "Gateway", Namespace: "default", Name: "public"}).ReferencedBy(hr) {
+			t.Error("expected Gateway in the route namespace to be referenced")
+		}
+	})
+}
+
+func TestInterceptorRoutesFromHTTPRoute(t *testing.T) {
+	hr := newTestHTTPRoute(
+		gatewayv1.HTTPRouteRule{
+			Matches: []gatewayv1.HTTPRouteMatch{
+				{
+					Path:    &gatewayv1.HTTPPathMatch{Type: ptr.To(gatewayv1.PathMatchExact), Value: ptr.To("/login")},
+					Method:  ptr.To(gatewayv1.HTTPMethodPost),
+					Headers: []gatewayv1.HTTPHeaderMatch{{Name: "X-Canary", Value: "true"}},
+				},
+				{
+					Path:        &gatewayv1.HTTPPathMatch{Value: ptr.To("/api")},
+					QueryParams: []gatewayv1.HTTPQueryParamMatch{{Type: ptr.To(gatewayv1.QueryParamMatchRegularExpression), Name: "v", Value: "[0-9]+"}},
+				},
+			},
+			Filters: []gatewayv1.HTTPRouteFilter{{
+				Type: gatewayv1.HTTPRouteFilterURLRewrite,
+				URLRewrite: &gatewayv1.HTTPURLRewriteFilter{
+					Path: &gatewayv1.HTTPPathModifier{Type: gatewayv1.PrefixMatchHTTPPathModifier, ReplacePrefixMatch: ptr.To("/")},
+				},
+			}},
+			BackendRefs: []gatewayv1.HTTPBackendRef{testBackendRef("stable", ptr.To[int32](9)), testBackendRef("canary", nil)},
+		},
+		gatewayv1.HTTPRouteRule{
+			Filters: []gatewayv1.HTTPRouteFilter{{
+				Type:            gatewayv1.HTTPRouteFilterRequestRedirect,
+				RequestRedirect: &gatewayv1.HTTPRequestRedirectFilter{Scheme: ptr.To("https")},
+			}},
+			BackendRefs: []gatewayv1.HTTPBackendRef{testBackendRef("stable", nil)},
+		},
+		gatewayv1.HTTPRouteRule{
+			BackendRefs: []gatewayv1.HTTPBackendRef{testBackendRef("web", nil)},
+		},
+	)
+	hr.CreationTimestamp = metav1.NewTime(time.Unix(100, 0))
+
+	irs, err := InterceptorRoutesFromHTTPRoute(hr)
+	if err == nil {
+		t.Error("expected error for the redirect rule")
+	}
+
+	meta := metav1.ObjectMeta{Namespace: "default", Name: "web", CreationTimestamp: hr.CreationTimestamp}
+	scaling := httpv1beta1.ScalingMetricSpec{Concurrency: &httpv1beta1.ConcurrencyTargetSpec{TargetValue: 100}}
+	want := []*httpv1beta1.InterceptorRoute{
+		{
+			ObjectMeta: meta,
+			Spec: httpv1beta1.InterceptorRouteSpec{
+				Backends: []httpv1beta1.WeightedBackend{
+					{TargetRef: httpv1beta1.TargetRef{Service: "stable", Port: 8080}, Weight: ptr.To[int32](9)},
+					{TargetRef: httpv1beta1.TargetRef{Service: "canary", Port: 8080}, Weight: ptr.To[int32](1)},
+				},
+				Rewrite: &httpv1beta1.URLRewrite{ReplacePrefixMatch: ptr.To("/")},
+				Rules: []httpv1beta1.RoutingRule{
+					{
+						Hosts:   []string{"example.com"},
+						Paths:   []httpv1beta1.PathMatch{{Type: httpv1beta1.PathMatchExact, Value: "/login"}},
+						Headers: []httpv1beta1.HeaderMatch{{Name: "X-Canary", Type: httpv1beta1.HeaderMatchExact, Value: ptr.To("true")}},
+						Methods: []httpv1beta1.HTTPMethod{http.MethodPost},
+					},
+					{
+						Hosts:       []string{"example.com"},
+						Paths:       []httpv1beta1.PathMatch{{Type: httpv1beta1.PathMatchPathPrefix, Value: "/api"}},
+						QueryParams: []httpv1beta1.QueryParamMatch{{Name: "v", Type: httpv1beta1.QueryParamMatchRegularExpression, Value: ptr.To("[0-9]+")}},
+					},
+				},
+				ScalingMetric: scaling,
+			},
+		},
+		{
+			ObjectMeta: meta,
+			Spec: httpv1beta1.InterceptorRouteSpec{
+				Backends:      []httpv1beta1.WeightedBackend{{TargetRef: httpv1beta1.TargetRef{Service: "web", Port: 8080}, Weight: ptr.To[int32](1)}},
+				Rules:         []httpv1beta1.RoutingRule{{Hosts: []string{"example.com"}}},
+				ScalingMetric: scaling,
+			},
+		},
+	}
+	if !reflect.DeepEqual(irs, want) {
+		t.Errorf("InterceptorRoutesFromHTTPRoute() = %+v, want %+v", irs, want)
+	}
+
+	scalingIR, err := ScalingRouteFromHTTPRoute(hr)
+	if err != nil {
+		t.Fatalf("ScalingRouteFromHTTPRoute() error = %v", err)
+	}
+	var services []string
+	for _, b := range scalingIR.Spec.Backends {
+		services = append(services, b.Service)
+	}
+	if want := []string{"stable", "canary", "web"}; !reflect.DeepEqual(services, want) {
+		t.Errorf("scaling backends = %v, want %v", services, want)
+	}
+}
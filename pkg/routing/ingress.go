@@ -0,0 +1,123 @@
+package routing
+
+import (
+	"errors"
+	"fmt"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	httpv1beta1 "github.com/kedacore/http-add-on/operator/apis/http/v1beta1"
+)
+
+// legacyIngressClassAnnotation selects the class of Ingresses predating
+// spec.ingressClassName.
+const legacyIngressClassAnnotation = "kubernetes.io/ingress.class"
+
+// IngressHasClass reports whether ing belongs to the IngressClass class.
+func IngressHasClass(ing *networkingv1.Ingress, class string) bool {
+	if ing.Spec.IngressClassName != nil {
+		return *ing.Spec.IngressClassName == class
+	}
+	return ing.Annotations[legacyIngressClassAnnotation] == class
+}
+
+// InterceptorRoutesFromIngress converts every path of ing, and its default
+// backend, into an InterceptorRoute named after ing, routing to the path's
+// Service as a single weighted backend so that each Service is counted
+// separately. Paths that cannot be converted are skipped and reported in the
+// returned error.
+func InterceptorRoutesFromIngress(ing *networkingv1.Ingress) ([]*httpv1beta1.InterceptorRoute, error) {
+	scalingMetric, err := scalingMetricFromAnnotations(ing.Annotations)
+	if err != nil {
+		return nil, err
+	}
+
+	newRoute := func(backend networkingv1.IngressBackend, rule httpv1beta1.RoutingRule) (*httpv1beta1.InterceptorRoute, error) {
+		target, err := ingressBackendTarget(backend)
+		if err != nil {
+			return nil, err
+		}
+		return &httpv1beta1.InterceptorRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				CreationTimestamp: ing.CreationTimestamp,
+				Name:              ing.Name,
+				Namespace:         ing.Namespace,
+			},
+			Spec: httpv1beta1.InterceptorRouteSpec{
+				Backends:      []httpv1beta1.WeightedBackend{{TargetRef: target, Weight: ptr.To[int32](1)}},
+				Rules:         []httpv1beta1.RoutingRule{rule},
+				ScalingMetric: scalingMetric,
+			},
+		}, nil
+	}
+
+	var irs []*httpv1beta1.InterceptorRoute
+	var errs []error
+	for i, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+
+		var hosts []string
+		if rule.Host != "" {
+			hosts = []string{rule.Host}
+		}
+		for j, path := range rule.HTTP.Paths {
+			ir, err := newRoute(path.Backend, httpv1beta1.RoutingRule{
+				Hosts: hosts,
+				Paths: []httpv1beta1.PathMatch{ingressPathMatch(path)},
+			})
+			if err != nil {
+				errs = append(errs, fmt.Errorf("rule %d path %d: %w", i, j, err))
+				continue
+			}
+			irs = append(irs, ir)
+		}
+	}
+
+	// The default backend receives requests matching no rule of any Ingress
+	// of the class, as with other Ingress controllers.
+	if backend := ing.Spec.DefaultBackend; backend != nil {
+		ir, err := newRoute(*backend, httpv1beta1.RoutingRule{})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("default backend: %w", err))
+		} else {
+			irs = append(irs, ir)
+		}
+	}
+
+	return irs, errors.Join(errs...)
+}
+
+// ScalingRouteFromIngress returns an InterceptorRoute describing how the
+// backends of all paths of ing are scaled.
+func ScalingRouteFromIngress(ing *networkingv1.Ingress) (*httpv1beta1.InterceptorRoute, error) {
+	return scalingRoute(InterceptorRoutesFromIngress(ing))
+}
+
+// ingressPathMatch converts an Ingress path. ImplementationSpecific paths
+// are matched as prefixes.
+func ingressPathMatch(path networkingv1.HTTPIngressPath) httpv1beta1.PathMatch {
+	value := path.Path
+	if value == "" {
+		value = "/"
+	}
+
+	if ptr.Deref(path.PathType, networkingv1.PathTypeImplementationSpecific) == networkingv1.PathTypeExact {
+		return httpv1beta1.PathMatch{Type: httpv1beta1.PathMatchExact, Value: value}
+	}
+	return httpv1beta1.PathMatch{Type: httpv1beta1.PathMatchPathPrefix, Value: value}
+}
+
+func ingressBackendTarget(backend networkingv1.IngressBackend) (httpv1beta1.TargetRef, error) {
+	svc := backend.Service
+	if svc == nil {
+		return httpv1beta1.TargetRef{}, errors.New("only Service backends are supported")
+	}
+	if svc.Port.Name == "" && svc.Port.Number == 0 {
+		return httpv1beta1.TargetRef{}, fmt.Errorf("backend %s: port is required", svc.Name)
+	}
+	return httpv1beta1.TargetRef{Service: svc.Name, Port: svc.Port.Number, PortName: svc.Port.Name}, nil
+}
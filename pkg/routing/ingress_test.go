@@ -0,0 +1,110 @@
+package routing
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	httpv1beta1 "github.com/kedacore/http-add-on/operator/apis/http/v1beta1"
+)
+
+func testIngressBackend(service string, port networkingv1.ServiceBackendPort) networkingv1.IngressBackend {
+	return networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{Name: service, Port: port}}
+}
+
+func newTestIngress(class string) *networkingv1.Ingress {
+	return &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "shop"},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: ptr.To(class),
+			Rules: []networkingv1.IngressRule{{
+				Host: "shop.example.com",
+				IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: []networkingv1.HTTPIngressPath{
+						{Path: "/cart", PathType: ptr.To(networkingv1.PathTypeExact), Backend: testIngressBackend("cart", networkingv1.ServiceBackendPort{Number: 8080})},
+						{Path: "/", PathType: ptr.To(networkingv1.PathTypePrefix), Backend: testIngressBackend("web", networkingv1.ServiceBackendPort{Name: "http"})},
+					},
+				}},
+			}},
+		},
+	}
+}
+
+func TestIngressHasClass(t *testing.T) {
+	legacy := newTestIngress("")
+	legacy.Spec.IngressClassName = nil
+	legacy.Annotations = map[string]string{legacyIngressClassAnnotation: "keda-http"}
+
+	tests := map[string]struct {
+		ing  *networkingv1.Ingress
+		want bool
+	}{
+		"matching class":    {ing: newTestIngress("keda-http"), want: true},
+		"other class":       {ing: newTestIngress("nginx")},
+		"legacy annotation": {ing: legacy, want: true},
+		"no class":          {ing: &networkingv1.Ingress{}},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := IngressHasClass(tt.ing, "keda-http"); got != tt.want {
+				t.Errorf("IngressHasClass() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInterceptorRoutesFromIngress(t *testing.T) {
+	ing := newTestIngress("keda-http")
+	ing.Spec.Rules = append(ing.Spec.Rules, networkingv1.IngressRule{
+		IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+			Paths: []networkingv1.HTTPIngressPath{
+				{Path: "/static", Backend: networkingv1.IngressBackend{Resource: &corev1.TypedLocalObjectReference{Kind: "Bucket", Name: "assets"}}},
+				{Path: "/api", PathType: ptr.To(networkingv1.PathTypeImplementationSpecific), Backend: testIngressBackend("api", networkingv1.ServiceBackendPort{Number: 80})},
+			},
+		}},
+	})
+	ing.Spec.DefaultBackend = ptr.To(testIngressBackend("fallback", networkingv1.ServiceBackendPort{Number: 80}))
+
+	irs, err := InterceptorRoutesFromIngress(ing)
+	if err == nil {
+		t.Error("expected error for the resource backend")
+	}
+
+	type route struct {
+		target httpv1beta1.TargetRef
+		rule   httpv1beta1.RoutingRule
+	}
+	var got []route
+	for _, ir := range irs {
+		if ir.Name != "shop" || ir.Namespace != "default" {
+			t.Errorf("route name = %s/%s, want default/shop", ir.Namespace, ir.Name)
+		}
+		got = append(got, route{target: ir.Spec.Backends[0].TargetRef, rule: ir.Spec.Rules[0]})
+	}
+
+	want := []route{
+		{
+			target: httpv1beta1.TargetRef{Service: "cart", Port: 8080},
+			rule:   httpv1beta1.RoutingRule{Hosts: []string{"shop.example.com"}, Paths: []httpv1beta1.PathMatch{{Type: httpv1beta1.PathMatchExact, Value: "/cart"}}},
+		},
+		{
+			target: httpv1beta1.TargetRef{Service: "web", PortName: "http"},
+			rule:   httpv1beta1.RoutingRule{Hosts: []string{"shop.example.com"}, Paths: []httpv1beta1.PathMatch{{Type: httpv1beta1.PathMatchPathPrefix, Value: "/"}}},
+		},
+		{
+			target: httpv1beta1.TargetRef{Service: "api", Port: 80},
+			rule:   httpv1beta1.RoutingRule{Paths: []httpv1beta1.PathMatch{{Type: httpv1beta1.PathMatchPathPrefix, Value: "/api"}}},
+		},
+		{
+			target: httpv1beta1.TargetRef{Service: "fallback", Port: 80},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("InterceptorRoutesFromIngress() = %+v, want %+v", got, want)
+	}
+}
@@ -56,28 +56,35 @@ func NewKeyFromRequest(req *http.Request) Key {
 	return NewKey(RequestHost(req), req.URL.Path)
 }
 
-// newKeysFromRoutingRule creates routing keys from a RoutingRule.
+// newKeysFromRoutingRule creates prefix routing keys from a RoutingRule.
 // Nil hosts default to catch-all, nil paths default to root.
+// Paths of other match types are skipped.
 func newKeysFromRoutingRule(rule httpv1beta1.RoutingRule) Keys {
-	hosts := rule.Hosts
-	if len(hosts) == 0 {
-		hosts = []string{catchAllHostKey}
-	}
-
 	paths := rule.Paths
 	if len(paths) == 0 {
 		paths = []httpv1beta1.PathMatch{{}}
 	}
 
-	keys := make([]Key, 0, len(hosts)*len(paths))
+	return newKeysForPaths(rule.Hosts, paths, httpv1beta1.PathMatchPathPrefix)
+}
 
-	for _, hostname := range hosts {
-		hostname = StripPort(hostname)
-		if isCatchAllHostname(hostname) {
-			hostname = catchAllHostKey
-		}
+// newExactKeysFromRoutingRule creates exact routing keys from a RoutingRule.
+// Nil hosts default to catch-all. Paths of other match types are skipped.
+func newExactKeysFromRoutingRule(rule httpv1beta1.RoutingRule) Keys {
+	return newKeysForPaths(rule.Hosts, rule.Paths, httpv1beta1.PathMatchExact)
+}
 
+// newKeysForPaths creates the cartesian product of hosts and all paths with
+// the given match type. An empty path type is treated as PathPrefix.
+func newKeysForPaths(hosts []string, paths []httpv1beta1.PathMatch, pathType httpv1beta1.PathMatchType) Keys {
+	hostnames := routingHostnames(hosts)
+	keys := make([]Key, 0, len(hostnames)*len(paths))
+
+	for _, hostname := range hostnames {
 		for _, path := range paths {
+			if pathMatchType(path) != pathType {
+				continue
+			}
 			key := NewKey(hostname, path.Value)
 			keys = append(keys, key)
 		}
@@ -86,6 +93,33 @@ func newKeysFromRoutingRule(rule httpv1beta1.RoutingRule) Keys {
 	return keys
 }
 
+// routingHostnames normalizes the hosts of a RoutingRule: ports are stripped
+// and catch-all patterns are mapped to the catch-all key. Nil hosts default
+// to catch-all.
+func routingHostnames(hosts []string) []string {
+	if len(hosts) == 0 {
+		return []string{catchAllHostKey}
+	}
+
+	hostnames := make([]string, 0, len(hosts))
+	for _, hostname := range hosts {
+		hostname = StripPort(hostname)
+		if isCatchAllHostname(hostname) {
+			hostname = catchAllHostKey
+		}
+		hostnames = append(hostnames, hostname)
+	}
+	return hostnames
+}
+
+// pathMatchType returns the match type of p, defaulting to PathPrefix.
+func pathMatchType(p httpv1beta1.PathMatch) httpv1beta1.PathMatchType {
+	if p.Type == "" {
+		return httpv1beta1.PathMatchPathPrefix
+	}
+	return p.Type
+}
+
 // wildcardHostnames returns all wildcard patterns for a hostname,
 // ordered from most specific to least specific.
 // "foo.example.com" -> ["*.example.com", "*.com"]
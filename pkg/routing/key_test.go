@@ -123,6 +123,24 @@ func TestNewKeysFromRoutingRule(t *testing.T) {
 			},
 			want: []string{"example.com/api/"},
 		},
+		"skips non-prefix paths": {
+			rule: httpv1beta1.RoutingRule{
+				Hosts: []string{"example.com"},
+				Paths: []httpv1beta1.PathMatch{
+					{Type: httpv1beta1.PathMatchPathPrefix, Value: "/api"},
+					{Type: httpv1beta1.PathMatchExact, Value: "/exact"},
+					{Type: httpv1beta1.PathMatchRegularExpression, Value: "/re.*"},
+				},
+			},
+			want: []string{"example.com/api/"},
+		},
+		"only non-prefix paths yields no keys": {
+			rule: httpv1beta1.RoutingRule{
+				Hosts: []string{"example.com"},
+				Paths: []httpv1beta1.PathMatch{{Type: httpv1beta1.PathMatchExact, Value: "/exact"}},
+			},
+			want: []string{},
+		},
 	}
 
 	for name, tt := range tests {
@@ -144,6 +162,52 @@ func TestNewKeysFromRoutingRule(t *testing.T) {
 	}
 }
 
+func TestNewExactKeysFromRoutingRule(t *testing.T) {
+	tests := map[string]struct {
+		rule httpv1beta1.RoutingRule
+		want []string
+	}{
+		"exact paths only": {
+			rule: httpv1beta1.RoutingRule{
+				Hosts: []string{"a.com", "b.com"},
+				Paths: []httpv1beta1.PathMatch{
+					{Type: httpv1beta1.PathMatchExact, Value: "/x"},
+					{Value: "/prefix"},
+				},
+			},
+			want: []string{"a.com/x/", "b.com/x/"},
+		},
+		"nil hosts defaults to catch-all": {
+			rule: httpv1beta1.RoutingRule{
+				Paths: []httpv1beta1.PathMatch{{Type: httpv1beta1.PathMatchExact, Value: "/x"}},
+			},
+			want: []string{"*/x/"},
+		},
+		"nil paths yields no keys": {
+			rule: httpv1beta1.RoutingRule{Hosts: []string{"a.com"}},
+			want: []string{},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			keys := newExactKeysFromRoutingRule(tt.rule)
+
+			got := make([]string, len(keys))
+			for i, k := range keys {
+				got[i] = k.String()
+			}
+
+			slices.Sort(got)
+			slices.Sort(tt.want)
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestStripPort(t *testing.T) {
 	tests := map[string]struct {
 		host string
@@ -1,44 +1,177 @@
 package routing
 
 import (
+	"fmt"
 	"net"
 	"net/http"
+	"net/url"
+	"regexp"
 	"slices"
 	"strings"
+	"sync"
 
 	httpv1beta1 "github.com/kedacore/http-add-on/operator/apis/http/v1beta1"
 )
 
 // MatchRoutingRule reports whether the request matches the given rule.
-// Empty fields match everything. Hosts and paths use OR semantics; headers use AND semantics.
+// Empty fields match everything. Hosts, paths and methods use OR semantics;
+// headers and query parameters use AND semantics. Regular expressions are
+// cached by the routing table in the context of r, if any.
 func MatchRoutingRule(r *http.Request, rule httpv1beta1.RoutingRule) bool {
+	return matchRoutingRule(r, rule, TableMemoryFromContext(r.Context()).regexCache())
+}
+
+func matchRoutingRule(r *http.Request, rule httpv1beta1.RoutingRule, regexes *regexCache) bool {
+	if !MatchMethod(rule.Methods, r.Method) {
+		return false
+	}
 	if len(rule.Hosts) > 0 && !MatchAnyHost(RequestHost(r), rule.Hosts) {
 		return false
 	}
-	if len(rule.Paths) > 0 && !MatchAnyPath(r.URL.Path, rule.Paths) {
+	if len(rule.Paths) > 0 && !matchAnyPath(r.URL.Path, rule.Paths, regexes) {
+		return false
+	}
+	if len(rule.Headers) > 0 && !matchHeaders(rule.Headers, r.Header, regexes) {
 		return false
 	}
-	if len(rule.Headers) > 0 && !MatchHeaders(rule.Headers, r.Header) {
+	if len(rule.QueryParams) > 0 && !matchQueryParams(rule.QueryParams, r.URL.Query(), regexes) {
 		return false
 	}
 	return true
 }
 
+// MatchMethod reports whether method is one of methods. Empty methods match everything.
+func MatchMethod(methods []httpv1beta1.HTTPMethod, method string) bool {
+	return len(methods) == 0 || slices.Contains(methods, httpv1beta1.HTTPMethod(method))
+}
+
 // MatchHeaders reports whether reqHeaders satisfies all matchers (AND semantics).
-// A matcher with a nil Value requires the header to be present with any value.
+// A matcher without a type is an exact match when Value is set and a presence
+// check otherwise. Invalid regular expressions never match, and are compiled
+// on each call.
 func MatchHeaders(matchers []httpv1beta1.HeaderMatch, reqHeaders http.Header) bool {
+	return matchHeaders(matchers, reqHeaders, nil)
+}
+
+func matchHeaders(matchers []httpv1beta1.HeaderMatch, reqHeaders http.Header, regexes *regexCache) bool {
 	for _, m := range matchers {
 		vals := reqHeaders.Values(m.Name)
-		if m.Value == nil && len(vals) == 0 {
-			return false
+
+		switch headerMatchType(m) {
+		case httpv1beta1.HeaderMatchNotPresent:
+			if len(vals) > 0 {
+				return false
+			}
+		case httpv1beta1.HeaderMatchPresent:
+			if len(vals) == 0 {
+				return false
+			}
+		case httpv1beta1.HeaderMatchPrefix:
+			if !slices.ContainsFunc(vals, func(v string) bool { return strings.HasPrefix(v, *m.Value) }) {
+				return false
+			}
+		case httpv1beta1.HeaderMatchRegularExpression:
+			re, err := regexes.compile(*m.Value)
+			if err != nil || !slices.ContainsFunc(vals, re.MatchString) {
+				return false
+			}
+		default:
+			if !slices.Contains(vals, *m.Value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// ValidateRoutingRule returns an error if a regular expression of rule is not
+// a valid RE2 expression, which would never match.
+func ValidateRoutingRule(rule httpv1beta1.RoutingRule) error {
+	for _, p := range rule.Paths {
+		if pathMatchType(p) != httpv1beta1.PathMatchRegularExpression {
+			continue
+		}
+		if _, err := compileRegex(p.Value); err != nil {
+			return fmt.Errorf("path: %w", err)
+		}
+	}
+	for _, m := range rule.Headers {
+		if headerMatchType(m) != httpv1beta1.HeaderMatchRegularExpression {
+			continue
+		}
+		if _, err := compileRegex(*m.Value); err != nil {
+			return fmt.Errorf("header %q: %w", m.Name, err)
 		}
-		if m.Value != nil && !slices.Contains(vals, *m.Value) {
+	}
+	for _, m := range rule.QueryParams {
+		if queryParamMatchType(m) != httpv1beta1.QueryParamMatchRegularExpression {
+			continue
+		}
+		if _, err := compileRegex(*m.Value); err != nil {
+			return fmt.Errorf("query parameter %q: %w", m.Name, err)
+		}
+	}
+	return nil
+}
+
+// headerMatchType returns the effective match type of m.
+func headerMatchType(m httpv1beta1.HeaderMatch) httpv1beta1.HeaderMatchType {
+	switch {
+	case m.Type == httpv1beta1.HeaderMatchNotPresent:
+		return httpv1beta1.HeaderMatchNotPresent
+	case m.Type == httpv1beta1.HeaderMatchPresent || m.Value == nil:
+		return httpv1beta1.HeaderMatchPresent
+	case m.Type == "":
+		return httpv1beta1.HeaderMatchExact
+	default:
+		return m.Type
+	}
+}
+
+// MatchQueryParams reports whether query satisfies all matchers (AND semantics).
+// A matcher without a type is an exact match when Value is set and a presence
+// check otherwise. Invalid regular expressions never match, and are compiled
+// on each call.
+func MatchQueryParams(matchers []httpv1beta1.QueryParamMatch, query url.Values) bool {
+	return matchQueryParams(matchers, query, nil)
+}
+
+func matchQueryParams(matchers []httpv1beta1.QueryParamMatch, query url.Values, regexes *regexCache) bool {
+	for _, m := range matchers {
+		vals, present := query[m.Name]
+		if !present {
 			return false
 		}
+
+		switch queryParamMatchType(m) {
+		case httpv1beta1.QueryParamMatchPresent:
+			continue
+		case httpv1beta1.QueryParamMatchRegularExpression:
+			re, err := regexes.compile(*m.Value)
+			if err != nil || !slices.ContainsFunc(vals, re.MatchString) {
+				return false
+			}
+		default:
+			if !slices.Contains(vals, *m.Value) {
+				return false
+			}
+		}
 	}
 	return true
 }
 
+// queryParamMatchType returns the effective match type of m.
+func queryParamMatchType(m httpv1beta1.QueryParamMatch) httpv1beta1.QueryParamMatchType {
+	switch {
+	case m.Type == httpv1beta1.QueryParamMatchPresent || m.Value == nil:
+		return httpv1beta1.QueryParamMatchPresent
+	case m.Type == "":
+		return httpv1beta1.QueryParamMatchExact
+	default:
+		return m.Type
+	}
+}
+
 // MatchAnyHost reports whether reqHost matches any of the host patterns.
 // Supports exact match, wildcard prefix ("*.example.com"), and catch-all ("*" or "").
 func MatchAnyHost(reqHost string, hosts []string) bool {
@@ -60,22 +193,114 @@ func MatchAnyHost(reqHost string, hosts []string) bool {
 	return false
 }
 
-// MatchAnyPath reports whether reqPath prefix-matches any of the path patterns.
-// A root path ("/" or "") matches everything.
+// MatchAnyPath reports whether reqPath matches any of the path patterns.
+// Prefix matches operate on whole path segments and a root path ("/" or "")
+// matches everything. Invalid regular expressions never match, and are
+// compiled on each call.
 func MatchAnyPath(reqPath string, paths []httpv1beta1.PathMatch) bool {
+	return matchAnyPath(reqPath, paths, nil)
+}
+
+func matchAnyPath(reqPath string, paths []httpv1beta1.PathMatch, regexes *regexCache) bool {
 	norm := normalizePath(reqPath)
 	for _, p := range paths {
-		prefix := normalizePath(p.Value)
-		if prefix == "" {
-			return true
-		}
-		if norm == prefix || strings.HasPrefix(norm, prefix+"/") {
-			return true
+		switch pathMatchType(p) {
+		case httpv1beta1.PathMatchExact:
+			if norm == normalizePath(p.Value) {
+				return true
+			}
+		case httpv1beta1.PathMatchRegularExpression:
+			re, err := regexes.compile(p.Value)
+			if err == nil && re.MatchString(reqPath) {
+				return true
+			}
+		default:
+			prefix := normalizePath(p.Value)
+			if prefix == "" {
+				return true
+			}
+			if norm == prefix || strings.HasPrefix(norm, prefix+"/") {
+				return true
+			}
 		}
 	}
 	return false
 }
 
+// MatchedPathPrefix returns the longest path prefix of the rules matching r,
+// normalized without leading and trailing slashes. Rules without paths match
+// the root prefix "". Returns false if r is not matched by any prefix.
+func MatchedPathPrefix(r *http.Request, rules []httpv1beta1.RoutingRule) (string, bool) {
+	if len(rules) == 0 {
+		return "", true
+	}
+
+	regexes := TableMemoryFromContext(r.Context()).regexCache()
+	norm := normalizePath(r.URL.Path)
+	prefix, found := "", false
+	for _, rule := range rules {
+		paths := rule.Paths
+		rule.Paths = nil
+		if !matchRoutingRule(r, rule, regexes) {
+			continue
+		}
+		if len(paths) == 0 {
+			found = true
+			continue
+		}
+		for _, p := range paths {
+			if pathMatchType(p) != httpv1beta1.PathMatchPathPrefix {
+				continue
+			}
+			pp := normalizePath(p.Value)
+			if pp == "" || norm == pp || strings.HasPrefix(norm, pp+"/") {
+				found = true
+				if len(pp) > len(prefix) {
+					prefix = pp
+				}
+			}
+		}
+	}
+	return prefix, found
+}
+
+// regexCache holds the compiled match expressions of a routing table keyed by
+// their pattern. It is dropped with the table, so patterns of removed routes
+// do not outlive the next table. A nil regexCache compiles without caching.
+type regexCache struct {
+	regexes sync.Map
+}
+
+func newRegexCache() *regexCache {
+	return &regexCache{}
+}
+
+// compile compiles an RE2 expression anchored to match the full input.
+func (c *regexCache) compile(pattern string) (*regexp.Regexp, error) {
+	if c == nil {
+		return compileRegex(pattern)
+	}
+	if v, ok := c.regexes.Load(pattern); ok {
+		return v.(*regexp.Regexp), nil
+	}
+
+	re, err := compileRegex(pattern)
+	if err != nil {
+		return nil, err
+	}
+	c.regexes.Store(pattern, re)
+	return re, nil
+}
+
+// compileRegex compiles an RE2 expression anchored to match the full input.
+func compileRegex(pattern string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression %q: %w", pattern, err)
+	}
+	return re, nil
+}
+
 // RequestHost extracts the hostname from the request, stripping the port if present.
 // Falls back to r.URL.Host when r.Host is empty.
 func RequestHost(r *http.Request) string {
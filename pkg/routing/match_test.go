@@ -3,6 +3,7 @@ package routing
 import (
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	"k8s.io/utils/ptr"
@@ -17,6 +18,7 @@ func TestMatchRoutingRule(t *testing.T) {
 		rule    httpv1beta1.RoutingRule
 		path    string
 		host    string
+		method  string
 		headers map[string]string
 		want    bool
 	}{
@@ -60,11 +62,36 @@ func TestMatchRoutingRule(t *testing.T) {
 			headers: map[string]string{"Custom-Header": "not matching"},
 			want:    false,
 		},
+		"query only match": {
+			rule: httpv1beta1.RoutingRule{
+				QueryParams: []httpv1beta1.QueryParamMatch{{Name: "api-version", Value: ptr.To("2024-01-01")}},
+			},
+			path: "/?api-version=2024-01-01",
+			want: true,
+		},
+		"query only miss": {
+			rule: httpv1beta1.RoutingRule{
+				QueryParams: []httpv1beta1.QueryParamMatch{{Name: "api-version", Value: ptr.To("2024-01-01")}},
+			},
+			path: "/?api-version=2023-01-01",
+			want: false,
+		},
+		"method only match": {
+			rule:   httpv1beta1.RoutingRule{Methods: []httpv1beta1.HTTPMethod{http.MethodPost, http.MethodPut}},
+			method: http.MethodPut,
+			want:   true,
+		},
+		"method only miss": {
+			rule:   httpv1beta1.RoutingRule{Methods: []httpv1beta1.HTTPMethod{http.MethodPost}},
+			method: http.MethodGet,
+			want:   false,
+		},
 		"all fields match": {
 			rule: httpv1beta1.RoutingRule{
 				Hosts:   []string{"app.example.com"},
 				Paths:   []httpv1beta1.PathMatch{{Value: "/api"}},
 				Headers: []httpv1beta1.HeaderMatch{{Name: "Custom-Header", Value: &headerValue}},
+				Methods: []httpv1beta1.HTTPMethod{http.MethodGet},
 			},
 			host:    "app.example.com",
 			path:    "/api/v1",
@@ -80,7 +107,12 @@ func TestMatchRoutingRule(t *testing.T) {
 				path = "/"
 			}
 
-			req := httptest.NewRequest(http.MethodGet, path, nil)
+			method := tc.method
+			if method == "" {
+				method = http.MethodGet
+			}
+
+			req := httptest.NewRequest(method, path, nil)
 			if tc.host != "" {
 				req.Host = tc.host
 			}
@@ -207,6 +239,41 @@ func TestMatchAnyPath(t *testing.T) {
 			paths: []httpv1beta1.PathMatch{{Value: ""}},
 			want:  true,
 		},
+		"explicit prefix type": {
+			path:  "/api/v1",
+			paths: []httpv1beta1.PathMatch{{Type: httpv1beta1.PathMatchPathPrefix, Value: "/api"}},
+			want:  true,
+		},
+		"exact type match": {
+			path:  "/v1/users",
+			paths: []httpv1beta1.PathMatch{{Type: httpv1beta1.PathMatchExact, Value: "/v1/users"}},
+			want:  true,
+		},
+		"exact type rejects sub-path": {
+			path:  "/v1/users/123",
+			paths: []httpv1beta1.PathMatch{{Type: httpv1beta1.PathMatchExact, Value: "/v1/users"}},
+			want:  false,
+		},
+		"exact root only matches root": {
+			path:  "/anything",
+			paths: []httpv1beta1.PathMatch{{Type: httpv1beta1.PathMatchExact, Value: "/"}},
+			want:  false,
+		},
+		"regex type match": {
+			path:  "/v1/users/123",
+			paths: []httpv1beta1.PathMatch{{Type: httpv1beta1.PathMatchRegularExpression, Value: `/v1/users/\d+`}},
+			want:  true,
+		},
+		"regex type must match full path": {
+			path:  "/prefix/v1/users/123",
+			paths: []httpv1beta1.PathMatch{{Type: httpv1beta1.PathMatchRegularExpression, Value: `/v1/users/\d+`}},
+			want:  false,
+		},
+		"invalid regex never matches": {
+			path:  "/v1/(",
+			paths: []httpv1beta1.PathMatch{{Type: httpv1beta1.PathMatchRegularExpression, Value: `/v1/(`}},
+			want:  false,
+		},
 	}
 
 	for name, tc := range tests {
@@ -219,6 +286,237 @@ func TestMatchAnyPath(t *testing.T) {
 	}
 }
 
+func TestMatchedPathPrefix(t *testing.T) {
+	tests := map[string]struct {
+		rules      []httpv1beta1.RoutingRule
+		method     string
+		path       string
+		wantPrefix string
+		wantFound  bool
+	}{
+		"no rules match root": {
+			path:      "/api",
+			wantFound: true,
+		},
+		"rule without paths matches root": {
+			rules:     []httpv1beta1.RoutingRule{{Hosts: []string{"example.com"}}},
+			path:      "/api",
+			wantFound: true,
+		},
+		"longest prefix wins": {
+			rules: []httpv1beta1.RoutingRule{
+				{Paths: []httpv1beta1.PathMatch{{Value: "/api"}}},
+				{Paths: []httpv1beta1.PathMatch{{Value: "/api/orders/"}}},
+			},
+			path:       "/api/orders/1",
+			wantPrefix: "api/orders",
+			wantFound:  true,
+		},
+		"prefix on segment boundary only": {
+			rules:      []httpv1beta1.RoutingRule{{Paths: []httpv1beta1.PathMatch{{Value: "/api"}, {Value: "/api/orders"}}}},
+			path:       "/api/ordersx",
+			wantPrefix: "api",
+			wantFound:  true,
+		},
+		"non-matching rule is skipped": {
+			rules: []httpv1beta1.RoutingRule{
+				{Paths: []httpv1beta1.PathMatch{{Value: "/api/orders"}}, Methods: []httpv1beta1.HTTPMethod{http.MethodPost}},
+				{Paths: []httpv1beta1.PathMatch{{Value: "/api"}}},
+			},
+			method:     http.MethodGet,
+			path:       "/api/orders",
+			wantPrefix: "api",
+			wantFound:  true,
+		},
+		"exact match is not a prefix": {
+			rules:     []httpv1beta1.RoutingRule{{Paths: []httpv1beta1.PathMatch{{Value: "/api", Type: httpv1beta1.PathMatchExact}}}},
+			path:      "/api",
+			wantFound: false,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			r := httptest.NewRequest(method, tt.path, nil)
+
+			prefix, found := MatchedPathPrefix(r, tt.rules)
+			if prefix != tt.wantPrefix || found != tt.wantFound {
+				t.Errorf("MatchedPathPrefix() = (%q, %v), want (%q, %v)", prefix, found, tt.wantPrefix, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestMatchRoutingRule_TableMemoryRegexCache(t *testing.T) {
+	rule := httpv1beta1.RoutingRule{
+		Headers: []httpv1beta1.HeaderMatch{{Name: "X-Version", Type: httpv1beta1.HeaderMatchRegularExpression, Value: ptr.To("v[0-9]+")}},
+	}
+	tm := NewTableMemory()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-Version", "v2")
+	r = r.WithContext(ContextWithTableMemory(r.Context(), tm))
+
+	if !MatchRoutingRule(r, rule) {
+		t.Fatal("MatchRoutingRule() = false, want true")
+	}
+	if _, ok := tm.regexes.regexes.Load("v[0-9]+"); !ok {
+		t.Error("expression not cached by the table of the request")
+	}
+	if _, ok := NewTableMemory().regexes.regexes.Load("v[0-9]+"); ok {
+		t.Error("expression cached by another table")
+	}
+}
+
+func TestValidateRoutingRule(t *testing.T) {
+	tests := map[string]struct {
+		rule    httpv1beta1.RoutingRule
+		wantErr bool
+	}{
+		"without regular expressions": {
+			rule: httpv1beta1.RoutingRule{
+				Paths:   []httpv1beta1.PathMatch{{Value: "/(?!admin)"}},
+				Headers: []httpv1beta1.HeaderMatch{{Name: "X-Version", Value: ptr.To("(")}},
+			},
+		},
+		"valid regular expressions": {
+			rule: httpv1beta1.RoutingRule{
+				Paths:       []httpv1beta1.PathMatch{{Type: httpv1beta1.PathMatchRegularExpression, Value: "/api/v[0-9]+/.*"}},
+				Headers:     []httpv1beta1.HeaderMatch{{Name: "X-Version", Type: httpv1beta1.HeaderMatchRegularExpression, Value: ptr.To("v[0-9]+")}},
+				QueryParams: []httpv1beta1.QueryParamMatch{{Name: "id", Type: httpv1beta1.QueryParamMatchRegularExpression, Value: ptr.To("[a-f0-9]+")}},
+			},
+		},
+		"lookahead path": {
+			rule:    httpv1beta1.RoutingRule{Paths: []httpv1beta1.PathMatch{{Type: httpv1beta1.PathMatchRegularExpression, Value: "/(?!admin).*"}}},
+			wantErr: true,
+		},
+		"unbalanced header": {
+			rule:    httpv1beta1.RoutingRule{Headers: []httpv1beta1.HeaderMatch{{Name: "X-Version", Type: httpv1beta1.HeaderMatchRegularExpression, Value: ptr.To("v(")}}},
+			wantErr: true,
+		},
+		"backreference query parameter": {
+			rule:    httpv1beta1.RoutingRule{QueryParams: []httpv1beta1.QueryParamMatch{{Name: "id", Type: httpv1beta1.QueryParamMatchRegularExpression, Value: ptr.To(`(a)\1`)}}},
+			wantErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := ValidateRoutingRule(tt.rule); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateRoutingRule() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMatchMethod(t *testing.T) {
+	tests := map[string]struct {
+		method  string
+		methods []httpv1beta1.HTTPMethod
+		want    bool
+	}{
+		"empty methods match everything": {method: http.MethodDelete, want: true},
+		"listed method matches":          {method: http.MethodPost, methods: []httpv1beta1.HTTPMethod{http.MethodGet, http.MethodPost}, want: true},
+		"unlisted method does not match": {method: http.MethodPut, methods: []httpv1beta1.HTTPMethod{http.MethodGet, http.MethodPost}, want: false},
+		"method is case sensitive":       {method: "post", methods: []httpv1beta1.HTTPMethod{http.MethodPost}, want: false},
+		"HEAD does not imply GET":        {method: http.MethodHead, methods: []httpv1beta1.HTTPMethod{http.MethodGet}, want: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := MatchMethod(tc.methods, tc.method)
+			if got != tc.want {
+				t.Fatalf("MatchMethod(..., %q) = %v, want %v", tc.method, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMatchQueryParams(t *testing.T) {
+	tests := map[string]struct {
+		matchers []httpv1beta1.QueryParamMatch
+		query    string
+		want     bool
+	}{
+		"empty matchers match everything": {
+			query: "a=1",
+			want:  true,
+		},
+		"implicit exact match": {
+			matchers: []httpv1beta1.QueryParamMatch{{Name: "api-version", Value: ptr.To("v2")}},
+			query:    "api-version=v2",
+			want:     true,
+		},
+		"explicit exact match on any value": {
+			matchers: []httpv1beta1.QueryParamMatch{{Name: "tag", Type: httpv1beta1.QueryParamMatchExact, Value: ptr.To("b")}},
+			query:    "tag=a&tag=b",
+			want:     true,
+		},
+		"exact miss": {
+			matchers: []httpv1beta1.QueryParamMatch{{Name: "api-version", Value: ptr.To("v2")}},
+			query:    "api-version=v1",
+			want:     false,
+		},
+		"name is case sensitive": {
+			matchers: []httpv1beta1.QueryParamMatch{{Name: "api-version", Value: ptr.To("v2")}},
+			query:    "API-Version=v2",
+			want:     false,
+		},
+		"implicit present match": {
+			matchers: []httpv1beta1.QueryParamMatch{{Name: "debug"}},
+			query:    "debug",
+			want:     true,
+		},
+		"explicit present match": {
+			matchers: []httpv1beta1.QueryParamMatch{{Name: "debug", Type: httpv1beta1.QueryParamMatchPresent}},
+			query:    "debug=",
+			want:     true,
+		},
+		"present miss": {
+			matchers: []httpv1beta1.QueryParamMatch{{Name: "debug"}},
+			query:    "other=1",
+			want:     false,
+		},
+		"regex match": {
+			matchers: []httpv1beta1.QueryParamMatch{{Name: "api-version", Type: httpv1beta1.QueryParamMatchRegularExpression, Value: ptr.To(`2024-\d{2}-\d{2}`)}},
+			query:    "api-version=2024-05-01",
+			want:     true,
+		},
+		"regex must match full value": {
+			matchers: []httpv1beta1.QueryParamMatch{{Name: "api-version", Type: httpv1beta1.QueryParamMatchRegularExpression, Value: ptr.To(`2024`)}},
+			query:    "api-version=2024-05-01",
+			want:     false,
+		},
+		"invalid regex never matches": {
+			matchers: []httpv1beta1.QueryParamMatch{{Name: "q", Type: httpv1beta1.QueryParamMatchRegularExpression, Value: ptr.To(`(`)}},
+			query:    "q=(",
+			want:     false,
+		},
+		"all matchers must match": {
+			matchers: []httpv1beta1.QueryParamMatch{{Name: "a", Value: ptr.To("1")}, {Name: "b"}},
+			query:    "a=1",
+			want:     false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			query, err := url.ParseQuery(tc.query)
+			if err != nil {
+				t.Fatalf("parsing query: %v", err)
+			}
+
+			got := MatchQueryParams(tc.matchers, query)
+			if got != tc.want {
+				t.Fatalf("MatchQueryParams(..., %q) = %v, want %v", tc.query, got, tc.want)
+			}
+		})
+	}
+}
+
 func TestMatchHeaders(t *testing.T) {
 	matchers := []httpv1beta1.HeaderMatch{
 		{Name: "Required-Header", Value: ptr.To("expected")},
@@ -293,3 +591,84 @@ func TestMatchHeaders(t *testing.T) {
 		}
 	})
 }
+
+func TestMatchHeadersTypes(t *testing.T) {
+	tests := map[string]struct {
+		matchers []httpv1beta1.HeaderMatch
+		headers  http.Header
+		want     bool
+	}{
+		"explicit exact match": {
+			matchers: []httpv1beta1.HeaderMatch{{Name: "X-Tenant", Type: httpv1beta1.HeaderMatchExact, Value: ptr.To("eu-1")}},
+			headers:  http.Header{"X-Tenant": {"eu-1"}},
+			want:     true,
+		},
+		"explicit present match": {
+			matchers: []httpv1beta1.HeaderMatch{{Name: "X-Tenant", Type: httpv1beta1.HeaderMatchPresent}},
+			headers:  http.Header{"X-Tenant": {"anything"}},
+			want:     true,
+		},
+		"prefix match": {
+			matchers: []httpv1beta1.HeaderMatch{{Name: "X-Tenant", Type: httpv1beta1.HeaderMatchPrefix, Value: ptr.To("eu-")}},
+			headers:  http.Header{"X-Tenant": {"eu-west"}},
+			want:     true,
+		},
+		"prefix match on any value": {
+			matchers: []httpv1beta1.HeaderMatch{{Name: "X-Tenant", Type: httpv1beta1.HeaderMatchPrefix, Value: ptr.To("eu-")}},
+			headers:  http.Header{"X-Tenant": {"us-east", "eu-west"}},
+			want:     true,
+		},
+		"prefix miss": {
+			matchers: []httpv1beta1.HeaderMatch{{Name: "X-Tenant", Type: httpv1beta1.HeaderMatchPrefix, Value: ptr.To("eu-")}},
+			headers:  http.Header{"X-Tenant": {"us-east"}},
+			want:     false,
+		},
+		"prefix requires header": {
+			matchers: []httpv1beta1.HeaderMatch{{Name: "X-Tenant", Type: httpv1beta1.HeaderMatchPrefix, Value: ptr.To("")}},
+			headers:  http.Header{},
+			want:     false,
+		},
+		"regex match": {
+			matchers: []httpv1beta1.HeaderMatch{{Name: "X-Tenant", Type: httpv1beta1.HeaderMatchRegularExpression, Value: ptr.To("(eu|uk)-[a-z]+")}},
+			headers:  http.Header{"X-Tenant": {"uk-south"}},
+			want:     true,
+		},
+		"regex must match full value": {
+			matchers: []httpv1beta1.HeaderMatch{{Name: "X-Tenant", Type: httpv1beta1.HeaderMatchRegularExpression, Value: ptr.To("eu")}},
+			headers:  http.Header{"X-Tenant": {"eu-west"}},
+			want:     false,
+		},
+		"invalid regex never matches": {
+			matchers: []httpv1beta1.HeaderMatch{{Name: "X-Tenant", Type: httpv1beta1.HeaderMatchRegularExpression, Value: ptr.To("(")}},
+			headers:  http.Header{"X-Tenant": {"("}},
+			want:     false,
+		},
+		"not present match": {
+			matchers: []httpv1beta1.HeaderMatch{{Name: "X-Canary", Type: httpv1beta1.HeaderMatchNotPresent}},
+			headers:  http.Header{"Other": {"1"}},
+			want:     true,
+		},
+		"not present miss": {
+			matchers: []httpv1beta1.HeaderMatch{{Name: "X-Canary", Type: httpv1beta1.HeaderMatchNotPresent}},
+			headers:  http.Header{"X-Canary": {""}},
+			want:     false,
+		},
+		"combined with AND semantics": {
+			matchers: []httpv1beta1.HeaderMatch{
+				{Name: "X-Tenant", Type: httpv1beta1.HeaderMatchPrefix, Value: ptr.To("eu-")},
+				{Name: "X-Canary", Type: httpv1beta1.HeaderMatchNotPresent},
+			},
+			headers: http.Header{"X-Tenant": {"eu-west"}, "X-Canary": {"1"}},
+			want:    false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := MatchHeaders(tc.matchers, tc.headers)
+			if got != tc.want {
+				t.Errorf("MatchHeaders()=%v, want=%v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package routing
+
+import (
+	"slices"
+
+	httpv1beta1 "github.com/kedacore/http-add-on/operator/apis/http/v1beta1"
+	"github.com/kedacore/http-add-on/pkg/k8s"
+)
+
+// RefusedHost records a host of a route that was not loaded because another
+// namespace owns it.
+type RefusedHost struct {
+	// Route is the refused route as namespace/name.
+	Route string
+	Host  string
+	// Owner is the HostClaim or route owning the host.
+	Owner string
+}
+
+// hostOwner is the owner of a host pattern.
+type hostOwner struct {
+	// name is the HostClaim name or the namespace/name of the first route.
+	name       string
+	namespaces []string
+}
+
+// hostOwnership tracks which namespaces may route which host patterns.
+type hostOwnership struct {
+	claims    map[string]hostOwner
+	firstCome map[string]hostOwner
+}
+
+func newHostOwnership(claims []httpv1beta1.HostClaim) *hostOwnership {
+	o := &hostOwnership{
+		claims:    make(map[string]hostOwner),
+		firstCome: make(map[string]hostOwner),
+	}
+	for _, hc := range claims {
+		for _, host := range routingHostnames(hc.Spec.Hosts) {
+			owner := o.claims[host]
+			if owner.name == "" {
+				owner.name = hc.Name
+			}
+			owner.namespaces = append(owner.namespaces, hc.Spec.Namespaces...)
+			o.claims[host] = owner
+		}
+	}
+	return o
+}
+
+// claimOwner returns the owner of host through its most specific HostClaim.
+func (o *hostOwnership) claimOwner(host string) (hostOwner, bool) {
+	patterns := append([]string{host}, wildcardHostnames(host)...)
+	patterns = append(patterns, catchAllHostKey)
+	for _, p := range patterns {
+		if owner, ok := o.claims[p]; ok {
+			return owner, true
+		}
+	}
+	return hostOwner{}, false
+}
+
+// firstComeOwner returns the owner of host through the most specific route
+// that claimed it first. A catch-all route only owns the catch-all itself.
+func (o *hostOwnership) firstComeOwner(host string) (hostOwner, bool) {
+	for _, p := range append([]string{host}, wildcardHostnames(host)...) {
+		if owner, ok := o.firstCome[p]; ok {
+			return owner, true
+		}
+	}
+	return hostOwner{}, false
+}
+
+// enforceHostOwnership removes the hosts of routes that are owned by another
+// namespace, either through a HostClaim or, if firstCome is set, through the
+// oldest route using the host. Rules left without hosts are dropped. Routes
+// are returned in their original order.
+func enforceHostOwnership(irs []*httpv1beta1.InterceptorRoute, claims []httpv1beta1.HostClaim, firstCome bool) ([]*httpv1beta1.InterceptorRoute, []RefusedHost) {
+	if len(claims) == 0 && !firstCome {
+		return irs, nil
+	}
+
+	o := newHostOwnership(claims)
+
+	// Claim hosts in creation order so the oldest route wins.
+	byAge := slices.Clone(irs)
+	slices.SortStableFunc(byAge, compareRouteAge)
+
+	refused := make(map[*httpv1beta1.InterceptorRoute]map[string]struct{})
+	var refusals []RefusedHost
+	for _, ir := range byAge {
+		route := k8s.ResourceKey(ir.Namespace, ir.Name)
+		for _, rule := range ir.Spec.Rules {
+			for _, host := range routingHostnames(rule.Hosts) {
+				owner, ok := o.claimOwner(host)
+				if !ok && firstCome {
+					owner, ok = o.firstComeOwner(host)
+					if !ok {
+						o.firstCome[host] = hostOwner{name: route, namespaces: []string{ir.Namespace}}
+						continue
+					}
+				}
+				if !ok || slices.Contains(owner.namespaces, ir.Namespace) {
+					continue
+				}
+
+				if refused[ir] == nil {
+					refused[ir] = make(map[string]struct{})
+				}
+				if _, dup := refused[ir][host]; !dup {
+					refused[ir][host] = struct{}{}
+					refusals = append(refusals, RefusedHost{Route: route, Host: host, Owner: owner.name})
+				}
+			}
+		}
+	}
+
+	if len(refused) == 0 {
+		return irs, nil
+	}
+
+	allowed := make([]*httpv1beta1.InterceptorRoute, 0, len(irs))
+	for _, ir := range irs {
+		if hosts, ok := refused[ir]; ok {
+			ir = withoutHosts(ir, hosts)
+		}
+		allowed = append(allowed, ir)
+	}
+	return allowed, refusals
+}
+
+// withoutHosts returns a copy of ir without the given hosts in its rules.
+func withoutHosts(ir *httpv1beta1.InterceptorRoute, hosts map[string]struct{}) *httpv1beta1.InterceptorRoute {
+	ir = ir.DeepCopy()
+	rules := make([]httpv1beta1.RoutingRule, 0, len(ir.Spec.Rules))
+	for _, rule := range ir.Spec.Rules {
+		if len(rule.Hosts) == 0 {
+			// The catch-all rule is either refused entirely or kept.
+			if _, ok := hosts[catchAllHostKey]; !ok {
+				rules = append(rules, rule)
+			}
+			continue
+		}
+
+		kept := make([]string, 0, len(rule.Hosts))
+		for i, host := range routingHostnames(rule.Hosts) {
+			if _, ok := hosts[host]; !ok {
+				kept = append(kept, rule.Hosts[i])
+			}
+		}
+		if len(kept) > 0 {
+			rule.Hosts = kept
+			rules = append(rules, rule)
+		}
+	}
+	ir.Spec.Rules = rules
+	return ir
+}
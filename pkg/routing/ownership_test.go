@@ -0,0 +1,131 @@
+package routing
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	httpv1beta1 "github.com/kedacore/http-add-on/operator/apis/http/v1beta1"
+)
+
+func TestEnforceHostOwnership(t *testing.T) {
+	newIR := func(namespace, name string, created int64, hosts ...[]string) *httpv1beta1.InterceptorRoute {
+		ir := &httpv1beta1.InterceptorRoute{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, CreationTimestamp: metav1.NewTime(time.Unix(created, 0))},
+		}
+		for _, h := range hosts {
+			ir.Spec.Rules = append(ir.Spec.Rules, httpv1beta1.RoutingRule{Hosts: h})
+		}
+		return ir
+	}
+	newClaim := func(name string, hosts []string, namespaces ...string) httpv1beta1.HostClaim {
+		return httpv1beta1.HostClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       httpv1beta1.HostClaimSpec{Hosts: hosts, Namespaces: namespaces},
+		}
+	}
+
+	tests := map[string]struct {
+		irs         []*httpv1beta1.InterceptorRoute
+		claims      []httpv1beta1.HostClaim
+		firstCome   bool
+		wantHosts   [][][]string
+		wantRefused []RefusedHost
+	}{
+		"disabled": {
+			irs: []*httpv1beta1.InterceptorRoute{
+				newIR("a", "first", 1, []string{"example.com"}),
+				newIR("b", "second", 2, []string{"example.com"}),
+			},
+			wantHosts: [][][]string{{{"example.com"}}, {{"example.com"}}},
+		},
+		"first come oldest route wins": {
+			irs: []*httpv1beta1.InterceptorRoute{
+				newIR("b", "newer", 2, []string{"example.com", "other.com"}),
+				newIR("a", "older", 1, []string{"example.com"}),
+			},
+			firstCome:   true,
+			wantHosts:   [][][]string{{{"other.com"}}, {{"example.com"}}},
+			wantRefused: []RefusedHost{{Route: "b/newer", Host: "example.com", Owner: "a/older"}},
+		},
+		"first come same namespace shares host": {
+			irs: []*httpv1beta1.InterceptorRoute{
+				newIR("a", "first", 1, []string{"example.com"}),
+				newIR("a", "second", 2, []string{"example.com"}),
+			},
+			firstCome: true,
+			wantHosts: [][][]string{{{"example.com"}}, {{"example.com"}}},
+		},
+		"first come wildcard owns subdomains": {
+			irs: []*httpv1beta1.InterceptorRoute{
+				newIR("a", "wildcard", 1, []string{"*.example.com"}),
+				newIR("b", "sub", 2, []string{"api.example.com"}),
+			},
+			firstCome:   true,
+			wantHosts:   [][][]string{{{"*.example.com"}}, nil},
+			wantRefused: []RefusedHost{{Route: "b/sub", Host: "api.example.com", Owner: "a/wildcard"}},
+		},
+		"first come catch-all owns only itself": {
+			irs: []*httpv1beta1.InterceptorRoute{
+				newIR("a", "catch-all", 1, nil),
+				newIR("b", "host", 2, []string{"example.com"}),
+				newIR("c", "catch-all", 3, []string{"*"}),
+			},
+			firstCome:   true,
+			wantHosts:   [][][]string{{nil}, {{"example.com"}}, nil},
+			wantRefused: []RefusedHost{{Route: "c/catch-all", Host: "*", Owner: "a/catch-all"}},
+		},
+		"host claim overrides first come": {
+			irs: []*httpv1beta1.InterceptorRoute{
+				newIR("a", "older", 1, []string{"example.com"}),
+				newIR("b", "newer", 2, []string{"example.com"}),
+			},
+			claims:      []httpv1beta1.HostClaim{newClaim("example", []string{"example.com"}, "b")},
+			firstCome:   true,
+			wantHosts:   [][][]string{nil, {{"example.com"}}},
+			wantRefused: []RefusedHost{{Route: "a/older", Host: "example.com", Owner: "example"}},
+		},
+		"most specific claim governs": {
+			irs: []*httpv1beta1.InterceptorRoute{
+				newIR("a", "api", 1, []string{"api.example.com"}, []string{"www.example.com"}),
+				newIR("b", "www", 2, []string{"www.example.com"}),
+			},
+			claims: []httpv1beta1.HostClaim{
+				newClaim("domain", []string{"*.example.com"}, "b"),
+				newClaim("api", []string{"api.example.com"}, "a"),
+			},
+			wantHosts:   [][][]string{{{"api.example.com"}}, {{"www.example.com"}}},
+			wantRefused: []RefusedHost{{Route: "a/api", Host: "www.example.com", Owner: "domain"}},
+		},
+		"catch-all claim": {
+			irs: []*httpv1beta1.InterceptorRoute{
+				newIR("a", "tenant", 1, []string{"example.com"}),
+				newIR("b", "unconstrained", 2, nil),
+			},
+			claims:      []httpv1beta1.HostClaim{newClaim("all", []string{"*"}, "a")},
+			wantHosts:   [][][]string{{{"example.com"}}, nil},
+			wantRefused: []RefusedHost{{Route: "b/unconstrained", Host: "*", Owner: "all"}},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, refused := enforceHostOwnership(tt.irs, tt.claims, tt.firstCome)
+
+			gotHosts := make([][][]string, len(got))
+			for i, ir := range got {
+				for _, rule := range ir.Spec.Rules {
+					gotHosts[i] = append(gotHosts[i], rule.Hosts)
+				}
+			}
+			if !reflect.DeepEqual(gotHosts, tt.wantHosts) {
+				t.Errorf("hosts = %v, want %v", gotHosts, tt.wantHosts)
+			}
+			if !reflect.DeepEqual(refused, tt.wantRefused) {
+				t.Errorf("refused = %+v, want %+v", refused, tt.wantRefused)
+			}
+		})
+	}
+}
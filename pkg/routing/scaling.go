@@ -0,0 +1,106 @@
+package routing
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	httpv1beta1 "github.com/kedacore/http-add-on/operator/apis/http/v1beta1"
+)
+
+// Annotations of HTTPRoutes and Ingresses configuring how their backends are
+// scaled. Without any of them, backends are scaled on concurrency with a
+// target of 100.
+const (
+	ConcurrencyTargetAnnotation      = "http.keda.sh/concurrency-target-value"
+	RequestRateTargetAnnotation      = "http.keda.sh/request-rate-target-value"
+	RequestRateWindowAnnotation      = "http.keda.sh/request-rate-window"
+	RequestRateGranularityAnnotation = "http.keda.sh/request-rate-granularity"
+)
+
+const defaultHTTPRouteConcurrencyTarget = 100
+
+// scalingRoute merges the routes converted from a single HTTPRoute or Ingress
+// into one InterceptorRoute holding the backends of all of them, so that the
+// scaler can report metrics for any or all of its backends.
+func scalingRoute(irs []*httpv1beta1.InterceptorRoute, err error) (*httpv1beta1.InterceptorRoute, error) {
+	if len(irs) == 0 {
+		if err == nil {
+			err = errors.New("no routable rules")
+		}
+		return nil, err
+	}
+
+	var backends []httpv1beta1.WeightedBackend
+	seen := make(map[string]struct{})
+	for _, ir := range irs {
+		for _, b := range ir.Spec.Backends {
+			if _, ok := seen[b.Service]; ok {
+				continue
+			}
+			seen[b.Service] = struct{}{}
+			backends = append(backends, b)
+		}
+	}
+
+	ir := irs[0]
+	ir.Spec.Backends = backends
+	return ir, nil
+}
+
+// scalingMetricFromAnnotations reads the scaling metric of an HTTPRoute or
+// Ingress from its annotations.
+func scalingMetricFromAnnotations(annotations map[string]string) (httpv1beta1.ScalingMetricSpec, error) {
+	var sm httpv1beta1.ScalingMetricSpec
+
+	if v, ok := annotations[ConcurrencyTargetAnnotation]; ok {
+		target, err := parseTargetValue(ConcurrencyTargetAnnotation, v)
+		if err != nil {
+			return sm, err
+		}
+		sm.Concurrency = &httpv1beta1.ConcurrencyTargetSpec{TargetValue: target}
+	}
+
+	if v, ok := annotations[RequestRateTargetAnnotation]; ok {
+		target, err := parseTargetValue(RequestRateTargetAnnotation, v)
+		if err != nil {
+			return sm, err
+		}
+		rr := &httpv1beta1.RequestRateTargetSpec{
+			TargetValue: target,
+			Window:      metav1.Duration{Duration: time.Minute},
+			Granularity: metav1.Duration{Duration: time.Second},
+		}
+		for key, d := range map[string]*metav1.Duration{
+			RequestRateWindowAnnotation:      &rr.Window,
+			RequestRateGranularityAnnotation: &rr.Granularity,
+		} {
+			v, ok := annotations[key]
+			if !ok {
+				continue
+			}
+			parsed, err := time.ParseDuration(v)
+			if err != nil || parsed <= 0 {
+				return sm, fmt.Errorf("invalid annotation %s=%q: expected a positive duration", key, v)
+			}
+			d.Duration = parsed
+		}
+		sm.RequestRate = rr
+	}
+
+	if sm.Concurrency == nil && sm.RequestRate == nil {
+		sm.Concurrency = &httpv1beta1.ConcurrencyTargetSpec{TargetValue: defaultHTTPRouteConcurrencyTarget}
+	}
+	return sm, nil
+}
+
+func parseTargetValue(key, v string) (int32, error) {
+	target, err := strconv.ParseInt(v, 10, 32)
+	if err != nil || target < 1 {
+		return 0, fmt.Errorf("invalid annotation %s=%q: expected a positive integer", key, v)
+	}
+	return int32(target), nil
+}
@@ -0,0 +1,55 @@
+package routing
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	httpv1beta1 "github.com/kedacore/http-add-on/operator/apis/http/v1beta1"
+)
+
+func TestScalingMetricFromAnnotations(t *testing.T) {
+	tests := map[string]struct {
+		annotations map[string]string
+		want        httpv1beta1.ScalingMetricSpec
+		wantErr     bool
+	}{
+		"default": {
+			want: httpv1beta1.ScalingMetricSpec{Concurrency: &httpv1beta1.ConcurrencyTargetSpec{TargetValue: 100}},
+		},
+		"concurrency": {
+			annotations: map[string]string{ConcurrencyTargetAnnotation: "5"},
+			want:        httpv1beta1.ScalingMetricSpec{Concurrency: &httpv1beta1.ConcurrencyTargetSpec{TargetValue: 5}},
+		},
+		"request rate": {
+			annotations: map[string]string{RequestRateTargetAnnotation: "50", RequestRateWindowAnnotation: "30s"},
+			want: httpv1beta1.ScalingMetricSpec{RequestRate: &httpv1beta1.RequestRateTargetSpec{
+				TargetValue: 50,
+				Window:      metav1.Duration{Duration: 30 * time.Second},
+				Granularity: metav1.Duration{Duration: time.Second},
+			}},
+		},
+		"invalid target": {
+			annotations: map[string]string{ConcurrencyTargetAnnotation: "0"},
+			wantErr:     true,
+		},
+		"invalid window": {
+			annotations: map[string]string{RequestRateTargetAnnotation: "50", RequestRateWindowAnnotation: "soon"},
+			wantErr:     true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := scalingMetricFromAnnotations(tt.annotations)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("scalingMetricFromAnnotations() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("scalingMetricFromAnnotations() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -5,10 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"time"
 
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	httpv1alpha1 "github.com/kedacore/http-add-on/operator/apis/http/v1alpha1"
 	httpv1beta1 "github.com/kedacore/http-add-on/operator/apis/http/v1beta1"
@@ -24,6 +28,8 @@ type Table interface {
 
 	HasSynced() bool
 	Route(req *http.Request) *httpv1beta1.InterceptorRoute
+	// Snapshot returns the current routing table contents, nil until synced.
+	Snapshot() *TableMemory
 	Signal()
 	Start(ctx context.Context) error
 }
@@ -34,16 +40,32 @@ type table struct {
 	previousKeys   map[string]struct{}
 	reader         client.Reader
 	queueCounter   queue.Counter
+	opts           TableOptions
+}
+
+// TableOptions configures the routes loaded into a Table.
+type TableOptions struct {
+	// FirstComeHostOwnership makes the namespace of the oldest route using a
+	// host not claimed by a HostClaim its owner, see enforceHostOwnership.
+	FirstComeHostOwnership bool
+	// HTTPRouteParent, if set, loads the Gateway API HTTPRoutes referencing it.
+	HTTPRouteParent *HTTPRouteParent
+	// IngressClassName, if set, loads the Ingresses of this IngressClass.
+	IngressClassName string
 }
 
 var _ Table = (*table)(nil)
 
-func NewTable(reader client.Reader, counter queue.Counter) Table {
+// NewTable creates a routing table from the InterceptorRoutes and
+// HTTPScaledObjects read from reader. Hosts claimed by a HostClaim are only
+// routed for its namespaces.
+func NewTable(reader client.Reader, counter queue.Counter, opts TableOptions) Table {
 	return &table{
 		memorySignaler: util.NewSignaler(),
 		previousKeys:   make(map[string]struct{}),
 		queueCounter:   counter,
 		reader:         reader,
+		opts:           opts,
 	}
 }
 
@@ -54,16 +76,36 @@ func (t *table) refreshMemory(ctx context.Context) error {
 			return fmt.Errorf("failed to list InterceptorRoutes: %w", err)
 		}
 
-		tm := NewTableMemory()
+		var routes []*httpv1beta1.InterceptorRoute
 		currentKeys := make(map[string]struct{})
+		routeKeys := make(map[string]struct{})
 
 		for i := range irList.Items {
 			ir := &irList.Items[i]
 			key := k8s.ResourceKey(ir.Namespace, ir.Name)
 
-			currentKeys[key] = struct{}{}
+			routeKeys[key] = struct{}{}
 
-			tm = tm.Remember(ir)
+			routes = append(routes, ir)
+
+			// Mirrored requests are counted apart from the route, see middleware.Mirror.
+			if m := ir.Spec.Mirror; m != nil && m.CountRequests {
+				mirrorKey := k8s.BackendResourceKey(ir.Namespace, ir.Name, m.Service)
+				currentKeys[mirrorKey] = struct{}{}
+				t.queueCounter.EnsureKey(mirrorKey)
+			}
+
+			// Weighted backends are counted per backend, see middleware.Counting.
+			if len(ir.Spec.Backends) > 0 {
+				for _, b := range ir.Spec.Backends {
+					backendKey := k8s.BackendResourceKey(ir.Namespace, ir.Name, b.Service)
+					currentKeys[backendKey] = struct{}{}
+					t.queueCounter.EnsureKey(backendKey)
+				}
+				continue
+			}
+
+			currentKeys[key] = struct{}{}
 
 			t.queueCounter.EnsureKey(key)
 		}
@@ -78,10 +120,11 @@ func (t *table) refreshMemory(ctx context.Context) error {
 			httpso := &httpsoList.Items[i]
 			key := fmt.Sprintf("%s/%s", httpso.Namespace, httpso.Name)
 
-			if _, ok := currentKeys[key]; ok {
+			if _, ok := routeKeys[key]; ok {
 				// skip the conflicting HTTPSO, IR takes precedence
 				continue
 			}
+			routeKeys[key] = struct{}{}
 			currentKeys[key] = struct{}{}
 
 			// Create an IR from the HTTPSO to simplify the whole routing logic
@@ -156,11 +199,86 @@ func (t *table) refreshMemory(ctx context.Context) error {
 				}
 			}
 
-			tm = tm.Remember(ir)
+			routes = append(routes, ir)
 
 			t.queueCounter.EnsureKey(key)
 		}
 
+		if parent := t.opts.HTTPRouteParent; parent != nil {
+			var hrList gatewayv1.HTTPRouteList
+			if err := t.reader.List(ctx, &hrList); err != nil {
+				return fmt.Errorf("listing HTTPRoutes: %w", err)
+			}
+
+			for i := range hrList.Items {
+				hr := &hrList.Items[i]
+				key := k8s.ResourceKey(hr.Namespace, hr.Name)
+
+				if !parent.ReferencedBy(hr) {
+					continue
+				}
+				if _, ok := routeKeys[key]; ok {
+					// Routes of the same name share queue keys: InterceptorRoutes
+					// and HTTPScaledObjects take precedence.
+					util.LoggerFromContext(ctx).Info("skipping HTTPRoute conflicting with a route of the same name", "httpRoute", key)
+					continue
+				}
+				routeKeys[key] = struct{}{}
+
+				irs, err := InterceptorRoutesFromHTTPRoute(hr)
+				if err != nil {
+					util.LoggerFromContext(ctx).Error(err, "skipping HTTPRoute rules", "httpRoute", key)
+				}
+				routes = t.appendConverted(routes, currentKeys, irs)
+			}
+		}
+
+		if class := t.opts.IngressClassName; class != "" {
+			var ingList networkingv1.IngressList
+			if err := t.reader.List(ctx, &ingList); err != nil {
+				return fmt.Errorf("listing Ingresses: %w", err)
+			}
+
+			for i := range ingList.Items {
+				ing := &ingList.Items[i]
+				key := k8s.ResourceKey(ing.Namespace, ing.Name)
+
+				if !IngressHasClass(ing, class) {
+					continue
+				}
+				if _, ok := routeKeys[key]; ok {
+					// Routes of the same name share queue keys: InterceptorRoutes,
+					// HTTPScaledObjects and HTTPRoutes take precedence.
+					util.LoggerFromContext(ctx).Info("skipping Ingress conflicting with a route of the same name", "ingress", key)
+					continue
+				}
+				routeKeys[key] = struct{}{}
+
+				irs, err := InterceptorRoutesFromIngress(ing)
+				if err != nil {
+					util.LoggerFromContext(ctx).Error(err, "skipping Ingress paths", "ingress", key)
+				}
+				routes = t.appendConverted(routes, currentKeys, irs)
+			}
+		}
+
+		// The HostClaim CRD may not be installed, which means there are no
+		// claims: routing must not depend on it.
+		var claimList httpv1beta1.HostClaimList
+		if err := t.reader.List(ctx, &claimList); err != nil && !meta.IsNoMatchError(err) {
+			return fmt.Errorf("listing HostClaims: %w", err)
+		}
+
+		routes, refused := enforceHostOwnership(routes, claimList.Items, t.opts.FirstComeHostOwnership)
+		for _, r := range refused {
+			util.LoggerFromContext(ctx).Info("refusing to route host owned by another namespace", "route", r.Route, "host", r.Host, "owner", r.Owner)
+		}
+
+		tm := NewTableMemory()
+		for _, ir := range routes {
+			tm = tm.Remember(ir)
+		}
+
 		for key := range t.previousKeys {
 			if _, exists := currentKeys[key]; !exists {
 				t.queueCounter.RemoveKey(key)
@@ -176,6 +294,20 @@ func (t *table) refreshMemory(ctx context.Context) error {
 	}
 }
 
+// appendConverted appends the routes converted from a single HTTPRoute or
+// Ingress to routes. Backends are counted per Service across all of them.
+func (t *table) appendConverted(routes []*httpv1beta1.InterceptorRoute, currentKeys map[string]struct{}, irs []*httpv1beta1.InterceptorRoute) []*httpv1beta1.InterceptorRoute {
+	for _, ir := range irs {
+		for _, b := range ir.Spec.Backends {
+			backendKey := k8s.BackendResourceKey(ir.Namespace, ir.Name, b.Service)
+			currentKeys[backendKey] = struct{}{}
+			t.queueCounter.EnsureKey(backendKey)
+		}
+		routes = append(routes, ir)
+	}
+	return routes
+}
+
 func (t *table) Signal() {
 	t.memorySignaler.Signal()
 }
@@ -196,7 +328,17 @@ func (t *table) Route(req *http.Request) *httpv1beta1.InterceptorRoute {
 
 	hostname := StripPort(req.Host)
 
-	return tm.Route(hostname, req.URL.Path, req.Header)
+	// Avoid allocating for the common case of requests without a query.
+	var query url.Values
+	if req.URL.RawQuery != "" {
+		query = req.URL.Query()
+	}
+
+	return tm.Route(hostname, req.URL.Path, req.Method, query, req.Header)
+}
+
+func (t *table) Snapshot() *TableMemory {
+	return t.memoryHolder.Get()
 }
 
 func (t *table) HasSynced() bool {
@@ -8,9 +8,13 @@ import (
 	"testing"
 	"time"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	httpv1alpha1 "github.com/kedacore/http-add-on/operator/apis/http/v1alpha1"
 	httpv1beta1 "github.com/kedacore/http-add-on/operator/apis/http/v1beta1"
@@ -48,7 +52,7 @@ func startTableAndWaitForSync(t *testing.T, tbl Table) context.CancelFunc {
 
 func TestTableRoute_NilRequest(t *testing.T) {
 	cl := newTestClient()
-	tbl := NewTable(cl, queue.NewMemory())
+	tbl := NewTable(cl, queue.NewMemory(), TableOptions{})
 
 	cancel := startTableAndWaitForSync(t, tbl)
 	defer cancel()
@@ -86,7 +90,7 @@ func TestTableRoute(t *testing.T) {
 			}
 
 			cl := newTestClient(objs...)
-			tbl := NewTable(cl, queue.NewMemory())
+			tbl := NewTable(cl, queue.NewMemory(), TableOptions{})
 
 			cancel := startTableAndWaitForSync(t, tbl)
 			defer cancel()
@@ -107,9 +111,271 @@ func TestTableRoute(t *testing.T) {
 	}
 }
 
+func TestTableRouteMethod(t *testing.T) {
+	ir := &httpv1beta1.InterceptorRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "write", Namespace: "default"},
+		Spec: httpv1beta1.InterceptorRouteSpec{
+			Rules: []httpv1beta1.RoutingRule{{
+				Hosts:   []string{"example.com"},
+				Methods: []httpv1beta1.HTTPMethod{http.MethodPost},
+			}},
+		},
+	}
+
+	tbl := NewTable(newTestClient(ir), queue.NewMemory(), TableOptions{})
+	cancel := startTableAndWaitForSync(t, tbl)
+	defer cancel()
+
+	postReq, _ := http.NewRequest(http.MethodPost, "http://example.com/ingest", nil)
+	if result := tbl.Route(postReq); result == nil || result.Name != ir.Name {
+		t.Errorf("expected %q for POST, got %v", ir.Name, result)
+	}
+
+	getReq, _ := http.NewRequest(http.MethodGet, "http://example.com/ingest", nil)
+	if tbl.Route(getReq) != nil {
+		t.Error("expected nil for GET")
+	}
+}
+
+func TestTableRouteHostOwnership(t *testing.T) {
+	older := &httpv1beta1.InterceptorRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "owner", Namespace: "team-a", CreationTimestamp: metav1.NewTime(time.Unix(100, 0))},
+		Spec: httpv1beta1.InterceptorRouteSpec{
+			Rules: []httpv1beta1.RoutingRule{{Hosts: []string{"example.com"}}},
+		},
+	}
+	newer := &httpv1beta1.InterceptorRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "hijacker", Namespace: "team-b", CreationTimestamp: metav1.NewTime(time.Unix(200, 0))},
+		Spec: httpv1beta1.InterceptorRouteSpec{
+			Rules: []httpv1beta1.RoutingRule{{
+				Hosts:   []string{"example.com"},
+				Headers: []httpv1beta1.HeaderMatch{{Name: "X-Hijack"}},
+			}},
+		},
+	}
+	claim := &httpv1beta1.HostClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "example"},
+		Spec:       httpv1beta1.HostClaimSpec{Hosts: []string{"*.com"}, Namespaces: []string{"team-b"}},
+	}
+
+	tests := map[string]struct {
+		objs      []client.Object
+		firstCome bool
+		want      string
+	}{
+		"no ownership": {
+			objs: []client.Object{older, newer},
+			want: "hijacker",
+		},
+		"first come": {
+			objs:      []client.Object{older, newer},
+			firstCome: true,
+			want:      "owner",
+		},
+		"host claim": {
+			objs:      []client.Object{older, newer, claim},
+			firstCome: true,
+			want:      "hijacker",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tbl := NewTable(newTestClient(tt.objs...), queue.NewMemory(), TableOptions{FirstComeHostOwnership: tt.firstCome})
+			cancel := startTableAndWaitForSync(t, tbl)
+			defer cancel()
+
+			req, _ := http.NewRequest(http.MethodGet, "http://example.com/test", nil)
+			req.Header.Set("X-Hijack", "1")
+			if result := tbl.Route(req); result == nil || result.Name != tt.want {
+				t.Errorf("expected %q, got %v", tt.want, result)
+			}
+		})
+	}
+}
+
+func TestTableRouteWithoutHostClaimCRD(t *testing.T) {
+	ir := &httpv1beta1.InterceptorRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: httpv1beta1.InterceptorRouteSpec{
+			Rules: []httpv1beta1.RoutingRule{{Hosts: []string{"example.com"}}},
+		},
+	}
+	reader := fake.NewClientBuilder().
+		WithScheme(kedacache.NewScheme()).
+		WithObjects(ir).
+		WithInterceptorFuncs(interceptor.Funcs{
+			List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+				if _, ok := list.(*httpv1beta1.HostClaimList); ok {
+					return &meta.NoKindMatchError{GroupKind: schema.GroupKind{Group: httpv1beta1.SchemeGroupVersion.Group, Kind: "HostClaim"}}
+				}
+				return c.List(ctx, list, opts...)
+			},
+		}).
+		Build()
+
+	tbl := NewTable(reader, queue.NewMemory(), TableOptions{})
+	cancel := startTableAndWaitForSync(t, tbl)
+	defer cancel()
+
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com/test", nil)
+	if result := tbl.Route(req); result == nil || result.Name != "web" {
+		t.Errorf("expected %q, got %v", "web", result)
+	}
+}
+
+func TestTableRouteHTTPRoute(t *testing.T) {
+	hr := newTestHTTPRoute(gatewayv1.HTTPRouteRule{
+		BackendRefs: []gatewayv1.HTTPBackendRef{testBackendRef("web", nil)},
+	})
+	other := newTestHTTPRoute(gatewayv1.HTTPRouteRule{
+		BackendRefs: []gatewayv1.HTTPBackendRef{testBackendRef("other", nil)},
+	})
+	other.Name = "other"
+	other.Spec.Hostnames = []gatewayv1.Hostname{"other.example.com"}
+	other.Spec.ParentRefs = []gatewayv1.ParentReference{{Name: "public"}}
+
+	tests := map[string]struct {
+		parent *HTTPRouteParent
+		want   string
+	}{
+		"disabled": {},
+		"referenced parent": {
+			parent: &HTTPRouteParent{Kind: "Service", Namespace: "keda", Name: "interceptor-proxy"},
+			want:   "web",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			counter := queue.NewMemory()
+			tbl := NewTable(newTestClient(hr, other), counter, TableOptions{HTTPRouteParent: tt.parent})
+			cancel := startTableAndWaitForSync(t, tbl)
+			defer cancel()
+
+			req, _ := http.NewRequest(http.MethodGet, "http://example.com/test", nil)
+			result := tbl.Route(req)
+			if tt.want == "" {
+				if result != nil {
+					t.Errorf("expected nil, got %v", result)
+				}
+				return
+			}
+			if result == nil || result.Name != tt.want {
+				t.Fatalf("expected %q, got %v", tt.want, result)
+			}
+
+			otherReq, _ := http.NewRequest(http.MethodGet, "http://other.example.com/test", nil)
+			if tbl.Route(otherReq) != nil {
+				t.Error("expected nil for HTTPRoute with another parent")
+			}
+
+			counts, err := counter.Current()
+			if err != nil {
+				t.Fatalf("failed to get current counts: %v", err)
+			}
+			key := "default/web/web"
+			if _, exists := counts[key]; !exists {
+				t.Errorf("expected queue counter to have key %q", key)
+			}
+		})
+	}
+}
+
+func TestTableRouteIngress(t *testing.T) {
+	ing := newTestIngress("keda-http")
+	other := newTestIngress("nginx")
+	other.Name = "other"
+	other.Spec.Rules[0].Host = "other.example.com"
+
+	tests := map[string]struct {
+		class string
+		want  string
+	}{
+		"disabled":       {},
+		"matching class": {class: "keda-http", want: "shop"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			counter := queue.NewMemory()
+			tbl := NewTable(newTestClient(ing, other), counter, TableOptions{IngressClassName: tt.class})
+			cancel := startTableAndWaitForSync(t, tbl)
+			defer cancel()
+
+			req, _ := http.NewRequest(http.MethodGet, "http://shop.example.com/cart", nil)
+			result := tbl.Route(req)
+			if tt.want == "" {
+				if result != nil {
+					t.Errorf("expected nil, got %v", result)
+				}
+				return
+			}
+			if result == nil || result.Name != tt.want || result.Spec.Backends[0].Service != "cart" {
+				t.Fatalf("expected %q routing to cart, got %v", tt.want, result)
+			}
+
+			otherReq, _ := http.NewRequest(http.MethodGet, "http://other.example.com/", nil)
+			if tbl.Route(otherReq) != nil {
+				t.Error("expected nil for Ingress of another class")
+			}
+
+			counts, err := counter.Current()
+			if err != nil {
+				t.Fatalf("failed to get current counts: %v", err)
+			}
+			for _, key := range []string{"default/shop/cart", "default/shop/web"} {
+				if _, exists := counts[key]; !exists {
+					t.Errorf("expected queue counter to have key %q", key)
+				}
+			}
+		})
+	}
+}
+
+func TestTableRouteConflictingSources(t *testing.T) {
+	httpso := &httpv1alpha1.HTTPScaledObject{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       httpv1alpha1.HTTPScaledObjectSpec{Hosts: []string{"httpso.example.com"}},
+	}
+	// Same name as the HTTPScaledObject.
+	hr := newTestHTTPRoute(gatewayv1.HTTPRouteRule{
+		BackendRefs: []gatewayv1.HTTPBackendRef{testBackendRef("web", nil)},
+	})
+	hr.Spec.Hostnames = []gatewayv1.Hostname{"httproute.example.com"}
+	// Same name as the Ingress.
+	shop := newTestHTTPRoute(gatewayv1.HTTPRouteRule{
+		BackendRefs: []gatewayv1.HTTPBackendRef{testBackendRef("cart", nil)},
+	})
+	shop.Name = "shop"
+	shop.Spec.Hostnames = []gatewayv1.Hostname{"shop-route.example.com"}
+	ing := newTestIngress("keda-http")
+
+	tbl := NewTable(newTestClient(httpso, hr, shop, ing), queue.NewMemory(), TableOptions{
+		HTTPRouteParent:  &HTTPRouteParent{Kind: "Service", Namespace: "keda", Name: "interceptor-proxy"},
+		IngressClassName: "keda-http",
+	})
+	cancel := startTableAndWaitForSync(t, tbl)
+	defer cancel()
+
+	tests := map[string]bool{
+		"http://httpso.example.com/":        true,
+		"http://httproute.example.com/":     false,
+		"http://shop-route.example.com/":    true,
+		"http://shop.example.com/cart":      false,
+		"http://shop.example.com/somewhere": false,
+	}
+	for url, want := range tests {
+		req, _ := http.NewRequest(http.MethodGet, url, nil)
+		if got := tbl.Route(req) != nil; got != want {
+			t.Errorf("%s routed = %t, want %t", url, got, want)
+		}
+	}
+}
+
 func TestTableHasSynced(t *testing.T) {
 	cl := newTestClient()
-	tbl := NewTable(cl, queue.NewMemory())
+	tbl := NewTable(cl, queue.NewMemory(), TableOptions{})
 
 	// Initially not synced
 	if tbl.HasSynced() {
@@ -127,7 +393,7 @@ func TestTableHasSynced(t *testing.T) {
 
 func TestTableHealthCheck(t *testing.T) {
 	cl := newTestClient()
-	tbl := NewTable(cl, queue.NewMemory())
+	tbl := NewTable(cl, queue.NewMemory(), TableOptions{})
 
 	ctx := context.Background()
 
@@ -159,7 +425,7 @@ func TestTableSignal_TriggersRefresh(t *testing.T) {
 	}
 
 	cl := newTestClient(ir)
-	tbl := NewTable(cl, queue.NewMemory())
+	tbl := NewTable(cl, queue.NewMemory(), TableOptions{})
 
 	cancel := startTableAndWaitForSync(t, tbl)
 	defer cancel()
@@ -207,7 +473,7 @@ func TestTableSignal_TriggersRefresh(t *testing.T) {
 
 func TestTableRefreshMemory_CancelsOnContextDone(t *testing.T) {
 	cl := newTestClient()
-	tbl := NewTable(cl, queue.NewMemory())
+	tbl := NewTable(cl, queue.NewMemory(), TableOptions{})
 
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -254,7 +520,7 @@ func TestTableQueueCounterIntegration(t *testing.T) {
 
 	cl := newTestClient(ir)
 	counter := queue.NewMemory()
-	tbl := NewTable(cl, counter)
+	tbl := NewTable(cl, counter, TableOptions{})
 
 	cancel := startTableAndWaitForSync(t, tbl)
 	defer cancel()
@@ -269,6 +535,43 @@ func TestTableQueueCounterIntegration(t *testing.T) {
 	}
 }
 
+func TestTableQueueCounterIntegrationBackendKeys(t *testing.T) {
+	ir := &httpv1beta1.InterceptorRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-ir", Namespace: "default"},
+		Spec: httpv1beta1.InterceptorRouteSpec{
+			Backends: []httpv1beta1.WeightedBackend{
+				{TargetRef: httpv1beta1.TargetRef{Service: "stable", Port: 8080}},
+				{TargetRef: httpv1beta1.TargetRef{Service: "canary", Port: 8080}},
+			},
+			Mirror: &httpv1beta1.MirrorSpec{
+				TargetRef:     httpv1beta1.TargetRef{Service: "shadow", Port: 8080},
+				CountRequests: true,
+			},
+			Rules: []httpv1beta1.RoutingRule{{Hosts: []string{"example.com"}}},
+		},
+	}
+
+	cl := newTestClient(ir)
+	counter := queue.NewMemory()
+	tbl := NewTable(cl, counter, TableOptions{})
+
+	cancel := startTableAndWaitForSync(t, tbl)
+	defer cancel()
+
+	counts, err := counter.Current()
+	if err != nil {
+		t.Fatalf("failed to get current counts: %v", err)
+	}
+	for _, key := range []string{"default/test-ir/stable", "default/test-ir/canary", "default/test-ir/shadow"} {
+		if _, exists := counts[key]; !exists {
+			t.Errorf("expected queue counter to have key %q", key)
+		}
+	}
+	if _, exists := counts["default/test-ir"]; exists {
+		t.Errorf("expected queue counter to not have route key %q", "default/test-ir")
+	}
+}
+
 func TestTableSignal_DeletedObjectBecomesUnroutable(t *testing.T) {
 	ir := &httpv1beta1.InterceptorRoute{
 		ObjectMeta: metav1.ObjectMeta{Name: "to-delete", Namespace: "default"},
@@ -279,7 +582,7 @@ func TestTableSignal_DeletedObjectBecomesUnroutable(t *testing.T) {
 
 	cl := newTestClient(ir)
 	counter := queue.NewMemory()
-	tbl := NewTable(cl, counter)
+	tbl := NewTable(cl, counter, TableOptions{})
 
 	cancel := startTableAndWaitForSync(t, tbl)
 	defer cancel()
@@ -356,7 +659,7 @@ func TestTableRouteHTTPSO(t *testing.T) {
 			}
 
 			cl := newTestClient(objs...)
-			tbl := NewTable(cl, queue.NewMemory())
+			tbl := NewTable(cl, queue.NewMemory(), TableOptions{})
 
 			cancel := startTableAndWaitForSync(t, tbl)
 			defer cancel()
@@ -389,7 +692,7 @@ func TestTableSignalHTTPSO_TriggersRefresh(t *testing.T) {
 	}
 
 	cl := newTestClient(httpso)
-	tbl := NewTable(cl, queue.NewMemory())
+	tbl := NewTable(cl, queue.NewMemory(), TableOptions{})
 
 	cancel := startTableAndWaitForSync(t, tbl)
 	defer cancel()
@@ -492,7 +795,7 @@ func TestTableHTTPSOTimeoutConversion(t *testing.T) {
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
 			cl := newTestClient(tc.httpso)
-			tbl := NewTable(cl, queue.NewMemory())
+			tbl := NewTable(cl, queue.NewMemory(), TableOptions{})
 
 			cancel := startTableAndWaitForSync(t, tbl)
 			defer cancel()
@@ -533,7 +836,7 @@ func TestTableQueueCounterIntegrationHTTPSO(t *testing.T) {
 
 	cl := newTestClient(httpso)
 	counter := queue.NewMemory()
-	tbl := NewTable(cl, counter)
+	tbl := NewTable(cl, counter, TableOptions{})
 
 	cancel := startTableAndWaitForSync(t, tbl)
 	defer cancel()
@@ -562,7 +865,7 @@ func TestTableSignalHTTPSO_DeletedObjectBecomesUnroutable(t *testing.T) {
 
 	cl := newTestClient(httpso)
 	counter := queue.NewMemory()
-	tbl := NewTable(cl, counter)
+	tbl := NewTable(cl, counter, TableOptions{})
 
 	cancel := startTableAndWaitForSync(t, tbl)
 	defer cancel()
@@ -2,7 +2,10 @@ package routing
 
 import (
 	"cmp"
+	"context"
 	"net/http"
+	"net/url"
+	"regexp"
 	"slices"
 
 	iradix "github.com/hashicorp/go-immutable-radix/v2"
@@ -13,6 +16,10 @@ import (
 type routeEntry struct {
 	ir      *httpv1beta1.InterceptorRoute
 	headers []httpv1beta1.HeaderMatch
+	methods []httpv1beta1.HTTPMethod
+	query   []httpv1beta1.QueryParamMatch
+	// path is only set for regular expression path matches.
+	path *regexp.Regexp
 }
 
 type (
@@ -21,82 +28,196 @@ type (
 
 // TableMemory is an immutable routing table.
 type TableMemory struct {
+	// store holds path prefix matches, looked up by longest prefix.
 	store *store
+	// exact holds exact path matches, keyed like store.
+	exact *store
+	// regex holds regular expression path matches keyed by hostname,
+	// in evaluation order.
+	regex *store
+	// regexes caches the compiled expressions of the routes, shared by the
+	// tables built from the same empty one.
+	regexes *regexCache
 }
 
 // NewTableMemory creates an empty TableMemory.
 func NewTableMemory() *TableMemory {
 	return &TableMemory{
-		store: iradix.New[[]routeEntry](),
+		store:   iradix.New[[]routeEntry](),
+		exact:   iradix.New[[]routeEntry](),
+		regex:   iradix.New[[]routeEntry](),
+		regexes: newRegexCache(),
 	}
 }
 
+type tableMemoryContextKey struct{}
+
+// ContextWithTableMemory returns a copy of ctx carrying tm, whose cache of
+// compiled expressions is then used to match requests with ctx.
+func ContextWithTableMemory(ctx context.Context, tm *TableMemory) context.Context {
+	return context.WithValue(ctx, tableMemoryContextKey{}, tm)
+}
+
+// TableMemoryFromContext returns the TableMemory of ctx, nil if it has none.
+func TableMemoryFromContext(ctx context.Context) *TableMemory {
+	tm, _ := ctx.Value(tableMemoryContextKey{}).(*TableMemory)
+	return tm
+}
+
+// regexCache returns the expression cache of tm, nil for a nil tm.
+func (tm *TableMemory) regexCache() *regexCache {
+	if tm == nil {
+		return nil
+	}
+	return tm.regexes
+}
+
 // Remember adds an InterceptorRoute and returns a new TableMemory.
 // Duplicates are not detected and existing entries are not updated.
+// Paths with invalid regular expressions are skipped.
 func (tm *TableMemory) Remember(ir *httpv1beta1.InterceptorRoute) *TableMemory {
 	if ir == nil {
 		return tm
 	}
 	ir = ir.DeepCopy()
 
-	store := tm.store
+	store, exact, regex := tm.store, tm.exact, tm.regex
 	for _, rule := range ir.Spec.Rules {
-		keys := newKeysFromRoutingRule(rule)
+		for _, key := range newKeysFromRoutingRule(rule) {
+			store = remember(store, key, routeEntry{ir: ir, headers: rule.Headers, methods: rule.Methods, query: rule.QueryParams})
+		}
+
+		for _, key := range newExactKeysFromRoutingRule(rule) {
+			exact = remember(exact, key, routeEntry{ir: ir, headers: rule.Headers, methods: rule.Methods, query: rule.QueryParams})
+		}
 
-		for _, key := range keys {
-			store = remember(store, key, ir, rule.Headers)
+		for _, path := range rule.Paths {
+			if pathMatchType(path) != httpv1beta1.PathMatchRegularExpression {
+				continue
+			}
+			re, err := tm.regexes.compile(path.Value)
+			if err != nil {
+				continue
+			}
+			for _, hostname := range routingHostnames(rule.Hosts) {
+				regex = rememberRegex(regex, hostname, routeEntry{ir: ir, headers: rule.Headers, methods: rule.Methods, query: rule.QueryParams, path: re})
+			}
 		}
 	}
 
 	return &TableMemory{
-		store: store,
+		store:   store,
+		exact:   exact,
+		regex:   regex,
+		regexes: tm.regexes,
 	}
 }
 
-// Route finds an InterceptorRoute matching hostname, path and headers.
-// Tries exact match first the hostname, then filter by headers and find the most specific match
-// then it moves to wildcards with similar header matching rule, finally catch-all.
-func (tm *TableMemory) Route(hostname, path string, headers http.Header) *httpv1beta1.InterceptorRoute {
-	// Try exact match
-	key := NewKey(hostname, path)
-	_, routeEntries, _ := tm.store.Root().LongestPrefix(key)
+// Route finds an InterceptorRoute matching hostname, path, method, query and headers.
+// Tries exact match first the hostname, then moves to wildcards from most to
+// least specific, finally catch-all. For each hostname, exact paths are tried
+// first, then the longest path prefix, then regular expressions in order.
+// Within each, methods, query parameters and headers are filtered to find the
+// most specific match.
+func (tm *TableMemory) Route(hostname, path, method string, query url.Values, headers http.Header) *httpv1beta1.InterceptorRoute {
+	return tm.route(hostname, path, method, query, headers, nil)
+}
 
-	// Filter header matches, the entries are already sorted by specificity so the first match is the most specific one
-	for _, e := range routeEntries {
-		if MatchHeaders(e.headers, headers) {
-			return e.ir
-		}
+// route implements Route, recording every considered entry in trace if non-nil.
+func (tm *TableMemory) route(hostname, path, method string, query url.Values, headers http.Header, trace *[]MatchStep) *httpv1beta1.InterceptorRoute {
+	// Try exact match
+	if ir := tm.routeHostname(hostname, path, method, query, headers, trace); ir != nil {
+		return ir
 	}
 
 	// Try wildcard matches (most specific to least specific)
 	for _, wildcardName := range wildcardHostnames(hostname) {
-		wildcardKey := NewKey(wildcardName, path)
-		if ir := tm.tryWithKey(wildcardKey, headers); ir != nil {
+		if ir := tm.routeHostname(wildcardName, path, method, query, headers, trace); ir != nil {
 			return ir
 		}
 	}
 
 	// Try catch-all
-	catchAllKey := NewKey(catchAllHostKey, path)
-	return tm.tryWithKey(catchAllKey, headers)
+	return tm.routeHostname(catchAllHostKey, path, method, query, headers, trace)
 }
 
-// tryWithKey attempts to find an InterceptorRoute for the given key and headers.
-func (tm *TableMemory) tryWithKey(key Key, headers http.Header) *httpv1beta1.InterceptorRoute {
-	_, routeEntries, _ := tm.store.Root().LongestPrefix(key)
+// routeHostname attempts to find an InterceptorRoute for the given stored
+// hostname (exact, wildcard or catch-all), path, method, query and headers.
+func (tm *TableMemory) routeHostname(hostname, path, method string, query url.Values, headers http.Header, trace *[]MatchStep) *httpv1beta1.InterceptorRoute {
+	key := NewKey(hostname, path)
+
+	if tm.exact.Len() > 0 {
+		routeEntries, _ := tm.exact.Root().Get(key)
+		if ir := tm.matchEntries(routeEntries, path, method, query, headers, traceStep(trace, EntryKindExact, key)); ir != nil {
+			return ir
+		}
+	}
+
+	prefixKey, routeEntries, _ := tm.store.Root().LongestPrefix(key)
+	if ir := tm.matchEntries(routeEntries, path, method, query, headers, traceStep(trace, EntryKindPrefix, prefixKey)); ir != nil {
+		return ir
+	}
+
+	if tm.regex.Len() > 0 {
+		hostKey := []byte(hostname)
+		routeEntries, _ := tm.regex.Root().Get(hostKey)
+		return tm.matchEntries(routeEntries, path, method, query, headers, traceStep(trace, EntryKindRegex, hostKey))
+	}
+	return nil
+}
+
+// matchEntries returns the first entry matching the path, method, query and
+// headers. The entries are already sorted by specificity so the first match
+// is the most specific one.
+func (tm *TableMemory) matchEntries(routeEntries []routeEntry, path, method string, query url.Values, headers http.Header, trace func(routeEntry, string)) *httpv1beta1.InterceptorRoute {
 	for _, e := range routeEntries {
-		if MatchHeaders(e.headers, headers) {
+		reason := tm.mismatchReason(e, path, method, query, headers)
+		if trace != nil {
+			trace(e, reason)
+		}
+		if reason == "" {
 			return e.ir
 		}
 	}
 	return nil
 }
 
+// mismatchReason returns why e does not match, or "" if it matches.
+func (tm *TableMemory) mismatchReason(e routeEntry, path, method string, query url.Values, headers http.Header) string {
+	switch {
+	case e.path != nil && !e.path.MatchString(path):
+		return "path does not match regular expression"
+	case !MatchMethod(e.methods, method):
+		return "method does not match"
+	case !matchQueryParams(e.query, query, tm.regexes):
+		return "query parameters do not match"
+	case !matchHeaders(e.headers, headers, tm.regexes):
+		return "headers do not match"
+	default:
+		return ""
+	}
+}
+
+// rememberRegex appends a regular expression entry for the hostname. Entries
+// are ordered by route age and kept in declaration order within a route.
+func rememberRegex(store *store, hostname string, re routeEntry) *store {
+	key := []byte(hostname)
+	existing, _ := store.Root().Get(key)
+
+	existing = slices.Clone(existing)
+	existing = append(existing, re)
+	slices.SortStableFunc(existing, func(a, b routeEntry) int {
+		return compareRouteAge(a.ir, b.ir)
+	})
+
+	newStore, _, _ := store.Insert(key, existing)
+	return newStore
+}
+
 // remember adds a route entry to the store for the given key, sorting by specificity.
 // Duplicates are not detected and existing entries are not updated.
-func remember(store *store, key Key, ir *httpv1beta1.InterceptorRoute, headers []httpv1beta1.HeaderMatch) *store {
+func remember(store *store, key Key, re routeEntry) *store {
 	existing, found := store.Root().Get(key)
-	re := routeEntry{ir: ir, headers: headers}
 	if !found {
 		// No existing entry, create a new one
 		newStore, _, _ := store.Insert(key, []routeEntry{re})
@@ -112,35 +233,92 @@ func remember(store *store, key Key, ir *httpv1beta1.InterceptorRoute, headers [
 			return diff
 		}
 
-		// then by specificity of header values, more specific first
-		if diff := cmp.Compare(headerSpecificity(b.headers), headerSpecificity(a.headers)); diff != 0 {
+		// then by specificity of header match types, more specific first
+		if diff := compareHeaderSpecificity(a.headers, b.headers); diff != 0 {
 			return diff
 		}
 
-		// then by creation timestamp, older first
-		if diff := a.ir.CreationTimestamp.Compare(b.ir.CreationTimestamp.Time); diff != 0 {
+		// then by number of query parameters, more first
+		if diff := cmp.Compare(len(b.query), len(a.query)); diff != 0 {
 			return diff
 		}
 
-		// tiebreaker by namespace/name, lexicographically (descending)
-		if diff := cmp.Compare(b.ir.Namespace, a.ir.Namespace); diff != 0 {
+		// then method restricted entries before entries matching any method
+		if diff := cmp.Compare(methodSpecificity(b.methods), methodSpecificity(a.methods)); diff != 0 {
 			return diff
 		}
 
-		return cmp.Compare(b.ir.Name, a.ir.Name)
+		return compareRouteAge(a.ir, b.ir)
 	})
 
 	newRadix, _, _ := store.Insert(key, existing)
 	return newRadix
 }
 
-// headerSpecificity calculates how specific the headers are, putting more weight on headers with values.
-func headerSpecificity(headers []httpv1beta1.HeaderMatch) int {
-	specificity := 0
+// compareRouteAge orders routes by creation timestamp, older first, with
+// namespace/name as a tiebreaker.
+func compareRouteAge(a, b *httpv1beta1.InterceptorRoute) int {
+	// by creation timestamp, older first
+	if diff := a.CreationTimestamp.Compare(b.CreationTimestamp.Time); diff != 0 {
+		return diff
+	}
+
+	// tiebreaker by namespace/name, lexicographically (descending)
+	if diff := cmp.Compare(b.Namespace, a.Namespace); diff != 0 {
+		return diff
+	}
+
+	return cmp.Compare(b.Name, a.Name)
+}
+
+// methodSpecificity is 1 for entries restricted to specific methods, 0 otherwise.
+func methodSpecificity(methods []httpv1beta1.HTTPMethod) int {
+	if len(methods) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// headerSpecificityOrder lists header match types from most to least specific.
+var headerSpecificityOrder = []httpv1beta1.HeaderMatchType{
+	httpv1beta1.HeaderMatchExact,
+	httpv1beta1.HeaderMatchPrefix,
+	httpv1beta1.HeaderMatchRegularExpression,
+	httpv1beta1.HeaderMatchPresent,
+	httpv1beta1.HeaderMatchNotPresent,
+}
+
+// compareHeaderSpecificity orders header matchers with the same length by how
+// specific they are, more specific first: the one with more exact matches
+// wins, then more prefix matches, and so on down headerSpecificityOrder.
+// Remaining ties are broken by total prefix length, longer first.
+func compareHeaderSpecificity(a, b []httpv1beta1.HeaderMatch) int {
+	for _, t := range headerSpecificityOrder {
+		if diff := cmp.Compare(countHeaderMatchType(b, t), countHeaderMatchType(a, t)); diff != 0 {
+			return diff
+		}
+	}
+	return cmp.Compare(headerPrefixLength(b), headerPrefixLength(a))
+}
+
+// countHeaderMatchType counts the matchers of the given effective type.
+func countHeaderMatchType(headers []httpv1beta1.HeaderMatch, t httpv1beta1.HeaderMatchType) int {
+	count := 0
+	for _, header := range headers {
+		if headerMatchType(header) == t {
+			count++
+		}
+	}
+	return count
+}
+
+// headerPrefixLength sums the lengths of all prefix matcher values.
+func headerPrefixLength(headers []httpv1beta1.HeaderMatch) int {
+	length := 0
 	for _, header := range headers {
-		if header.Value != nil {
-			specificity++
+		if headerMatchType(header) == httpv1beta1.HeaderMatchPrefix {
+			length += len(*header.Value)
 		}
 	}
-	return specificity
+	return length
 }
@@ -1,6 +1,7 @@
 package routing
 
 import (
+	"net/http"
 	"strconv"
 	"testing"
 
@@ -20,7 +21,7 @@ func BenchmarkExactMatch(b *testing.B) {
 		tm := NewTableMemory().Remember(ir)
 
 		for b.Loop() {
-			tm.Route("foo.example.com", "/api/v1", nil)
+			tm.Route("foo.example.com", "/api/v1", http.MethodGet, nil, nil)
 		}
 	})
 
@@ -28,7 +29,7 @@ func BenchmarkExactMatch(b *testing.B) {
 		tm := setup100ExactRoutes()
 
 		for b.Loop() {
-			tm.Route("host50.example.com", "/api/v1", nil)
+			tm.Route("host50.example.com", "/api/v1", http.MethodGet, nil, nil)
 		}
 	})
 }
@@ -44,7 +45,7 @@ func BenchmarkWildcard(b *testing.B) {
 		tm := NewTableMemory().Remember(ir)
 
 		for b.Loop() {
-			tm.Route("foo.example.com", "/api/v1", nil)
+			tm.Route("foo.example.com", "/api/v1", http.MethodGet, nil, nil)
 		}
 	})
 
@@ -58,7 +59,7 @@ func BenchmarkWildcard(b *testing.B) {
 		tm := NewTableMemory().Remember(ir)
 
 		for b.Loop() {
-			tm.Route("a.b.c.example.com", "/api/v1", nil)
+			tm.Route("a.b.c.example.com", "/api/v1", http.MethodGet, nil, nil)
 		}
 	})
 
@@ -72,7 +73,7 @@ func BenchmarkWildcard(b *testing.B) {
 		tm := NewTableMemory().Remember(ir)
 
 		for b.Loop() {
-			tm.Route("a.b.c.d.e.example.com", "/api/v1", nil)
+			tm.Route("a.b.c.d.e.example.com", "/api/v1", http.MethodGet, nil, nil)
 		}
 	})
 
@@ -87,7 +88,7 @@ func BenchmarkWildcard(b *testing.B) {
 		tm = tm.Remember(wildcardIR)
 
 		for b.Loop() {
-			tm.Route("foo.other.com", "/api/v1", nil)
+			tm.Route("foo.other.com", "/api/v1", http.MethodGet, nil, nil)
 		}
 	})
 }
@@ -103,7 +104,7 @@ func BenchmarkCatchAll(b *testing.B) {
 		tm := NewTableMemory().Remember(ir)
 
 		for b.Loop() {
-			tm.Route("unknown.domain.com", "/api/v1", nil)
+			tm.Route("unknown.domain.com", "/api/v1", http.MethodGet, nil, nil)
 		}
 	})
 
@@ -118,7 +119,7 @@ func BenchmarkCatchAll(b *testing.B) {
 		tm = tm.Remember(catchAllIR)
 
 		for b.Loop() {
-			tm.Route("unknown.domain.com", "/api/v1", nil)
+			tm.Route("unknown.domain.com", "/api/v1", http.MethodGet, nil, nil)
 		}
 	})
 }
@@ -134,7 +135,7 @@ func BenchmarkNoMatch(b *testing.B) {
 		tm := NewTableMemory().Remember(ir)
 
 		for b.Loop() {
-			tm.Route("other.domain.com", "/api/v1", nil)
+			tm.Route("other.domain.com", "/api/v1", http.MethodGet, nil, nil)
 		}
 	})
 
@@ -142,7 +143,7 @@ func BenchmarkNoMatch(b *testing.B) {
 		tm := setup100ExactRoutes()
 
 		for b.Loop() {
-			tm.Route("unknown.domain.com", "/api/v1", nil)
+			tm.Route("unknown.domain.com", "/api/v1", http.MethodGet, nil, nil)
 		}
 	})
 }
@@ -1,6 +1,8 @@
 package routing
 
 import (
+	"net/http"
+	"net/url"
 	"testing"
 	"time"
 
@@ -37,7 +39,7 @@ func TestRemember(t *testing.T) {
 
 		tm := NewTableMemory().Remember(ir1).Remember(ir2)
 
-		route1 := tm.Route("first.com", "", nil)
+		route1 := tm.Route("first.com", "", http.MethodGet, nil, nil)
 		if route1 == nil || route1.Name != "first" {
 			name := "<nil>"
 			if route1 != nil {
@@ -45,7 +47,7 @@ func TestRemember(t *testing.T) {
 			}
 			t.Errorf("name=%q, want=%q", name, "first")
 		}
-		route2 := tm.Route("second.com", "", nil)
+		route2 := tm.Route("second.com", "", http.MethodGet, nil, nil)
 		if route2 == nil || route2.Name != "second" {
 			name := "<nil>"
 			if route2 != nil {
@@ -69,10 +71,10 @@ func TestRemember(t *testing.T) {
 		ir.Spec.Rules[0].Hosts[0] = "modified.com"
 
 		// Should still route to original host, not modified one
-		if tm.Route("example.com", "", nil) == nil {
+		if tm.Route("example.com", "", http.MethodGet, nil, nil) == nil {
 			t.Error("expected route for original host")
 		}
-		if tm.Route("modified.com", "", nil) != nil {
+		if tm.Route("modified.com", "", http.MethodGet, nil, nil) != nil {
 			t.Error("expected no route for modified host")
 		}
 	})
@@ -103,7 +105,7 @@ func TestRememberOldestWins(t *testing.T) {
 	t.Run("older object wins when added first", func(t *testing.T) {
 		tm := NewTableMemory().Remember(older).Remember(newer)
 
-		route := tm.Route("example.com", "", nil)
+		route := tm.Route("example.com", "", http.MethodGet, nil, nil)
 		if route == nil {
 			t.Fatal("no route matched")
 		}
@@ -115,7 +117,7 @@ func TestRememberOldestWins(t *testing.T) {
 	t.Run("older object wins when added second", func(t *testing.T) {
 		tm := NewTableMemory().Remember(newer).Remember(older)
 
-		route := tm.Route("example.com", "", nil)
+		route := tm.Route("example.com", "", http.MethodGet, nil, nil)
 		if route == nil {
 			t.Fatal("no route matched")
 		}
@@ -152,7 +154,7 @@ func TestRememberOldestWins(t *testing.T) {
 
 		tm := NewTableMemory().Remember(newerWithHeaders).Remember(olderWithHeaders)
 
-		route := tm.Route("example.com", "", map[string][]string{"X-Custom-Header": {"value"}})
+		route := tm.Route("example.com", "", http.MethodGet, nil, map[string][]string{"X-Custom-Header": {"value"}})
 		if route == nil {
 			t.Fatal("no route matched")
 		}
@@ -305,7 +307,274 @@ func TestRouteWithHeaders(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			route := tm.Route("example.com", tt.path, tt.headers)
+			route := tm.Route("example.com", tt.path, http.MethodGet, nil, tt.headers)
+
+			switch {
+			case route == nil && tt.want == "":
+				// ok
+			case route == nil && tt.want != "":
+				t.Errorf("route=nil, want %q", tt.want)
+			case route != nil && tt.want == "":
+				t.Errorf("route=%q, want nil", route.Name)
+			case route != nil && route.Name != tt.want:
+				t.Errorf("route=%q, want %q", route.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestRouteWithHeaderMatchTypes(t *testing.T) {
+	newIR := func(name string, headers ...httpv1beta1.HeaderMatch) *httpv1beta1.InterceptorRoute {
+		return &httpv1beta1.InterceptorRoute{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: httpv1beta1.InterceptorRouteSpec{
+				Rules: []httpv1beta1.RoutingRule{{
+					Hosts:   []string{"example.com"},
+					Headers: headers,
+				}},
+			},
+		}
+	}
+
+	defaultIR := newIR("default")
+	exactIR := newIR("exact", httpv1beta1.HeaderMatch{Name: "X-Tenant", Value: ptr.To("eu-west")})
+	prefixIR := newIR("prefix", httpv1beta1.HeaderMatch{Name: "X-Tenant", Type: httpv1beta1.HeaderMatchPrefix, Value: ptr.To("eu-")})
+	longerPrefixIR := newIR("longer-prefix", httpv1beta1.HeaderMatch{Name: "X-Tenant", Type: httpv1beta1.HeaderMatchPrefix, Value: ptr.To("eu-north")})
+	regexIR := newIR("regex", httpv1beta1.HeaderMatch{Name: "X-Tenant", Type: httpv1beta1.HeaderMatchRegularExpression, Value: ptr.To("[a-z]+-[a-z]+")})
+	presentIR := newIR("present", httpv1beta1.HeaderMatch{Name: "X-Tenant"})
+	notPresentIR := newIR("no-canary", httpv1beta1.HeaderMatch{Name: "X-Canary", Type: httpv1beta1.HeaderMatchNotPresent})
+
+	tm := NewTableMemory()
+	// Insert least specific first to make sure sorting, not insertion order, decides.
+	for _, ir := range []*httpv1beta1.InterceptorRoute{defaultIR, notPresentIR, presentIR, regexIR, prefixIR, longerPrefixIR, exactIR} {
+		tm = tm.Remember(ir)
+	}
+
+	tests := map[string]struct {
+		headers http.Header
+		want    string
+	}{
+		"exact wins over prefix": {
+			headers: http.Header{"X-Tenant": {"eu-west"}, "X-Canary": {"1"}},
+			want:    "exact",
+		},
+		"longer prefix wins": {
+			headers: http.Header{"X-Tenant": {"eu-north-1"}, "X-Canary": {"1"}},
+			want:    "longer-prefix",
+		},
+		"prefix wins over regex": {
+			headers: http.Header{"X-Tenant": {"eu-south"}, "X-Canary": {"1"}},
+			want:    "prefix",
+		},
+		"regex wins over present": {
+			headers: http.Header{"X-Tenant": {"us-east"}, "X-Canary": {"1"}},
+			want:    "regex",
+		},
+		"present wins over not present": {
+			headers: http.Header{"X-Tenant": {"US"}},
+			want:    "present",
+		},
+		"not present wins over no headers": {
+			headers: http.Header{},
+			want:    "no-canary",
+		},
+		"falls back to route without headers": {
+			headers: http.Header{"X-Canary": {"1"}},
+			want:    "default",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			route := tm.Route("example.com", "/", http.MethodGet, nil, tt.headers)
+			if route == nil {
+				t.Fatalf("route=nil, want %q", tt.want)
+			}
+			if route.Name != tt.want {
+				t.Errorf("route=%q, want %q", route.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestRouteWithMethods(t *testing.T) {
+	now := time.Now()
+
+	writeIR := &httpv1beta1.InterceptorRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "write", CreationTimestamp: metav1.NewTime(now)},
+		Spec: httpv1beta1.InterceptorRouteSpec{
+			Rules: []httpv1beta1.RoutingRule{{
+				Hosts:   []string{"example.com"},
+				Paths:   []httpv1beta1.PathMatch{{Value: "/ingest"}},
+				Methods: []httpv1beta1.HTTPMethod{http.MethodPost, http.MethodPut},
+			}},
+		},
+	}
+	readIR := &httpv1beta1.InterceptorRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "read", CreationTimestamp: metav1.NewTime(now.Add(-time.Hour))},
+		Spec: httpv1beta1.InterceptorRouteSpec{
+			Rules: []httpv1beta1.RoutingRule{{
+				Hosts: []string{"example.com"},
+				Paths: []httpv1beta1.PathMatch{{Value: "/ingest"}},
+			}},
+		},
+	}
+	headerIR := &httpv1beta1.InterceptorRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "header", CreationTimestamp: metav1.NewTime(now)},
+		Spec: httpv1beta1.InterceptorRouteSpec{
+			Rules: []httpv1beta1.RoutingRule{{
+				Hosts:   []string{"example.com"},
+				Paths:   []httpv1beta1.PathMatch{{Value: "/ingest"}},
+				Headers: []httpv1beta1.HeaderMatch{{Name: "X-Canary"}},
+			}},
+		},
+	}
+	wildcardIR := &httpv1beta1.InterceptorRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "wildcard"},
+		Spec: httpv1beta1.InterceptorRouteSpec{
+			Rules: []httpv1beta1.RoutingRule{{
+				Hosts: []string{"*.com"},
+			}},
+		},
+	}
+
+	tests := map[string]struct {
+		stored  []*httpv1beta1.InterceptorRoute
+		method  string
+		headers http.Header
+		want    string // expected Name, or "" for nil
+	}{
+		"POST goes to method restricted route": {
+			stored: []*httpv1beta1.InterceptorRoute{readIR, writeIR},
+			method: http.MethodPost,
+			want:   "write",
+		},
+		"GET goes to unrestricted route": {
+			stored: []*httpv1beta1.InterceptorRoute{readIR, writeIR},
+			method: http.MethodGet,
+			want:   "read",
+		},
+		"method restricted route wins regardless of age": {
+			stored: []*httpv1beta1.InterceptorRoute{writeIR, readIR},
+			method: http.MethodPut,
+			want:   "write",
+		},
+		"unmatched method falls through to wildcard host": {
+			stored: []*httpv1beta1.InterceptorRoute{writeIR, wildcardIR},
+			method: http.MethodGet,
+			want:   "wildcard",
+		},
+		"unmatched method without fallback": {
+			stored: []*httpv1beta1.InterceptorRoute{writeIR},
+			method: http.MethodDelete,
+			want:   "",
+		},
+		"headers take priority over methods": {
+			stored:  []*httpv1beta1.InterceptorRoute{writeIR, headerIR},
+			method:  http.MethodPost,
+			headers: http.Header{"X-Canary": {"1"}},
+			want:    "header",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tm := NewTableMemory()
+			for _, ir := range tt.stored {
+				tm = tm.Remember(ir)
+			}
+
+			route := tm.Route("example.com", "/ingest", tt.method, nil, tt.headers)
+
+			switch {
+			case route == nil && tt.want == "":
+				// ok
+			case route == nil && tt.want != "":
+				t.Errorf("route=nil, want %q", tt.want)
+			case route != nil && tt.want == "":
+				t.Errorf("route=%q, want nil", route.Name)
+			case route != nil && route.Name != tt.want:
+				t.Errorf("route=%q, want %q", route.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestRouteWithQueryParams(t *testing.T) {
+	v2IR := &httpv1beta1.InterceptorRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "v2"},
+		Spec: httpv1beta1.InterceptorRouteSpec{
+			Rules: []httpv1beta1.RoutingRule{{
+				Hosts:       []string{"example.com"},
+				QueryParams: []httpv1beta1.QueryParamMatch{{Name: "api-version", Value: ptr.To("2")}},
+			}},
+		},
+	}
+	defaultIR := &httpv1beta1.InterceptorRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec: httpv1beta1.InterceptorRouteSpec{
+			Rules: []httpv1beta1.RoutingRule{{
+				Hosts: []string{"example.com"},
+			}},
+		},
+	}
+	regexIR := &httpv1beta1.InterceptorRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "regex"},
+		Spec: httpv1beta1.InterceptorRouteSpec{
+			Rules: []httpv1beta1.RoutingRule{{
+				Hosts: []string{"regex.example.com"},
+				Paths: []httpv1beta1.PathMatch{{Type: httpv1beta1.PathMatchRegularExpression, Value: "/items/.*"}},
+				QueryParams: []httpv1beta1.QueryParamMatch{{
+					Name:  "api-version",
+					Type:  httpv1beta1.QueryParamMatchRegularExpression,
+					Value: ptr.To("3.*"),
+				}},
+			}},
+		},
+	}
+
+	tm := NewTableMemory().Remember(defaultIR).Remember(v2IR).Remember(regexIR)
+
+	tests := map[string]struct {
+		host  string
+		path  string
+		query url.Values
+		want  string // expected Name, or "" for nil
+	}{
+		"matching query param routes to query rule": {
+			host:  "example.com",
+			path:  "/",
+			query: url.Values{"api-version": {"2"}},
+			want:  "v2",
+		},
+		"non-matching query param falls back": {
+			host:  "example.com",
+			path:  "/",
+			query: url.Values{"api-version": {"1"}},
+			want:  "default",
+		},
+		"no query falls back": {
+			host: "example.com",
+			path: "/",
+			want: "default",
+		},
+		"regex path with regex query": {
+			host:  "regex.example.com",
+			path:  "/items/1",
+			query: url.Values{"api-version": {"3.1"}},
+			want:  "regex",
+		},
+		"regex path without matching query": {
+			host:  "regex.example.com",
+			path:  "/items/1",
+			query: url.Values{"api-version": {"4"}},
+			want:  "",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			route := tm.Route(tt.host, tt.path, http.MethodGet, tt.query, nil)
 
 			switch {
 			case route == nil && tt.want == "":
@@ -397,7 +666,7 @@ func TestRoute(t *testing.T) {
 				tm = tm.Remember(ir)
 			}
 
-			route := tm.Route(tt.host, tt.path, nil)
+			route := tm.Route(tt.host, tt.path, http.MethodGet, nil, nil)
 
 			switch {
 			case route == nil && tt.want == "":
@@ -413,6 +682,221 @@ func TestRoute(t *testing.T) {
 	}
 }
 
+func TestRoutePathMatchTypes(t *testing.T) {
+	now := time.Now()
+
+	usersIR := &httpv1beta1.InterceptorRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "users"},
+		Spec: httpv1beta1.InterceptorRouteSpec{
+			Rules: []httpv1beta1.RoutingRule{{
+				Hosts: []string{"example.com"},
+				Paths: []httpv1beta1.PathMatch{{Type: httpv1beta1.PathMatchExact, Value: "/v1/users"}},
+			}},
+		},
+	}
+	usersAdminIR := &httpv1beta1.InterceptorRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "users-admin"},
+		Spec: httpv1beta1.InterceptorRouteSpec{
+			Rules: []httpv1beta1.RoutingRule{{
+				Hosts: []string{"example.com"},
+				Paths: []httpv1beta1.PathMatch{{Type: httpv1beta1.PathMatchExact, Value: "/v1/users-admin"}},
+			}},
+		},
+	}
+	prefixIR := &httpv1beta1.InterceptorRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "prefix"},
+		Spec: httpv1beta1.InterceptorRouteSpec{
+			Rules: []httpv1beta1.RoutingRule{{
+				Hosts: []string{"example.com"},
+				Paths: []httpv1beta1.PathMatch{{Type: httpv1beta1.PathMatchPathPrefix, Value: "/v1"}},
+			}},
+		},
+	}
+	regexIR := &httpv1beta1.InterceptorRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "regex", CreationTimestamp: metav1.NewTime(now.Add(-time.Hour))},
+		Spec: httpv1beta1.InterceptorRouteSpec{
+			Rules: []httpv1beta1.RoutingRule{{
+				Hosts: []string{"example.com"},
+				Paths: []httpv1beta1.PathMatch{{Type: httpv1beta1.PathMatchRegularExpression, Value: `/v1/users/[0-9]+`}},
+			}},
+		},
+	}
+	laterRegexIR := &httpv1beta1.InterceptorRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "later-regex", CreationTimestamp: metav1.NewTime(now)},
+		Spec: httpv1beta1.InterceptorRouteSpec{
+			Rules: []httpv1beta1.RoutingRule{{
+				Hosts: []string{"example.com"},
+				Paths: []httpv1beta1.PathMatch{{Type: httpv1beta1.PathMatchRegularExpression, Value: `/v1/.*`}},
+			}},
+		},
+	}
+	wildcardExactIR := &httpv1beta1.InterceptorRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "wildcard-exact"},
+		Spec: httpv1beta1.InterceptorRouteSpec{
+			Rules: []httpv1beta1.RoutingRule{{
+				Hosts: []string{"*.example.com"},
+				Paths: []httpv1beta1.PathMatch{{Type: httpv1beta1.PathMatchExact, Value: "/status"}},
+			}},
+		},
+	}
+	invalidRegexIR := &httpv1beta1.InterceptorRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "invalid-regex"},
+		Spec: httpv1beta1.InterceptorRouteSpec{
+			Rules: []httpv1beta1.RoutingRule{{
+				Hosts: []string{"example.com"},
+				Paths: []httpv1beta1.PathMatch{{Type: httpv1beta1.PathMatchRegularExpression, Value: `/v1/(`}},
+			}},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		stored []*httpv1beta1.InterceptorRoute
+		host   string
+		path   string
+		want   string // expected Name, or "" for nil
+	}{
+		{
+			name:   "exact match",
+			stored: []*httpv1beta1.InterceptorRoute{usersIR, usersAdminIR},
+			host:   "example.com",
+			path:   "/v1/users",
+			want:   "users",
+		},
+		{
+			name:   "exact match distinguishes similar paths",
+			stored: []*httpv1beta1.InterceptorRoute{usersIR, usersAdminIR},
+			host:   "example.com",
+			path:   "/v1/users-admin",
+			want:   "users-admin",
+		},
+		{
+			name:   "exact match ignores trailing slash",
+			stored: []*httpv1beta1.InterceptorRoute{usersIR},
+			host:   "example.com",
+			path:   "/v1/users/",
+			want:   "users",
+		},
+		{
+			name:   "exact does not match sub-paths",
+			stored: []*httpv1beta1.InterceptorRoute{usersIR},
+			host:   "example.com",
+			path:   "/v1/users/123",
+			want:   "",
+		},
+		{
+			name:   "exact wins over prefix",
+			stored: []*httpv1beta1.InterceptorRoute{prefixIR, usersIR},
+			host:   "example.com",
+			path:   "/v1/users",
+			want:   "users",
+		},
+		{
+			name:   "prefix wins over regex",
+			stored: []*httpv1beta1.InterceptorRoute{regexIR, prefixIR},
+			host:   "example.com",
+			path:   "/v1/users/123",
+			want:   "prefix",
+		},
+		{
+			name:   "regex matches full path",
+			stored: []*httpv1beta1.InterceptorRoute{regexIR},
+			host:   "example.com",
+			path:   "/v1/users/123",
+			want:   "regex",
+		},
+		{
+			name:   "regex is anchored",
+			stored: []*httpv1beta1.InterceptorRoute{regexIR},
+			host:   "example.com",
+			path:   "/v1/users/123/orders",
+			want:   "",
+		},
+		{
+			name:   "older regex route is evaluated first",
+			stored: []*httpv1beta1.InterceptorRoute{laterRegexIR, regexIR},
+			host:   "example.com",
+			path:   "/v1/users/123",
+			want:   "regex",
+		},
+		{
+			name:   "later regex route matches when earlier does not",
+			stored: []*httpv1beta1.InterceptorRoute{laterRegexIR, regexIR},
+			host:   "example.com",
+			path:   "/v1/orders",
+			want:   "later-regex",
+		},
+		{
+			name:   "exact host regex wins over wildcard host exact path",
+			stored: []*httpv1beta1.InterceptorRoute{wildcardExactIR, laterRegexIR},
+			host:   "example.com",
+			path:   "/v1/status",
+			want:   "later-regex",
+		},
+		{
+			name:   "exact path on wildcard host",
+			stored: []*httpv1beta1.InterceptorRoute{wildcardExactIR},
+			host:   "foo.example.com",
+			path:   "/status",
+			want:   "wildcard-exact",
+		},
+		{
+			name:   "invalid regex never matches",
+			stored: []*httpv1beta1.InterceptorRoute{invalidRegexIR},
+			host:   "example.com",
+			path:   "/v1/(",
+			want:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tm := NewTableMemory()
+			for _, ir := range tt.stored {
+				tm = tm.Remember(ir)
+			}
+
+			route := tm.Route(tt.host, tt.path, http.MethodGet, nil, nil)
+
+			switch {
+			case route == nil && tt.want == "":
+				// ok
+			case route == nil && tt.want != "":
+				t.Errorf("route=nil, want %q", tt.want)
+			case route != nil && tt.want == "":
+				t.Errorf("route=%q, want nil", route.Name)
+			case route != nil && route.Name != tt.want:
+				t.Errorf("route=%q, want %q", route.Name, tt.want)
+			}
+		})
+	}
+
+	t.Run("regex falls through to next entry on header mismatch", func(t *testing.T) {
+		ir := &httpv1beta1.InterceptorRoute{
+			ObjectMeta: metav1.ObjectMeta{Name: "ordered"},
+			Spec: httpv1beta1.InterceptorRouteSpec{
+				Rules: []httpv1beta1.RoutingRule{
+					{
+						Paths:   []httpv1beta1.PathMatch{{Type: httpv1beta1.PathMatchRegularExpression, Value: `/a/.*`}},
+						Headers: []httpv1beta1.HeaderMatch{{Name: "X-First"}},
+					},
+					{
+						Paths: []httpv1beta1.PathMatch{{Type: httpv1beta1.PathMatchRegularExpression, Value: `/a/b`}},
+					},
+				},
+			},
+		}
+		tm := NewTableMemory().Remember(ir)
+
+		if route := tm.Route("example.com", "/a/b", http.MethodGet, nil, http.Header{"X-First": {"1"}}); route == nil {
+			t.Error("expected first regex to match with header")
+		}
+		if route := tm.Route("example.com", "/a/b", http.MethodGet, nil, nil); route == nil {
+			t.Error("expected second declared regex to match without header")
+		}
+	})
+}
+
 func TestRouteWildcardMultiLevel(t *testing.T) {
 	wildcardIR := &httpv1beta1.InterceptorRoute{
 		ObjectMeta: metav1.ObjectMeta{Name: "wildcard-example"},
@@ -604,7 +1088,7 @@ func TestRouteMultipleRules(t *testing.T) {
 
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
-			route := tm.Route(tt.host, tt.path, nil)
+			route := tm.Route(tt.host, tt.path, http.MethodGet, nil, nil)
 			gotMatch := route != nil
 			if gotMatch != tt.wantMatch {
 				t.Errorf("got %v, want %v", gotMatch, tt.wantMatch)
@@ -668,7 +1152,7 @@ func TestRouteMultipleRulesWithHeaders(t *testing.T) {
 
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
-			route := tm.Route("example.com", tt.path, tt.headers)
+			route := tm.Route("example.com", tt.path, http.MethodGet, nil, tt.headers)
 			gotMatch := route != nil
 			if gotMatch != tt.wantMatch {
 				t.Errorf("got %v, want %v", gotMatch, tt.wantMatch)
@@ -714,7 +1198,7 @@ func TestRouteMultipleRulesWithWildcards(t *testing.T) {
 
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
-			route := tm.Route(tt.host, "", nil)
+			route := tm.Route(tt.host, "", http.MethodGet, nil, nil)
 			gotMatch := route != nil
 			if gotMatch != tt.wantMatch {
 				t.Errorf("got %v, want %v", gotMatch, tt.wantMatch)
@@ -732,7 +1216,7 @@ func runRouteTest(t *testing.T, stored []*httpv1beta1.InterceptorRoute, reqHost,
 		tm = tm.Remember(ir)
 	}
 
-	route := tm.Route(reqHost, reqPath, nil)
+	route := tm.Route(reqHost, reqPath, http.MethodGet, nil, nil)
 
 	switch {
 	case route == nil && want == nil:
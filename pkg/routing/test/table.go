@@ -32,6 +32,14 @@ func (t Table) Route(req *http.Request) *httpv1beta1.InterceptorRoute {
 	return t.Memory[req.Host]
 }
 
+func (t Table) Snapshot() *routing.TableMemory {
+	tm := routing.NewTableMemory()
+	for _, ir := range t.Memory {
+		tm = tm.Remember(ir)
+	}
+	return tm
+}
+
 func (t Table) HasSynced() bool {
 	return true
 }
@@ -18,6 +18,11 @@ const (
 	ckIR
 	ckUpstreamServerName
 	ckUpstreamPortName
+	ckTargetRef
+	ckMirrorURL
+	ckMirrorPortName
+	ckUpstreamAttempt
+	ckFallbackURLs
 )
 
 func ContextWithLogger(ctx context.Context, logger logr.Logger) context.Context {
@@ -56,6 +61,37 @@ func FallbackURLFromContext(ctx context.Context) *url.URL {
 	return cv
 }
 
+// ContextWithFallbackURLs stores the URLs of the chained cold-start fallbacks
+// of the route, in order, with nil for the static response ending the chain.
+func ContextWithFallbackURLs(ctx context.Context, urls []*url.URL) context.Context {
+	return context.WithValue(ctx, ckFallbackURLs, urls)
+}
+
+func FallbackURLsFromContext(ctx context.Context) []*url.URL {
+	cv, _ := ctx.Value(ckFallbackURLs).([]*url.URL)
+	return cv
+}
+
+func ContextWithMirrorURL(ctx context.Context, url *url.URL) context.Context {
+	return context.WithValue(ctx, ckMirrorURL, url)
+}
+
+func MirrorURLFromContext(ctx context.Context) *url.URL {
+	cv, _ := ctx.Value(ckMirrorURL).(*url.URL)
+	return cv
+}
+
+// ContextWithMirrorPortName stores the EndpointSlice port name of the mirror
+// Service, "" for unnamed ports.
+func ContextWithMirrorPortName(ctx context.Context, portName string) context.Context {
+	return context.WithValue(ctx, ckMirrorPortName, portName)
+}
+
+func MirrorPortNameFromContext(ctx context.Context) string {
+	cv, _ := ctx.Value(ckMirrorPortName).(string)
+	return cv
+}
+
 // ContextWithUpstreamServerName stores the upstream TLS server name (SNI),
 // set before any middleware rewrites the upstream URL.
 func ContextWithUpstreamServerName(ctx context.Context, serverName string) context.Context {
@@ -77,3 +113,41 @@ func UpstreamPortNameFromContext(ctx context.Context) string {
 	cv, _ := ctx.Value(ckUpstreamPortName).(string)
 	return cv
 }
+
+// ContextWithTargetRef stores the backend selected for the request when the
+// InterceptorRoute splits traffic across weighted backends.
+func ContextWithTargetRef(ctx context.Context, target *httpv1beta1.TargetRef) context.Context {
+	return context.WithValue(ctx, ckTargetRef, target)
+}
+
+// TargetRefFromContext returns the backend selected for the request, falling
+// back to the target of the InterceptorRoute in ctx. Returns nil if neither is set.
+func TargetRefFromContext(ctx context.Context) *httpv1beta1.TargetRef {
+	if cv, _ := ctx.Value(ckTargetRef).(*httpv1beta1.TargetRef); cv != nil {
+		return cv
+	}
+	if ir := InterceptorRouteFromContext(ctx); ir != nil {
+		return &ir.Spec.Target
+	}
+	return nil
+}
+
+// UpstreamAttempt records the outcome of forwarding a request to the
+// upstream, so that it can be retried.
+type UpstreamAttempt struct {
+	// Host the request was forwarded to, "" if it was not forwarded.
+	Host string
+	// Err is the error forwarding the request, nil if the upstream responded.
+	Err error
+}
+
+// ContextWithUpstreamAttempt stores the attempt the upstream handler records
+// its outcome in.
+func ContextWithUpstreamAttempt(ctx context.Context, attempt *UpstreamAttempt) context.Context {
+	return context.WithValue(ctx, ckUpstreamAttempt, attempt)
+}
+
+func UpstreamAttemptFromContext(ctx context.Context) *UpstreamAttempt {
+	cv, _ := ctx.Value(ckUpstreamAttempt).(*UpstreamAttempt)
+	return cv
+}
@@ -16,13 +16,16 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/kedacore/keda/v2/pkg/scalers/externalscaler"
 	"google.golang.org/protobuf/types/known/emptypb"
+	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	httpv1alpha1 "github.com/kedacore/http-add-on/operator/apis/http/v1alpha1"
 	httpv1beta1 "github.com/kedacore/http-add-on/operator/apis/http/v1beta1"
 	"github.com/kedacore/http-add-on/pkg/k8s"
+	"github.com/kedacore/http-add-on/pkg/routing"
 )
 
 const (
@@ -103,18 +106,57 @@ func (e *scalerHandler) StreamIsActive(scaledObject *externalscaler.ScaledObject
 	}
 }
 
+// scalingRoute returns the InterceptorRoute referenced by the scaler metadata,
+// either by name or converted from a Gateway API HTTPRoute or an Ingress. ok
+// is false if none is referenced.
+func (e *scalerHandler) scalingRoute(ctx context.Context, namespace string, scalerMetadata map[string]string) (*httpv1beta1.InterceptorRoute, bool, error) {
+	if irName, ok := scalerMetadata[k8s.InterceptorRouteKey]; ok {
+		var ir httpv1beta1.InterceptorRoute
+		if err := e.reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: irName}, &ir); err != nil {
+			return nil, true, fmt.Errorf("getting InterceptorRoute %s: %w", irName, err)
+		}
+		return &ir, true, nil
+	}
+
+	if hrName, ok := scalerMetadata[k8s.HTTPRouteKey]; ok {
+		var hr gatewayv1.HTTPRoute
+		if err := e.reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: hrName}, &hr); err != nil {
+			return nil, true, fmt.Errorf("getting HTTPRoute %s: %w", hrName, err)
+		}
+		ir, err := routing.ScalingRouteFromHTTPRoute(&hr)
+		if err != nil {
+			return nil, true, fmt.Errorf("converting HTTPRoute %s: %w", hrName, err)
+		}
+		return ir, true, nil
+	}
+
+	if ingName, ok := scalerMetadata[k8s.IngressKey]; ok {
+		var ing networkingv1.Ingress
+		if err := e.reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ingName}, &ing); err != nil {
+			return nil, true, fmt.Errorf("getting Ingress %s: %w", ingName, err)
+		}
+		ir, err := routing.ScalingRouteFromIngress(&ing)
+		if err != nil {
+			return nil, true, fmt.Errorf("converting Ingress %s: %w", ingName, err)
+		}
+		return ir, true, nil
+	}
+
+	return nil, false, nil
+}
+
 func (e *scalerHandler) GetMetricSpec(ctx context.Context, sor *externalscaler.ScaledObjectRef) (*externalscaler.GetMetricSpecResponse, error) {
 	lggr := e.lggr.WithName("GetMetricSpec")
 
 	scalerMetadata := sor.GetScalerMetadata()
 
-	if irName, ok := scalerMetadata[k8s.InterceptorRouteKey]; ok {
-		nn := types.NamespacedName{Namespace: sor.Namespace, Name: irName}
-		var ir httpv1beta1.InterceptorRoute
-		if err := e.reader.Get(ctx, nn, &ir); err != nil {
-			lggr.Error(err, "failed to get InterceptorRoute", "namespace", sor.Namespace, "scaledObjectName", sor.Name, "interceptorRouteName", irName)
-			return nil, err
-		}
+	ir, ok, err := e.scalingRoute(ctx, sor.Namespace, scalerMetadata)
+	if err != nil {
+		lggr.Error(err, "failed to get InterceptorRoute", "namespace", sor.Namespace, "scaledObjectName", sor.Name)
+		return nil, err
+	}
+	if ok {
+		irName := ir.Name
 
 		var metricSpecs []*externalscaler.MetricSpec
 		if m := ir.Spec.ScalingMetric.Concurrency; m != nil {
@@ -207,22 +249,31 @@ func (e *scalerHandler) GetMetrics(ctx context.Context, metricRequest *externals
 
 	scalerMetadata := sor.GetScalerMetadata()
 
-	if irName, ok := scalerMetadata[k8s.InterceptorRouteKey]; ok {
-		nn := types.NamespacedName{Namespace: sor.Namespace, Name: irName}
-		var ir httpv1beta1.InterceptorRoute
-		if err := e.reader.Get(ctx, nn, &ir); err != nil {
-			lggr.Error(err, "failed to get InterceptorRoute", "namespace", sor.Namespace, "scaledObjectName", sor.Name, "interceptorRouteName", irName)
+	ir, ok, err := e.scalingRoute(ctx, sor.Namespace, scalerMetadata)
+	if err != nil {
+		lggr.Error(err, "failed to get InterceptorRoute", "namespace", sor.Namespace, "scaledObjectName", sor.Name)
+		return nil, err
+	}
+	if ok {
+		irName := ir.Name
+
+		keys, err := queueKeys(ir, scalerMetadata[k8s.InterceptorRouteBackendKey])
+		if err != nil {
+			lggr.Error(err, "invalid backend for InterceptorRoute", "namespace", sor.Namespace, "scaledObjectName", sor.Name, "interceptorRouteName", irName)
 			return nil, err
 		}
 
-		key := k8s.ResourceKeyFromNamespacedName(nn)
+		var count aggregatedCount
+		for _, key := range keys {
+			if rr := ir.Spec.ScalingMetric.RequestRate; rr != nil {
+				e.pinger.UpdateBucketConfig(key, rr.Window.Duration, rr.Granularity.Duration)
+			}
 
-		if rr := ir.Spec.ScalingMetric.RequestRate; rr != nil {
-			e.pinger.UpdateBucketConfig(key, rr.Window.Duration, rr.Granularity.Duration)
+			c := e.pinger.count(key)
+			count.Concurrency += c.Concurrency
+			count.RequestRate += c.RequestRate
 		}
 
-		count := e.pinger.count(key)
-
 		// KEDA sets metricName per metric; empty means return all (used by IsActive).
 		requestedMetric := metricRequest.GetMetricName()
 
@@ -302,6 +353,35 @@ func (e *scalerHandler) GetMetrics(ctx context.Context, metricRequest *externals
 	return res, nil
 }
 
+// queueKeys returns the queue keys to report metrics for. Routes with
+// weighted backends are counted per backend: the given backend's key is
+// returned, or the keys of all backends when backend is empty. A counted
+// mirror is selected by its service.
+func queueKeys(ir *httpv1beta1.InterceptorRoute, backend string) ([]string, error) {
+	// Mirrored requests are counted apart from the route's own traffic.
+	if m := ir.Spec.Mirror; m != nil && m.CountRequests && backend != "" && backend == m.Service {
+		return []string{k8s.BackendResourceKey(ir.Namespace, ir.Name, m.Service)}, nil
+	}
+
+	if len(ir.Spec.Backends) == 0 {
+		if backend != "" && backend != ir.Spec.Target.Service {
+			return nil, fmt.Errorf("backend %q is not the target of InterceptorRoute %s/%s", backend, ir.Namespace, ir.Name)
+		}
+		return []string{k8s.ResourceKey(ir.Namespace, ir.Name)}, nil
+	}
+
+	keys := make([]string, 0, len(ir.Spec.Backends))
+	for _, b := range ir.Spec.Backends {
+		if backend == "" || backend == b.Service {
+			keys = append(keys, k8s.BackendResourceKey(ir.Namespace, ir.Name, b.Service))
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("backend %q not found in InterceptorRoute %s/%s", backend, ir.Namespace, ir.Name)
+	}
+	return keys, nil
+}
+
 func (e *scalerHandler) interceptorMetrics(metricName string) (*externalscaler.GetMetricsResponse, error) {
 	lggr := e.lggr.WithName("interceptorMetrics")
 
@@ -11,14 +11,18 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/test/bufconn"
+	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	httpv1beta1 "github.com/kedacore/http-add-on/operator/apis/http/v1beta1"
 	"github.com/kedacore/http-add-on/pkg/cache"
 	"github.com/kedacore/http-add-on/pkg/k8s"
+	"github.com/kedacore/http-add-on/pkg/routing"
 )
 
 var (
@@ -236,6 +240,186 @@ func TestGetMetrics(t *testing.T) {
 		}
 	})
 
+	t.Run("weighted backends and mirror", func(t *testing.T) {
+		ir := newTestInterceptorRoute(httpv1beta1.ScalingMetricSpec{
+			Concurrency: &httpv1beta1.ConcurrencyTargetSpec{TargetValue: 100},
+		})
+		ir.Spec.Target = httpv1beta1.TargetRef{}
+		ir.Spec.Backends = []httpv1beta1.WeightedBackend{
+			{TargetRef: httpv1beta1.TargetRef{Service: "stable", Port: 8080}},
+			{TargetRef: httpv1beta1.TargetRef{Service: "canary", Port: 8080}},
+		}
+		ir.Spec.Mirror = &httpv1beta1.MirrorSpec{
+			TargetRef:     httpv1beta1.TargetRef{Service: "shadow", Port: 8080},
+			CountRequests: true,
+		}
+		hdl := newTestScalerHandler(t, ir, aggregatedCount{})
+		hdl.pinger.allCounts[k8s.BackendResourceKey(testIRNamespace, testIRName, "stable")] = aggregatedCount{Concurrency: 7}
+		hdl.pinger.allCounts[k8s.BackendResourceKey(testIRNamespace, testIRName, "canary")] = aggregatedCount{Concurrency: 3}
+		hdl.pinger.allCounts[k8s.BackendResourceKey(testIRNamespace, testIRName, "shadow")] = aggregatedCount{Concurrency: 5}
+
+		tests := map[string]struct {
+			backend string
+			want    float64
+			wantErr bool
+		}{
+			"all backends are summed": {
+				want: 10,
+			},
+			"single backend": {
+				backend: "canary",
+				want:    3,
+			},
+			"mirror": {
+				backend: "shadow",
+				want:    5,
+			},
+			"unknown backend": {
+				backend: "unknown",
+				wantErr: true,
+			},
+		}
+		for name, tc := range tests {
+			t.Run(name, func(t *testing.T) {
+				metadata := map[string]string{k8s.InterceptorRouteKey: testIRName}
+				if tc.backend != "" {
+					metadata[k8s.InterceptorRouteBackendKey] = tc.backend
+				}
+				req := &externalscaler.GetMetricsRequest{
+					ScaledObjectRef: &externalscaler.ScaledObjectRef{
+						Name:           testScaledObjectRef.Name,
+						Namespace:      testIRNamespace,
+						ScalerMetadata: metadata,
+					},
+				}
+				resp, err := hdl.GetMetrics(t.Context(), req)
+				if tc.wantErr {
+					if err == nil {
+						t.Fatal("expected error, got nil")
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				values := resp.GetMetricValues()
+				if got, want := len(values), 1; got != want {
+					t.Fatalf("got %d metric values, want %d", got, want)
+				}
+				if got, want := values[0].MetricValueFloat, tc.want; got != want {
+					t.Errorf("MetricValueFloat = %v, want %v", got, want)
+				}
+			})
+		}
+	})
+
+	t.Run("http route", func(t *testing.T) {
+		hr := &gatewayv1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "test-hr",
+				Namespace:   testIRNamespace,
+				Annotations: map[string]string{routing.RequestRateTargetAnnotation: "10"},
+			},
+			Spec: gatewayv1.HTTPRouteSpec{
+				Rules: []gatewayv1.HTTPRouteRule{
+					{BackendRefs: []gatewayv1.HTTPBackendRef{{BackendRef: gatewayv1.BackendRef{BackendObjectReference: gatewayv1.BackendObjectReference{Name: "api", Port: ptr.To[gatewayv1.PortNumber](8080)}}}}},
+					{BackendRefs: []gatewayv1.HTTPBackendRef{{BackendRef: gatewayv1.BackendRef{BackendObjectReference: gatewayv1.BackendObjectReference{Name: "web", Port: ptr.To[gatewayv1.PortNumber](8080)}}}}},
+				},
+			},
+		}
+		ticker, pinger, err := newFakeQueuePinger(logr.Discard())
+		if err != nil {
+			t.Fatalf("creating fake queue pinger: %v", err)
+		}
+		t.Cleanup(ticker.Stop)
+		pinger.allCounts[k8s.BackendResourceKey(testIRNamespace, hr.Name, "api")] = aggregatedCount{RequestRate: 4}
+		pinger.allCounts[k8s.BackendResourceKey(testIRNamespace, hr.Name, "web")] = aggregatedCount{RequestRate: 2}
+		hdl := newScalerHandler(logr.Discard(), pinger, newFakeClient(hr), time.Second)
+
+		tests := map[string]struct {
+			backend string
+			want    float64
+		}{
+			"all rules are summed": {want: 6},
+			"single backend":       {backend: "web", want: 2},
+		}
+		for name, tc := range tests {
+			t.Run(name, func(t *testing.T) {
+				metadata := map[string]string{k8s.HTTPRouteKey: hr.Name}
+				if tc.backend != "" {
+					metadata[k8s.InterceptorRouteBackendKey] = tc.backend
+				}
+				resp, err := hdl.GetMetrics(t.Context(), &externalscaler.GetMetricsRequest{
+					ScaledObjectRef: &externalscaler.ScaledObjectRef{
+						Name:           testScaledObjectRef.Name,
+						Namespace:      testIRNamespace,
+						ScalerMetadata: metadata,
+					},
+				})
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				values := resp.GetMetricValues()
+				if got, want := len(values), 1; got != want {
+					t.Fatalf("got %d metric values, want %d", got, want)
+				}
+				if got, want := values[0].MetricName, RateMetricName(hr.Name); got != want {
+					t.Errorf("MetricName = %q, want %q", got, want)
+				}
+				if got, want := values[0].MetricValueFloat, tc.want; got != want {
+					t.Errorf("MetricValueFloat = %v, want %v", got, want)
+				}
+			})
+		}
+	})
+
+	t.Run("ingress", func(t *testing.T) {
+		ing := &networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "test-ing",
+				Namespace:   testIRNamespace,
+				Annotations: map[string]string{routing.ConcurrencyTargetAnnotation: "10"},
+			},
+			Spec: networkingv1.IngressSpec{
+				DefaultBackend: &networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+					Name: "web",
+					Port: networkingv1.ServiceBackendPort{Number: 8080},
+				}},
+			},
+		}
+		ticker, pinger, err := newFakeQueuePinger(logr.Discard())
+		if err != nil {
+			t.Fatalf("creating fake queue pinger: %v", err)
+		}
+		t.Cleanup(ticker.Stop)
+		pinger.allCounts[k8s.BackendResourceKey(testIRNamespace, ing.Name, "web")] = aggregatedCount{Concurrency: 3}
+		hdl := newScalerHandler(logr.Discard(), pinger, newFakeClient(ing), time.Second)
+
+		resp, err := hdl.GetMetrics(t.Context(), &externalscaler.GetMetricsRequest{
+			ScaledObjectRef: &externalscaler.ScaledObjectRef{
+				Name:           testScaledObjectRef.Name,
+				Namespace:      testIRNamespace,
+				ScalerMetadata: map[string]string{k8s.IngressKey: ing.Name},
+			},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		values := resp.GetMetricValues()
+		if got, want := len(values), 1; got != want {
+			t.Fatalf("got %d metric values, want %d", got, want)
+		}
+		if got, want := values[0].MetricName, ConcurrencyMetricName(ing.Name); got != want {
+			t.Errorf("MetricName = %q, want %q", got, want)
+		}
+		if got, want := values[0].MetricValueFloat, float64(3); got != want {
+			t.Errorf("MetricValueFloat = %v, want %v", got, want)
+		}
+	})
+
 	t.Run("filters by requested metric name", func(t *testing.T) {
 		ir := newTestInterceptorRoute(httpv1beta1.ScalingMetricSpec{
 			Concurrency: &httpv1beta1.ConcurrencyTargetSpec{TargetValue: 3},